@@ -79,6 +79,29 @@ func (pe *PeriodicalExecutor) Flush() bool {
 	}())
 }
 
+// FlushWith 等待执行中的任务完成后，以 fn 代替容器的 Execute 执行挂起的任务，
+// 以保持任务的执行顺序，返回是否有任务执行。不可在执行任务时调用，否则将死锁。
+func (pe *PeriodicalExecutor) FlushWith(fn func(tasks any)) bool {
+	pe.wgBarrier.Guard(func() {
+		pe.waitGroup.Wait()
+	})
+
+	pe.enterExecution()
+	defer pe.doneExecution()
+
+	tasks := func() any {
+		pe.lock.Lock()
+		defer pe.lock.Unlock()
+		return pe.container.RemoveAll()
+	}()
+	ok := pe.hasTasks(tasks)
+	if ok {
+		fn(tasks)
+	}
+
+	return ok
+}
+
 // Sync 允许调用者使用 pe 执行线程安全的 fn 调用，尤其是底层容器。
 func (pe *PeriodicalExecutor) Sync(fn func()) {
 	pe.lock.Lock()
@@ -139,6 +139,39 @@ func TestPeriodicalExecutor_WaitFast(t *testing.T) {
 	assert.Equal(t, total, cnt)
 }
 
+func TestPeriodicalExecutor_FlushWith(t *testing.T) {
+	var lock sync.Mutex
+	var vals []int
+	executor := NewPeriodicalExecutor(time.Minute, newContainer(0, func(tasks any) {
+		time.Sleep(10 * time.Millisecond)
+		lock.Lock()
+		vals = append(vals, tasks.([]int)...)
+		lock.Unlock()
+	}))
+	for i := 0; i <= threshold; i++ {
+		executor.Add(i)
+	}
+	executor.Add(threshold + 1)
+
+	// 先完成执行中的任务，再以 fn 执行挂起的任务。
+	assert.True(t, executor.FlushWith(func(tasks any) {
+		lock.Lock()
+		vals = append(vals, tasks.([]int)...)
+		lock.Unlock()
+	}))
+	assert.False(t, executor.FlushWith(func(tasks any) {
+		t.Fatal("不应执行")
+	}))
+
+	var expect []int
+	for i := 0; i <= threshold+1; i++ {
+		expect = append(expect, i)
+	}
+	lock.Lock()
+	assert.Equal(t, expect, vals)
+	lock.Unlock()
+}
+
 func TestPeriodicalExecutor_Deadlock(t *testing.T) {
 	executor := NewBulkExecutor(func(tasks []any) {
 	}, WithBulkTasks(1), WithBulkInterval(time.Millisecond))
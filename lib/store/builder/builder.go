@@ -1,6 +1,7 @@
 package builder
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -8,6 +9,23 @@ import (
 
 const dbTag = "db"
 
+var (
+	// ErrNoTable 表示未指定表名。
+	ErrNoTable = errors.New("未指定表名")
+	// ErrNoColumns 表示没有可写入的列，如 SetNonZero 的结构体字段全为零值。
+	ErrNoColumns = errors.New("没有可写入的列")
+	// ErrNoRows 表示插入的结构体切片为空。
+	ErrNoRows = errors.New("没有可插入的行")
+	// ErrNoConflictKeys 表示 PostgreSQL 的 upsert 未指定冲突键。
+	ErrNoConflictKeys = errors.New("PostgreSQL 的 upsert 须指定冲突键")
+	// ErrReturningUnsupported 表示 MySQL 不支持 RETURNING。
+	ErrReturningUnsupported = errors.New("MySQL 不支持 RETURNING")
+	// ErrNoWhere 表示更新或删除语句没有 WHERE 条件，作用于全表须显式调用 All。
+	ErrNoWhere = errors.New("缺少 WHERE 条件，作用于全表须显式调用 All")
+	// ErrNotStruct 表示传入的值不是结构体或结构体指针。
+	ErrNotStruct = errors.New("只接收结构体")
+)
+
 // RawFieldNames 转换 golang 结构体字段为字符串切片。
 func RawFieldNames(in any, postgreSql ...bool) []string {
 	out := make([]string, 0)
@@ -34,8 +52,9 @@ func RawFieldNames(in any, postgreSql ...bool) []string {
 		case "-":
 			continue
 		case "":
+			// pg 会将未加引号的标识符转为小写，故须保留字段名大小写
 			if pg {
-				out = append(out, field.Name)
+				out = append(out, fmt.Sprintf(`"%s"`, field.Name))
 			} else {
 				out = append(out, fmt.Sprintf("`%s`", field.Name))
 			}
@@ -48,7 +67,12 @@ func RawFieldNames(in any, postgreSql ...bool) []string {
 				tagValue = strings.TrimSpace(strings.Split(tagValue, ",")[0])
 			}
 			if len(tagValue) == 0 {
-				tagValue = field.Name
+				if pg {
+					out = append(out, fmt.Sprintf(`"%s"`, field.Name))
+				} else {
+					out = append(out, fmt.Sprintf("`%s`", field.Name))
+				}
+				continue
 			}
 			if pg {
 				out = append(out, tagValue)
@@ -61,12 +85,20 @@ func RawFieldNames(in any, postgreSql ...bool) []string {
 	return out
 }
 
-// PostgreSqlJoin 连接给定字符串切片到一个字符串
+// PostgreSqlJoin 连接给定字符串切片到一个字符串，占位符从 $2 开始，$1 留给主键。
 func PostgreSqlJoin(vs []string) string {
+	return PostgreSqlJoinFrom(vs, 2)
+}
+
+// PostgreSqlJoinFrom 连接给定字符串切片到一个字符串，占位符从 $start 开始。
+func PostgreSqlJoinFrom(vs []string, start int) string {
 	b := new(strings.Builder)
 	for i, v := range vs {
-		b.WriteString(fmt.Sprintf("%s = $%d, ", v, i+2))
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%s = $%d", v, i+start))
 	}
 
-	return b.String()[0 : b.Len()-2]
+	return b.String()
 }
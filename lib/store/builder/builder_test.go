@@ -38,3 +38,23 @@ func TestFieldNamesWithTagOptions(t *testing.T) {
 		assert.Equal(t, expected, out)
 	})
 }
+
+type mockedPgUser struct {
+	ID       string `db:"id"`
+	UserName string
+	Age      int `db:",type=int"`
+	Ignored  int `db:"-"`
+}
+
+func TestPostgreSqlFieldNames(t *testing.T) {
+	var u mockedPgUser
+	out := RawFieldNames(&u, true)
+	expected := []string{"id", `"UserName"`, `"Age"`}
+	assert.Equal(t, expected, out)
+}
+
+func TestPostgreSqlJoin(t *testing.T) {
+	assert.Equal(t, "name = $2, age = $3", PostgreSqlJoin([]string{"name", "age"}))
+	assert.Equal(t, "name = $1, age = $2", PostgreSqlJoinFrom([]string{"name", "age"}, 1))
+	assert.Equal(t, "", PostgreSqlJoin(nil))
+}
@@ -0,0 +1,274 @@
+package builder
+
+import (
+	"reflect"
+	"strings"
+)
+
+type (
+	// Cond 是可组合的 WHERE 条件，nil 条件将被忽略，便于按需拼接可选条件。
+	Cond interface {
+		writeTo(b *buffer)
+	}
+
+	compareCond struct {
+		column string
+		op     string
+		value  any
+	}
+
+	inCond struct {
+		column string
+		values []any
+		not    bool
+	}
+
+	betweenCond struct {
+		column string
+		low    any
+		high   any
+		not    bool
+	}
+
+	nullCond struct {
+		column string
+		not    bool
+	}
+
+	groupCond struct {
+		sep   string
+		conds []Cond
+	}
+
+	exprCond struct {
+		expr string
+		args []any
+	}
+)
+
+// Eq 返回 column = value 条件。
+func Eq(column string, value any) Cond {
+	return compareCond{column: column, op: "=", value: value}
+}
+
+// Neq 返回 column <> value 条件。
+func Neq(column string, value any) Cond {
+	return compareCond{column: column, op: "<>", value: value}
+}
+
+// Gt 返回 column > value 条件。
+func Gt(column string, value any) Cond {
+	return compareCond{column: column, op: ">", value: value}
+}
+
+// Gte 返回 column >= value 条件。
+func Gte(column string, value any) Cond {
+	return compareCond{column: column, op: ">=", value: value}
+}
+
+// Lt 返回 column < value 条件。
+func Lt(column string, value any) Cond {
+	return compareCond{column: column, op: "<", value: value}
+}
+
+// Lte 返回 column <= value 条件。
+func Lte(column string, value any) Cond {
+	return compareCond{column: column, op: "<=", value: value}
+}
+
+// Like 返回 column LIKE pattern 条件，pattern 中的通配符须由调用方给出。
+func Like(column, pattern string) Cond {
+	return compareCond{column: column, op: "LIKE", value: pattern}
+}
+
+// NotLike 返回 column NOT LIKE pattern 条件。
+func NotLike(column, pattern string) Cond {
+	return compareCond{column: column, op: "NOT LIKE", value: pattern}
+}
+
+// In 返回 column IN (...) 条件，values 为切片或数组，为空时条件恒假。
+func In(column string, values any) Cond {
+	return inCond{column: column, values: expand(values)}
+}
+
+// NotIn 返回 column NOT IN (...) 条件，values 为空时条件恒真。
+func NotIn(column string, values any) Cond {
+	return inCond{column: column, values: expand(values), not: true}
+}
+
+// Between 返回 column BETWEEN low AND high 条件。
+func Between(column string, low, high any) Cond {
+	return betweenCond{column: column, low: low, high: high}
+}
+
+// NotBetween 返回 column NOT BETWEEN low AND high 条件。
+func NotBetween(column string, low, high any) Cond {
+	return betweenCond{column: column, low: low, high: high, not: true}
+}
+
+// IsNull 返回 column IS NULL 条件。
+func IsNull(column string) Cond {
+	return nullCond{column: column}
+}
+
+// IsNotNull 返回 column IS NOT NULL 条件。
+func IsNotNull(column string) Cond {
+	return nullCond{column: column, not: true}
+}
+
+// And 以 AND 组合条件，无条件时恒真。
+func And(conds ...Cond) Cond {
+	return groupCond{sep: " AND ", conds: conds}
+}
+
+// Or 以 OR 组合条件，无条件时恒假。
+func Or(conds ...Cond) Cond {
+	return groupCond{sep: " OR ", conds: conds}
+}
+
+// Expr 返回以 ? 为占位符的原始条件表达式，PostgreSQL 下自动改写为 $n。
+func Expr(expr string, args ...any) Cond {
+	return exprCond{expr: expr, args: args}
+}
+
+func (c compareCond) writeTo(b *buffer) {
+	b.writeIdent(c.column)
+	b.WriteString(" " + c.op + " ")
+	b.writeArg(c.value)
+}
+
+func (c inCond) writeTo(b *buffer) {
+	if len(c.values) == 0 {
+		if c.not {
+			b.WriteString("1 = 1")
+		} else {
+			b.WriteString("1 = 0")
+		}
+		return
+	}
+
+	b.writeIdent(c.column)
+	if c.not {
+		b.WriteString(" NOT IN (")
+	} else {
+		b.WriteString(" IN (")
+	}
+	for i, v := range c.values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.writeArg(v)
+	}
+	b.WriteString(")")
+}
+
+func (c betweenCond) writeTo(b *buffer) {
+	b.writeIdent(c.column)
+	if c.not {
+		b.WriteString(" NOT BETWEEN ")
+	} else {
+		b.WriteString(" BETWEEN ")
+	}
+	b.writeArg(c.low)
+	b.WriteString(" AND ")
+	b.writeArg(c.high)
+}
+
+func (c nullCond) writeTo(b *buffer) {
+	b.writeIdent(c.column)
+	if c.not {
+		b.WriteString(" IS NOT NULL")
+	} else {
+		b.WriteString(" IS NULL")
+	}
+}
+
+func (c groupCond) writeTo(b *buffer) {
+	conds := compact(c.conds)
+	switch len(conds) {
+	case 0:
+		if strings.TrimSpace(c.sep) == "AND" {
+			b.WriteString("1 = 1")
+		} else {
+			b.WriteString("1 = 0")
+		}
+	case 1:
+		conds[0].writeTo(b)
+	default:
+		for i, cond := range conds {
+			if i > 0 {
+				b.WriteString(c.sep)
+			}
+			// 嵌套的组合条件及原始表达式须加括号以保持优先级
+			switch cond.(type) {
+			case groupCond, exprCond:
+				b.WriteString("(")
+				cond.writeTo(b)
+				b.WriteString(")")
+			default:
+				cond.writeTo(b)
+			}
+		}
+	}
+}
+
+func (c exprCond) writeTo(b *buffer) {
+	b.writeExpr(c.expr, c.args)
+}
+
+// emptyConds 判断条件是否全为 nil、空的 NotIn 或由其组成的 And，此时条件恒真。
+func emptyConds(conds []Cond) bool {
+	for _, cond := range conds {
+		switch c := cond.(type) {
+		case nil:
+		case inCond:
+			if !c.not || len(c.values) > 0 {
+				return false
+			}
+		case groupCond:
+			if strings.TrimSpace(c.sep) != "AND" || !emptyConds(c.conds) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func compact(conds []Cond) []Cond {
+	out := make([]Cond, 0, len(conds))
+	for _, cond := range conds {
+		if cond != nil {
+			out = append(out, cond)
+		}
+	}
+
+	return out
+}
+
+func expand(values any) []any {
+	if vals, ok := values.([]any); ok {
+		return vals
+	}
+
+	rv := reflect.ValueOf(values)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		// []byte 是单个值而非列表
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return []any{values}
+		}
+
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = rv.Index(i).Interface()
+		}
+		return out
+	case reflect.Invalid:
+		return nil
+	default:
+		return []any{values}
+	}
+}
@@ -0,0 +1,116 @@
+package builder
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConds(t *testing.T) {
+	tests := []struct {
+		name  string
+		cond  Cond
+		query string
+		pg    string
+		args  []any
+	}{
+		{
+			name:  "eq",
+			cond:  Eq("name", "kevin"),
+			query: "`name` = ?",
+			pg:    `"name" = $1`,
+			args:  []any{"kevin"},
+		},
+		{
+			name:  "qualified",
+			cond:  Gte("u.age", 18),
+			query: "`u`.`age` >= ?",
+			pg:    `"u"."age" >= $1`,
+			args:  []any{18},
+		},
+		{
+			name:  "in",
+			cond:  In("id", []int64{1, 2, 3}),
+			query: "`id` IN (?, ?, ?)",
+			pg:    `"id" IN ($1, $2, $3)`,
+			args:  []any{int64(1), int64(2), int64(3)},
+		},
+		{
+			name:  "empty in",
+			cond:  In("id", []int{}),
+			query: "1 = 0",
+			pg:    "1 = 0",
+		},
+		{
+			name:  "empty not in",
+			cond:  NotIn("id", nil),
+			query: "1 = 1",
+			pg:    "1 = 1",
+		},
+		{
+			name:  "between",
+			cond:  Between("age", 10, 20),
+			query: "`age` BETWEEN ? AND ?",
+			pg:    `"age" BETWEEN $1 AND $2`,
+			args:  []any{10, 20},
+		},
+		{
+			name:  "like",
+			cond:  And(Like("name", "ke%"), IsNotNull("email"), nil),
+			query: "`name` LIKE ? AND `email` IS NOT NULL",
+			pg:    `"name" LIKE $1 AND "email" IS NOT NULL`,
+			args:  []any{"ke%"},
+		},
+		{
+			name:  "nested",
+			cond:  And(Eq("status", 1), Or(Lt("age", 10), Gt("age", 60)), Expr("score > ? OR vip", 90)),
+			query: "`status` = ? AND (`age` < ? OR `age` > ?) AND (score > ? OR vip)",
+			pg:    `"status" = $1 AND ("age" < $2 OR "age" > $3) AND (score > $4 OR vip)`,
+			args:  []any{1, 10, 60, 90},
+		},
+		{
+			name:  "empty or",
+			cond:  Or(),
+			query: "1 = 0",
+			pg:    "1 = 0",
+		},
+		{
+			name:  "quoted",
+			cond:  Expr(`name = '?' AND note <> "a?" AND tag = 'it''s?' AND id = ?`, 1),
+			query: "name = '?' AND note <> \"a?\" AND tag = 'it''s?' AND id = ?",
+			pg:    `name = '?' AND note <> "a?" AND tag = 'it''s?' AND id = $1`,
+			args:  []any{1},
+		},
+		{
+			name:  "bytes",
+			cond:  In("data", []byte("abc")),
+			query: "`data` IN (?)",
+			pg:    `"data" IN ($1)`,
+			args:  []any{[]byte("abc")},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			buf := &buffer{dialect: MySQL}
+			test.cond.writeTo(buf)
+			query, args := buf.build()
+			assert.Equal(t, test.query, query)
+			assert.Equal(t, test.args, args)
+
+			buf = &buffer{dialect: PostgreSQL}
+			test.cond.writeTo(buf)
+			query, args = buf.build()
+			assert.Equal(t, test.pg, query)
+			assert.Equal(t, test.args, args)
+		})
+	}
+}
+
+func TestExprBackslash(t *testing.T) {
+	buf := &buffer{dialect: MySQL}
+	Expr(`tag = 'it\'s?' AND id = ?`, 1).writeTo(buf)
+	query, args := buf.build()
+	assert.Equal(t, `tag = 'it\'s?' AND id = ?`, query)
+	assert.Equal(t, []any{1}, args)
+}
@@ -0,0 +1,200 @@
+package builder
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// MySQL 使用 ? 占位符及反引号标识符。
+	MySQL Dialect = iota
+	// PostgreSQL 使用 $n 占位符及双引号标识符。
+	PostgreSQL
+)
+
+// Dialect 是 SQL 方言，决定占位符、标识符引号及 upsert 语法。
+type Dialect int
+
+// Select 返回一个使用该方言的查询语句构建器。
+func (d Dialect) Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{
+		dialect: d,
+		columns: columns,
+	}
+}
+
+// Insert 返回一个使用该方言的插入语句构建器，v 为结构体、结构体指针或其切片。
+func (d Dialect) Insert(table string, v any) *InsertBuilder {
+	return &InsertBuilder{
+		dialect: d,
+		table:   table,
+		value:   v,
+	}
+}
+
+// Upsert 返回一个使用该方言的插入或更新语句构建器，v 为结构体、结构体指针或其切片。
+func (d Dialect) Upsert(table string, v any) *InsertBuilder {
+	return &InsertBuilder{
+		dialect: d,
+		table:   table,
+		value:   v,
+		upsert:  true,
+	}
+}
+
+// Update 返回一个使用该方言的更新语句构建器。
+func (d Dialect) Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{
+		dialect: d,
+		table:   table,
+	}
+}
+
+// Delete 返回一个使用该方言的删除语句构建器。
+func (d Dialect) Delete(table string) *DeleteBuilder {
+	return &DeleteBuilder{
+		dialect: d,
+		table:   table,
+	}
+}
+
+// Select 返回一个 MySQL 查询语句构建器。
+func Select(columns ...string) *SelectBuilder {
+	return MySQL.Select(columns...)
+}
+
+// Insert 返回一个 MySQL 插入语句构建器。
+func Insert(table string, v any) *InsertBuilder {
+	return MySQL.Insert(table, v)
+}
+
+// Upsert 返回一个 MySQL 插入或更新语句构建器。
+func Upsert(table string, v any) *InsertBuilder {
+	return MySQL.Upsert(table, v)
+}
+
+// Update 返回一个 MySQL 更新语句构建器。
+func Update(table string) *UpdateBuilder {
+	return MySQL.Update(table)
+}
+
+// Delete 返回一个 MySQL 删除语句构建器。
+func Delete(table string) *DeleteBuilder {
+	return MySQL.Delete(table)
+}
+
+// 带方言的语句缓冲区，负责占位符编号及标识符引号。
+type buffer struct {
+	dialect Dialect
+	sb      strings.Builder
+	args    []any
+}
+
+func (b *buffer) WriteString(s string) {
+	b.sb.WriteString(s)
+}
+
+// writeIdent 写入标识符，仅对形如 name 或 table.name 的标识符加引号，其余原样写入。
+func (b *buffer) writeIdent(name string) {
+	parts := strings.Split(name, ".")
+	for _, part := range parts {
+		if !isIdent(part) {
+			b.sb.WriteString(name)
+			return
+		}
+	}
+
+	for i, part := range parts {
+		if i > 0 {
+			b.sb.WriteByte('.')
+		}
+		b.sb.WriteString(b.dialect.quote(part))
+	}
+}
+
+func (b *buffer) writeIdents(names []string) {
+	for i, name := range names {
+		if i > 0 {
+			b.sb.WriteString(", ")
+		}
+		b.writeIdent(name)
+	}
+}
+
+func (b *buffer) writeArg(arg any) {
+	b.args = append(b.args, arg)
+	if b.dialect == PostgreSQL {
+		b.sb.WriteByte('$')
+		b.sb.WriteString(strconv.Itoa(len(b.args)))
+	} else {
+		b.sb.WriteByte('?')
+	}
+}
+
+// writeExpr 写入以 ? 为占位符的表达式，PostgreSQL 下改写为 $n，引号内的 ? 原样保留。
+func (b *buffer) writeExpr(expr string, args []any) {
+	var n int
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			b.sb.WriteByte(c)
+			// MySQL 字符串中反斜杠转义其后的字符
+			if c == '\\' && quote != '`' && b.dialect == MySQL && i+1 < len(expr) {
+				i++
+				b.sb.WriteByte(expr[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			b.sb.WriteByte(c)
+		case c == '?' && n < len(args):
+			b.writeArg(args[n])
+			n++
+		default:
+			b.sb.WriteByte(c)
+		}
+	}
+}
+
+func (b *buffer) writeWhere(conds []Cond) {
+	conds = compact(conds)
+	if len(conds) == 0 {
+		return
+	}
+
+	b.sb.WriteString(" WHERE ")
+	And(conds...).writeTo(b)
+}
+
+func (b *buffer) build() (string, []any) {
+	return b.sb.String(), b.args
+}
+
+func (d Dialect) quote(name string) string {
+	if d == PostgreSQL {
+		return `"` + name + `"`
+	}
+
+	return "`" + name + "`"
+}
+
+func isIdent(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,133 @@
+package builder
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// 结构体字段对应的列及其值。
+type field struct {
+	column string
+	value  reflect.Value
+}
+
+// Columns 返回结构体的列名（不含引号），in 不是结构体时 panic。
+// 与 RawFieldNames 不同，匿名结构体字段将被展开，未导出字段将被忽略。
+func Columns(in any) []string {
+	v, err := indirectStruct(in, "Columns")
+	if err != nil {
+		panic(err)
+	}
+
+	fields := structFields(v)
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+
+	return columns
+}
+
+func indirectStruct(in any, caller string) (reflect.Value, error) {
+	v := reflect.ValueOf(in)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	// 我们只接收结构体
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w，%s 却收到 %T", ErrNotStruct, caller, in)
+	}
+
+	return v, nil
+}
+
+func structFields(v reflect.Value) []field {
+	var fields []field
+	tp := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		sf := tp.Field(i)
+		tagValue := sf.Tag.Get(dbTag)
+		if tagValue == "-" || !sf.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		if sf.Anonymous && len(tagValue) == 0 {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv = reflect.New(fv.Type().Elem()).Elem()
+				} else {
+					fv = fv.Elem()
+				}
+			}
+			if fv.Kind() == reflect.Struct && !isValue(fv.Type()) {
+				fields = append(fields, structFields(fv)...)
+				continue
+			}
+		}
+
+		name := strings.TrimSpace(strings.Split(tagValue, ",")[0])
+		if len(name) == 0 {
+			name = sf.Name
+		}
+		fields = append(fields, field{
+			column: name,
+			value:  fv,
+		})
+	}
+
+	return fields
+}
+
+// 读取单个或多个结构体的字段，v 为结构体、结构体指针或其切片。
+func structRows(v any, caller string) ([][]field, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		sv, err := indirectStruct(v, caller)
+		if err != nil {
+			return nil, err
+		}
+
+		return [][]field{structFields(sv)}, nil
+	}
+
+	rows := make([][]field, rv.Len())
+	for i := range rows {
+		sv, err := indirectStruct(rv.Index(i).Interface(), caller)
+		if err != nil {
+			return nil, err
+		}
+
+		rows[i] = structFields(sv)
+	}
+
+	return rows, nil
+}
+
+func omitted(omits []string, column string) bool {
+	for _, omit := range omits {
+		if strings.EqualFold(omit, column) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 时间及 driver.Valuer 作为单个值写入，不展开。
+func isValue(tp reflect.Type) bool {
+	return tp == timeType || tp.Implements(valuerType) || reflect.PtrTo(tp).Implements(valuerType)
+}
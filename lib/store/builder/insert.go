@@ -0,0 +1,155 @@
+package builder
+
+// InsertBuilder 是插入及插入或更新（upsert）语句构建器。
+type InsertBuilder struct {
+	dialect   Dialect
+	table     string
+	value     any
+	omits     []string
+	upsert    bool
+	conflicts []string
+	updates   []string
+	returning []string
+}
+
+// Omit 忽略给定的列，如自增主键。
+func (b *InsertBuilder) Omit(columns ...string) *InsertBuilder {
+	b.omits = append(b.omits, columns...)
+	return b
+}
+
+// OnConflict 设置 upsert 的冲突键，PostgreSQL 必须指定，MySQL 仅用于排除更新列。
+func (b *InsertBuilder) OnConflict(columns ...string) *InsertBuilder {
+	b.conflicts = append(b.conflicts, columns...)
+	return b
+}
+
+// UpdateColumns 设置 upsert 冲突时更新的列，默认为除冲突键外的全部插入列。
+func (b *InsertBuilder) UpdateColumns(columns ...string) *InsertBuilder {
+	b.updates = append(b.updates, columns...)
+	return b
+}
+
+// Returning 设置 PostgreSQL 插入后返回的列，MySQL 不支持。
+func (b *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	b.returning = append(b.returning, columns...)
+	return b
+}
+
+// Build 返回插入语句及其参数，多个结构体将生成一条多行插入语句。
+func (b *InsertBuilder) Build() (string, []any, error) {
+	if len(b.table) == 0 {
+		return "", nil, ErrNoTable
+	}
+	if len(b.returning) > 0 && b.dialect != PostgreSQL {
+		return "", nil, ErrReturningUnsupported
+	}
+	if b.upsert && b.dialect == PostgreSQL && len(b.conflicts) == 0 {
+		return "", nil, ErrNoConflictKeys
+	}
+
+	rows, err := structRows(b.value, "Insert")
+	if err != nil {
+		return "", nil, err
+	}
+	if len(rows) == 0 {
+		return "", nil, ErrNoRows
+	}
+
+	var columns []string
+	for _, f := range rows[0] {
+		if !omitted(b.omits, f.column) {
+			columns = append(columns, f.column)
+		}
+	}
+	if len(columns) == 0 {
+		return "", nil, ErrNoColumns
+	}
+
+	buf := &buffer{dialect: b.dialect}
+	buf.WriteString("INSERT INTO ")
+	buf.writeIdent(b.table)
+	buf.WriteString(" (")
+	buf.writeIdents(columns)
+	buf.WriteString(") VALUES ")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("(")
+		var n int
+		for _, f := range row {
+			if omitted(b.omits, f.column) {
+				continue
+			}
+			if n > 0 {
+				buf.WriteString(", ")
+			}
+			buf.writeArg(f.value.Interface())
+			n++
+		}
+		buf.WriteString(")")
+	}
+
+	if b.upsert {
+		b.writeUpsert(buf, columns)
+	}
+
+	if len(b.returning) > 0 {
+		buf.WriteString(" RETURNING ")
+		buf.writeIdents(b.returning)
+	}
+
+	query, args := buf.build()
+	return query, args, nil
+}
+
+func (b *InsertBuilder) writeUpsert(buf *buffer, columns []string) {
+	updates := b.updates
+	if len(updates) == 0 {
+		for _, column := range columns {
+			if !omitted(b.conflicts, column) {
+				updates = append(updates, column)
+			}
+		}
+	}
+
+	if b.dialect == PostgreSQL {
+		buf.WriteString(" ON CONFLICT (")
+		buf.writeIdents(b.conflicts)
+		if len(updates) == 0 {
+			buf.WriteString(") DO NOTHING")
+			return
+		}
+
+		buf.WriteString(") DO UPDATE SET ")
+		for i, column := range updates {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.writeIdent(column)
+			buf.WriteString(" = EXCLUDED.")
+			buf.writeIdent(column)
+		}
+		return
+	}
+
+	buf.WriteString(" ON DUPLICATE KEY UPDATE ")
+	// 全部为冲突键时，以自身赋值实现忽略冲突
+	if len(updates) == 0 {
+		buf.writeIdent(columns[0])
+		buf.WriteString(" = ")
+		buf.writeIdent(columns[0])
+		return
+	}
+
+	for i, column := range updates {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.writeIdent(column)
+		buf.WriteString(" = VALUES(")
+		buf.writeIdent(column)
+		buf.WriteString(")")
+	}
+}
@@ -0,0 +1,74 @@
+package builder
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type MockedBase struct {
+	CreateTime time.Time `db:"create_time"`
+}
+
+type mockedOrder struct {
+	Id     int64  `db:"id"`
+	UserId int64  `db:"user_id"`
+	Status int    `db:"status"`
+	Memo   string `db:"-"`
+	MockedBase
+}
+
+func TestInsert(t *testing.T) {
+	now := time.Now()
+	query, args, err := Insert("order", &mockedOrder{UserId: 1, Status: 2, MockedBase: MockedBase{now}}).
+		Omit("id").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO `order` (`user_id`, `status`, `create_time`) VALUES (?, ?, ?)", query)
+	assert.Equal(t, []any{int64(1), 2, now}, args)
+}
+
+func TestInsertMany(t *testing.T) {
+	orders := []*mockedOrder{{Id: 1, UserId: 1}, {Id: 2, UserId: 2}}
+	query, args, err := PostgreSQL.Insert("order", orders).Omit("create_time").Returning("id").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO "order" ("id", "user_id", "status") VALUES ($1, $2, $3), ($4, $5, $6) RETURNING "id"`, query)
+	assert.Equal(t, []any{int64(1), int64(1), 0, int64(2), int64(2), 0}, args)
+
+	_, _, err = Insert("order", []mockedOrder{}).Build()
+	assert.Equal(t, ErrNoRows, err)
+	_, _, err = Insert("order", orders).Returning("id").Build()
+	assert.Equal(t, ErrReturningUnsupported, err)
+	_, _, err = Insert("order", 1).Build()
+	assert.ErrorIs(t, err, ErrNotStruct)
+	_, _, err = Insert("order", []int{1}).Build()
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
+
+func TestUpsert(t *testing.T) {
+	order := mockedOrder{Id: 1, UserId: 2, Status: 3}
+	query, args, err := Upsert("order", order).Omit("create_time").OnConflict("id").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO `order` (`id`, `user_id`, `status`) VALUES (?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE `user_id` = VALUES(`user_id`), `status` = VALUES(`status`)", query)
+	assert.Equal(t, []any{int64(1), int64(2), 3}, args)
+
+	query, _, err = Upsert("order", order).Omit("create_time").
+		OnConflict("id", "user_id", "status").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO `order` (`id`, `user_id`, `status`) VALUES (?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE `id` = `id`", query)
+
+	query, _, err = PostgreSQL.Upsert("order", order).Omit("create_time").
+		OnConflict("id").UpdateColumns("status").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO "order" ("id", "user_id", "status") VALUES ($1, $2, $3) `+
+		`ON CONFLICT ("id") DO UPDATE SET "status" = EXCLUDED."status"`, query)
+
+	query, _, err = PostgreSQL.Upsert("order", order).Omit("user_id", "status", "create_time").
+		OnConflict("id").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO "order" ("id") VALUES ($1) ON CONFLICT ("id") DO NOTHING`, query)
+
+	_, _, err = PostgreSQL.Upsert("order", order).Build()
+	assert.Equal(t, ErrNoConflictKeys, err)
+}
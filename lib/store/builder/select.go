@@ -0,0 +1,133 @@
+package builder
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SelectBuilder 是查询语句构建器。
+type SelectBuilder struct {
+	dialect   Dialect
+	columns   []string
+	table     string
+	where     []Cond
+	groupBy   []string
+	having    []Cond
+	orderBy   []string
+	limit     int
+	offset    int
+	forUpdate bool
+}
+
+// From 设置查询的表。
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.table = table
+	return b
+}
+
+// Where 以 AND 追加查询条件，nil 条件将被忽略。
+func (b *SelectBuilder) Where(conds ...Cond) *SelectBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+// GroupBy 设置分组列。
+func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// Having 以 AND 追加分组过滤条件。
+func (b *SelectBuilder) Having(conds ...Cond) *SelectBuilder {
+	b.having = append(b.having, conds...)
+	return b
+}
+
+// OrderBy 设置排序，如 OrderBy("age desc", "id")。
+func (b *SelectBuilder) OrderBy(orders ...string) *SelectBuilder {
+	b.orderBy = append(b.orderBy, orders...)
+	return b
+}
+
+// Limit 设置返回的最大行数，不大于 0 则不限制。
+func (b *SelectBuilder) Limit(limit int) *SelectBuilder {
+	b.limit = limit
+	return b
+}
+
+// Offset 设置跳过的行数。
+func (b *SelectBuilder) Offset(offset int) *SelectBuilder {
+	b.offset = offset
+	return b
+}
+
+// ForUpdate 为查询加上 FOR UPDATE 行锁。
+func (b *SelectBuilder) ForUpdate() *SelectBuilder {
+	b.forUpdate = true
+	return b
+}
+
+// Build 返回查询语句及其参数。
+func (b *SelectBuilder) Build() (string, []any, error) {
+	if len(b.table) == 0 {
+		return "", nil, ErrNoTable
+	}
+
+	buf := &buffer{dialect: b.dialect}
+	buf.WriteString("SELECT ")
+	if len(b.columns) == 0 {
+		buf.WriteString("*")
+	} else {
+		buf.writeIdents(b.columns)
+	}
+	buf.WriteString(" FROM ")
+	buf.writeIdent(b.table)
+	buf.writeWhere(b.where)
+
+	if len(b.groupBy) > 0 {
+		buf.WriteString(" GROUP BY ")
+		buf.writeIdents(b.groupBy)
+	}
+	if having := compact(b.having); len(having) > 0 {
+		buf.WriteString(" HAVING ")
+		And(having...).writeTo(buf)
+	}
+
+	if len(b.orderBy) > 0 {
+		buf.WriteString(" ORDER BY ")
+		for i, order := range b.orderBy {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeOrder(buf, order)
+		}
+	}
+
+	if b.limit > 0 {
+		buf.WriteString(" LIMIT ")
+		buf.WriteString(strconv.Itoa(b.limit))
+	}
+	if b.offset > 0 {
+		buf.WriteString(" OFFSET ")
+		buf.WriteString(strconv.Itoa(b.offset))
+	}
+	if b.forUpdate {
+		buf.WriteString(" FOR UPDATE")
+	}
+
+	query, args := buf.build()
+	return query, args, nil
+}
+
+func writeOrder(buf *buffer, order string) {
+	fields := strings.Fields(order)
+	if len(fields) == 0 {
+		return
+	}
+
+	buf.writeIdent(fields[0])
+	if len(fields) > 1 {
+		buf.WriteString(" ")
+		buf.WriteString(strings.ToUpper(strings.Join(fields[1:], " ")))
+	}
+}
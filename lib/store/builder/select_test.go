@@ -0,0 +1,36 @@
+package builder
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	query, args, err := Select(Columns(mockedUser{})...).From("user").
+		Where(Eq("sex", 1), nil, In("age", []int{18, 20})).
+		OrderBy("age desc", "id").Limit(10).Offset(20).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT `id`, `user_name`, `sex`, `uuid`, `age` FROM `user` "+
+		"WHERE `sex` = ? AND `age` IN (?, ?) ORDER BY `age` DESC, `id` LIMIT 10 OFFSET 20", query)
+	assert.Equal(t, []any{1, 18, 20}, args)
+}
+
+func TestSelectPostgres(t *testing.T) {
+	query, args, err := PostgreSQL.Select("sex", "count(*) AS total").From("public.user").
+		Where(Between("age", 18, 30)).GroupBy("sex").Having(Expr("count(*) > ?", 5)).
+		ForUpdate().Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT "sex", count(*) AS total FROM "public"."user" `+
+		`WHERE "age" BETWEEN $1 AND $2 GROUP BY "sex" HAVING count(*) > $3 FOR UPDATE`, query)
+	assert.Equal(t, []any{18, 30, 5}, args)
+}
+
+func TestSelectAll(t *testing.T) {
+	query, args, err := Select().From("user").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM `user`", query)
+	assert.Nil(t, args)
+
+	_, _, err = Select().Build()
+	assert.Equal(t, ErrNoTable, err)
+}
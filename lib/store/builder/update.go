@@ -0,0 +1,166 @@
+package builder
+
+type (
+	// UpdateBuilder 是更新语句构建器。
+	UpdateBuilder struct {
+		dialect Dialect
+		table   string
+		sets    []assignment
+		omits   []string
+		where   []Cond
+		all     bool
+		err     error
+	}
+
+	// DeleteBuilder 是删除语句构建器。
+	DeleteBuilder struct {
+		dialect Dialect
+		table   string
+		where   []Cond
+		all     bool
+	}
+
+	assignment struct {
+		column string
+		expr   string
+		args   []any
+	}
+)
+
+// Set 设置列的新值。
+func (b *UpdateBuilder) Set(column string, value any) *UpdateBuilder {
+	b.sets = append(b.sets, assignment{
+		column: column,
+		expr:   "?",
+		args:   []any{value},
+	})
+	return b
+}
+
+// SetExpr 以 ? 为占位符的表达式设置列的新值，如 SetExpr("count", "count + ?", 1)。
+func (b *UpdateBuilder) SetExpr(column, expr string, args ...any) *UpdateBuilder {
+	b.sets = append(b.sets, assignment{
+		column: column,
+		expr:   expr,
+		args:   args,
+	})
+	return b
+}
+
+// SetStruct 以结构体的全部字段设置新值，v 不是结构体时 Build 返回 ErrNotStruct。
+func (b *UpdateBuilder) SetStruct(v any) *UpdateBuilder {
+	sv, err := indirectStruct(v, "SetStruct")
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for _, f := range structFields(sv) {
+		b.Set(f.column, f.value.Interface())
+	}
+	return b
+}
+
+// SetNonZero 仅以结构体的非零值字段设置新值，用于部分更新，v 不是结构体时 Build 返回 ErrNotStruct。
+func (b *UpdateBuilder) SetNonZero(v any) *UpdateBuilder {
+	sv, err := indirectStruct(v, "SetNonZero")
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for _, f := range structFields(sv) {
+		if !f.value.IsZero() {
+			b.Set(f.column, f.value.Interface())
+		}
+	}
+	return b
+}
+
+// Omit 忽略给定列的设置，如主键。
+func (b *UpdateBuilder) Omit(columns ...string) *UpdateBuilder {
+	b.omits = append(b.omits, columns...)
+	return b
+}
+
+// Where 以 AND 追加更新条件，nil 条件将被忽略。
+func (b *UpdateBuilder) Where(conds ...Cond) *UpdateBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+// All 允许在没有 WHERE 条件时更新全表。
+func (b *UpdateBuilder) All() *UpdateBuilder {
+	b.all = true
+	return b
+}
+
+// Build 返回更新语句及其参数，没有可设置的列时返回 ErrNoColumns，
+// 未调用 All 且条件恒真（全为 nil 或空的 NotIn）时返回 ErrNoWhere。
+func (b *UpdateBuilder) Build() (string, []any, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	if len(b.table) == 0 {
+		return "", nil, ErrNoTable
+	}
+
+	buf := &buffer{dialect: b.dialect}
+	buf.WriteString("UPDATE ")
+	buf.writeIdent(b.table)
+	buf.WriteString(" SET ")
+	var n int
+	for _, set := range b.sets {
+		if omitted(b.omits, set.column) {
+			continue
+		}
+		if n > 0 {
+			buf.WriteString(", ")
+		}
+		buf.writeIdent(set.column)
+		buf.WriteString(" = ")
+		buf.writeExpr(set.expr, set.args)
+		n++
+	}
+	if n == 0 {
+		return "", nil, ErrNoColumns
+	}
+	if !b.all && emptyConds(b.where) {
+		return "", nil, ErrNoWhere
+	}
+
+	buf.writeWhere(b.where)
+
+	query, args := buf.build()
+	return query, args, nil
+}
+
+// Where 以 AND 追加删除条件，nil 条件将被忽略。
+func (b *DeleteBuilder) Where(conds ...Cond) *DeleteBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+// All 允许在没有 WHERE 条件时删除全表。
+func (b *DeleteBuilder) All() *DeleteBuilder {
+	b.all = true
+	return b
+}
+
+// Build 返回删除语句及其参数，未调用 All 且条件恒真（全为 nil 或空的 NotIn）时返回 ErrNoWhere。
+func (b *DeleteBuilder) Build() (string, []any, error) {
+	if len(b.table) == 0 {
+		return "", nil, ErrNoTable
+	}
+	if !b.all && emptyConds(b.where) {
+		return "", nil, ErrNoWhere
+	}
+
+	buf := &buffer{dialect: b.dialect}
+	buf.WriteString("DELETE FROM ")
+	buf.writeIdent(b.table)
+	buf.writeWhere(b.where)
+
+	query, args := buf.build()
+	return query, args, nil
+}
@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	query, args, err := Update("user").SetNonZero(mockedUser{ID: "1", UserName: "kevin"}).
+		SetExpr("age", "age + ?", 1).Omit("id").Where(Eq("id", "1")).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE `user` SET `user_name` = ?, `age` = age + ? WHERE `id` = ?", query)
+	assert.Equal(t, []any{"kevin", 1, "1"}, args)
+
+	_, _, err = Update("user").SetNonZero(&mockedUser{ID: "1"}).Omit("id").Build()
+	assert.Equal(t, ErrNoColumns, err)
+
+	_, _, err = Update("user").Set("age", 1).Where(nil).Build()
+	assert.Equal(t, ErrNoWhere, err)
+	_, _, err = Update("user").Set("age", 1).Where(NotIn("id", []int{})).Build()
+	assert.Equal(t, ErrNoWhere, err)
+	query, _, err = Update("user").Set("age", 1).All().Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE `user` SET `age` = ?", query)
+
+	var u *mockedUser
+	_, _, err = Update("user").SetStruct(u).Where(Eq("id", "1")).Build()
+	assert.ErrorIs(t, err, ErrNotStruct)
+	_, _, err = Update("user").SetNonZero(1).Where(Eq("id", "1")).Build()
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
+
+func TestUpdatePostgres(t *testing.T) {
+	query, args, err := PostgreSQL.Update("user").SetStruct(mockedUser{ID: "1", Age: 18}).
+		Omit("id", "uuid").Where(Eq("id", "1")).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `UPDATE "user" SET "user_name" = $1, "sex" = $2, "age" = $3 WHERE "id" = $4`, query)
+	assert.Equal(t, []any{"", 0, 18, "1"}, args)
+}
+
+func TestDelete(t *testing.T) {
+	query, args, err := Delete("user").Where(In("id", []string{"1", "2"}), Lt("age", 18)).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE FROM `user` WHERE `id` IN (?, ?) AND `age` < ?", query)
+	assert.Equal(t, []any{"1", "2", 18}, args)
+
+	query, args, err = PostgreSQL.Delete("user").All().Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `DELETE FROM "user"`, query)
+	assert.Nil(t, args)
+
+	// 可选条件全为 nil 时不允许删除全表。
+	var optional Cond
+	_, _, err = Delete("user").Where(optional).Build()
+	assert.Equal(t, ErrNoWhere, err)
+	_, _, err = Delete("user").Where(And(nil, And())).Build()
+	assert.Equal(t, ErrNoWhere, err)
+	_, _, err = Delete("user").Build()
+	assert.Equal(t, ErrNoWhere, err)
+	// 空的 NotIn 恒真，同样不允许删除全表。
+	_, _, err = Delete("user").Where(NotIn("id", []int{})).Build()
+	assert.Equal(t, ErrNoWhere, err)
+	_, _, err = Delete("user").Where(And(NotIn("id", nil), nil)).Build()
+	assert.Equal(t, ErrNoWhere, err)
+	_, _, err = Delete("user").Where(NotIn("id", []int{1})).Build()
+	assert.Nil(t, err)
+
+	_, _, err = Delete("").Build()
+	assert.Equal(t, ErrNoTable, err)
+}
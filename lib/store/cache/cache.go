@@ -18,6 +18,10 @@ type Cache interface {
 	Get(key string, val any) error
 	// GetCtx 获取给定 key 的缓存并填充至 val。
 	GetCtx(ctx context.Context, key string, val any) error
+	// GetMany 批量获取给定 keys 的缓存并填充至 vals（*map[string]T），仅填充命中的键。
+	GetMany(keys []string, vals any) error
+	// GetManyCtx 批量获取给定 keys 的缓存并填充至 vals（*map[string]T），仅填充命中的键。
+	GetManyCtx(ctx context.Context, keys []string, vals any) error
 	// IsNotFound 判断给定错误是否为预定义的未找到错误。
 	IsNotFound(err error) bool
 	// Set 设置键值对缓存，并将其存活时间设置为 n.expire。
@@ -32,6 +36,10 @@ type Cache interface {
 	Take(val any, key string, query func(val any) error) error
 	// TakeCtx 首先从缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
 	TakeCtx(ctx context.Context, val any, key string, query func(val any) error) error
+	// TakeMany 首先从缓存中批量获取结果，未命中的键以 query 一次批量查询并设置缓存，然后将结果填充至 vals。
+	TakeMany(vals any, keys []string, query ManyQueryFn) error
+	// TakeManyCtx 首先从缓存中批量获取结果，未命中的键以 query 一次批量查询并设置缓存，然后将结果填充至 vals。
+	TakeManyCtx(ctx context.Context, vals any, keys []string, query ManyQueryFn) error
 	// TakeWithExpire 首先从缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
 	TakeWithExpire(val any, key string, query func(val any, expire time.Duration) error) error
 	// TakeWithExpireCtx 首先从缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
@@ -57,5 +65,6 @@ func New(c Config, barrier syncx.SingleFlight, st *Stat, errNotFound error, opts
 	return cluster{
 		dispatcher:  dispatcher,
 		errNotFound: errNotFound,
+		codec:       newOptions(opts...).Codec,
 	}
 }
@@ -55,6 +55,26 @@ func (mn *mockedNode) GetCtx(ctx context.Context, key string, val any) error {
 	return mn.errNotFound
 }
 
+func (mn *mockedNode) GetMany(keys []string, vals any) error {
+	return mn.GetManyCtx(context.Background(), keys, vals)
+}
+
+func (mn *mockedNode) GetManyCtx(ctx context.Context, keys []string, vals any) error {
+	m, err := newValueMap(vals)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		v := m.newValue()
+		if err = mn.GetCtx(ctx, key, v); err == nil {
+			m.set(key, v)
+		}
+	}
+
+	return nil
+}
+
 func (mn *mockedNode) IsNotFound(err error) bool {
 	return errors.Is(err, mn.errNotFound)
 }
@@ -97,6 +117,43 @@ func (mn *mockedNode) TakeCtx(ctx context.Context, val any, key string, query fu
 	return mn.SetCtx(ctx, key, val)
 }
 
+func (mn *mockedNode) TakeMany(vals any, keys []string, query ManyQueryFn) error {
+	return mn.TakeManyCtx(context.Background(), vals, keys, query)
+}
+
+func (mn *mockedNode) TakeManyCtx(ctx context.Context, vals any, keys []string, query ManyQueryFn) error {
+	m, err := newValueMap(vals)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, key := range keys {
+		v := m.newValue()
+		if err = mn.GetCtx(ctx, key, v); err == nil {
+			m.set(key, v)
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	values, _, err := queryMany(m, missing, query)
+	if err != nil {
+		return err
+	}
+
+	for key, val := range values {
+		if err = mn.SetCtx(ctx, key, val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (mn *mockedNode) TakeWithExpire(val any, key string, query func(val any, expire time.Duration) error) error {
 	return mn.TakeWithExpireCtx(context.Background(), val, key, query)
 }
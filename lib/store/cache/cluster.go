@@ -9,11 +9,25 @@ import (
 	"time"
 )
 
-// cluster 是一个支持一致性哈希分发的缓存集群
-type cluster struct {
-	dispatcher  *hash.ConsistentHash
-	errNotFound error
-}
+type (
+	// cluster 是一个支持一致性哈希分发的缓存集群
+	cluster struct {
+		dispatcher  *hash.ConsistentHash
+		errNotFound error
+		codec       Codec
+	}
+
+	// nodeKeys 是分发至同一节点的键。
+	nodeKeys struct {
+		node Cache
+		keys []string
+	}
+
+	nodeGroups struct {
+		groups []nodeKeys
+		index  map[any]int
+	}
+)
 
 // Del 删除给定 keys 的缓存。
 func (c cluster) Del(keys ...string) error {
@@ -35,7 +49,7 @@ func (c cluster) DelCtx(ctx context.Context, keys ...string) error {
 		return n.(Cache).DelCtx(ctx, key)
 	default:
 		var be errorx.BatchError
-		nodes := make(map[any][]string)
+		var groups nodeGroups
 		for _, key := range keys {
 			n, ok := c.dispatcher.Get(key)
 			if !ok {
@@ -43,10 +57,10 @@ func (c cluster) DelCtx(ctx context.Context, keys ...string) error {
 				continue
 			}
 
-			nodes[n] = append(nodes[n], key)
+			groups.add(n.(Cache), key)
 		}
-		for n, ks := range nodes {
-			if err := n.(Cache).DelCtx(ctx, ks...); err != nil {
+		for _, g := range groups.groups {
+			if err := g.node.DelCtx(ctx, g.keys...); err != nil {
 				be.Add(err)
 			}
 		}
@@ -70,6 +84,31 @@ func (c cluster) GetCtx(ctx context.Context, key string, val any) error {
 	return n.(Cache).GetCtx(ctx, key, val)
 }
 
+// GetMany 批量获取给定 keys 的缓存并填充至 vals。
+func (c cluster) GetMany(keys []string, vals any) error {
+	return c.GetManyCtx(context.Background(), keys, vals)
+}
+
+// GetManyCtx 按节点分组批量获取给定 keys 的缓存并填充至 vals（*map[string]T），仅填充命中的键。
+func (c cluster) GetManyCtx(ctx context.Context, keys []string, vals any) error {
+	if _, err := newValueMap(vals); err != nil {
+		return err
+	}
+
+	groups, err := c.groupKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		if err = g.node.GetManyCtx(ctx, g.keys, vals); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // IsNotFound 判断给定错误是否为预定义的未找到错误。
 func (c cluster) IsNotFound(err error) bool {
 	return errors.Is(err, c.errNotFound)
@@ -120,6 +159,73 @@ func (c cluster) TakeCtx(ctx context.Context, val any, key string, query func(va
 	return n.(Cache).TakeCtx(ctx, val, key, query)
 }
 
+// TakeMany 首先从缓存中批量获取结果，未命中的键以 query 一次批量查询并设置缓存，然后将结果填充至 vals。
+func (c cluster) TakeMany(vals any, keys []string, query ManyQueryFn) error {
+	return c.TakeManyCtx(context.Background(), vals, keys, query)
+}
+
+// TakeManyCtx 按节点分组批量获取缓存，汇总各节点未命中的键以 query 一次批量查询，
+// 再按节点分组写入缓存，然后将结果填充至 vals（*map[string]T）。
+func (c cluster) TakeManyCtx(ctx context.Context, vals any, keys []string, query ManyQueryFn) error {
+	m, err := newValueMap(vals)
+	if err != nil {
+		return err
+	}
+
+	groups, err := c.groupKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	owners := make(map[string]int)
+	for i, g := range groups {
+		bn, ok := g.node.(batchNode)
+		if !ok {
+			// 不支持批量读写的节点，各自批量查询。
+			if err = g.node.TakeManyCtx(ctx, vals, g.keys, query); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ms, err := bn.getMany(ctx, g.keys, m)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range ms {
+			owners[key] = i
+		}
+		missing = append(missing, ms...)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// 各节点共享同一 singleflight 及统计，由任一节点加载即可，再按节点分组写入缓存。
+	loader := groups[owners[missing[0]]].node.(batchNode)
+	return loader.loadMany(m, missing, query, func(values map[string]any, notFound []string, delta time.Duration) {
+		nodeValues := make([]map[string]any, len(groups))
+		for key, val := range values {
+			i := owners[key]
+			if nodeValues[i] == nil {
+				nodeValues[i] = make(map[string]any)
+			}
+			nodeValues[i][key] = val
+		}
+		nodeNotFound := make([][]string, len(groups))
+		for _, key := range notFound {
+			nodeNotFound[owners[key]] = append(nodeNotFound[owners[key]], key)
+		}
+		for i, g := range groups {
+			if bn, ok := g.node.(batchNode); ok {
+				bn.setMany(ctx, nodeValues[i], nodeNotFound[i], delta)
+			}
+		}
+	})
+}
+
 // TakeWithExpire 首先从缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
 func (c cluster) TakeWithExpire(val any, key string, query func(val any, expire time.Duration) error) error {
 	return c.TakeWithExpireCtx(context.Background(), val, key, query)
@@ -134,3 +240,53 @@ func (c cluster) TakeWithExpireCtx(ctx context.Context, val any, key string, que
 
 	return n.(Cache).TakeWithExpireCtx(ctx, val, key, query)
 }
+
+// valueCodec 返回编解码缓存值的 Codec，未设置时使用 JsonCodec。
+func (c cluster) valueCodec() Codec {
+	if c.codec == nil {
+		return JsonCodec{}
+	}
+
+	return c.codec
+}
+
+// groupKeys 将 keys 按一致性哈希分发至的节点分组，分组顺序为节点首次出现的顺序。
+func (c cluster) groupKeys(keys []string) ([]nodeKeys, error) {
+	var groups nodeGroups
+	for _, key := range keys {
+		n, ok := c.dispatcher.Get(key)
+		if !ok {
+			return nil, c.errNotFound
+		}
+
+		groups.add(n.(Cache), key)
+	}
+
+	return groups.groups, nil
+}
+
+// add 将 key 加入节点 n 的分组。
+// node 含编解码器等可能不可比较的字段，不能直接作为 map 的键，故以节点标识分组。
+func (g *nodeGroups) add(n Cache, key string) {
+	id := nodeIdentity(n)
+	i, ok := g.index[id]
+	if !ok {
+		if g.index == nil {
+			g.index = make(map[any]int)
+		}
+		i = len(g.groups)
+		g.index[id] = i
+		g.groups = append(g.groups, nodeKeys{node: n})
+	}
+
+	g.groups[i].keys = append(g.groups[i].keys, key)
+}
+
+// nodeIdentity 返回可用作 map 键的节点标识，node 以其 redis 客户端标识。
+func nodeIdentity(n Cache) any {
+	if nd, ok := n.(node); ok {
+		return nd.rds
+	}
+
+	return n
+}
@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/codec"
+	"github.com/gotid/god/lib/jsonx"
+	"google.golang.org/protobuf/proto"
+	"sync"
+)
+
+const (
+	// JsonCodecId 是 JsonCodec 的编号。
+	JsonCodecId byte = 1
+	// ProtoCodecId 是 ProtoCodec 的编号。
+	ProtoCodecId byte = 2
+	// GobCodecId 是 GobCodec 的编号。
+	GobCodecId byte = 3
+
+	// 1 ~ maxReservedCodecId 为内置编解码器保留。
+	maxReservedCodecId byte = 15
+
+	// 带格式头的缓存值以版本字节开头，其后依次为编解码器编号及压缩方式。
+	// 版本字节不是可打印字符，不会与无格式头的 JSON 缓存值混淆。
+	formatVersion    byte = 0x01
+	formatHeaderSize      = 3
+
+	compressNone byte = 0
+	compressGzip byte = 1
+)
+
+var (
+	// ErrNotProtoMessage 表示 ProtoCodec 编解码的值不是 proto.Message。
+	ErrNotProtoMessage = errors.New("值不是 proto.Message")
+	// ErrReservedCodecId 表示自定义编解码器使用了内置编解码器保留的编号。
+	ErrReservedCodecId = errors.New("编解码器编号 1 ~ 15 为内置编解码器保留")
+	// ErrUnregisteredCodec 表示自定义编解码器未以 RegisterCodec 注册。
+	ErrUnregisteredCodec = errors.New("编解码器未注册")
+
+	errInvalidFormat = errors.New("缓存值格式无效")
+
+	codecs = map[byte]Codec{
+		JsonCodecId:  JsonCodec{},
+		ProtoCodecId: ProtoCodec{},
+		GobCodecId:   GobCodec{},
+	}
+	codecsLock sync.RWMutex
+)
+
+type (
+	// Codec 是缓存值的编解码器。
+	Codec interface {
+		// Id 返回编解码器的编号，写入缓存值的格式头，读取时据此选择编解码器。
+		// 1 ~ 15 为内置编解码器保留。
+		Id() byte
+		Marshal(v any) ([]byte, error)
+		Unmarshal(data []byte, v any) error
+	}
+
+	// JsonCodec 以 JSON 编解码，为默认编解码器。
+	JsonCodec struct{}
+
+	// ProtoCodec 以 protobuf 编解码，值须为 proto.Message。
+	ProtoCodec struct{}
+
+	// GobCodec 以 encoding/gob 二进制编解码，适用于较大的结构体。
+	GobCodec struct{}
+)
+
+// RegisterCodec 注册自定义编解码器，以便读取由其写入的缓存值，编号不可为 1 ~ 15。
+// 须在以 WithCodec 使用前注册，通常在 init 中调用。
+// 迁移编解码器时，须保持旧编解码器注册直至旧缓存值过期。
+func RegisterCodec(c Codec) error {
+	id := c.Id()
+	if id > 0 && id <= maxReservedCodecId {
+		return fmt.Errorf("%w：%d", ErrReservedCodecId, id)
+	}
+
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	codecs[id] = c
+	return nil
+}
+
+// checkCodec 检查自定义编解码器已注册，以免写入的缓存值无法读取。
+func checkCodec(c Codec) error {
+	if isBuiltinCodec(c) {
+		return nil
+	}
+
+	id := c.Id()
+	if id > 0 && id <= maxReservedCodecId {
+		return fmt.Errorf("%w：%d", ErrReservedCodecId, id)
+	}
+	if _, ok := getCodec(id); !ok {
+		return fmt.Errorf("%w：%d", ErrUnregisteredCodec, id)
+	}
+
+	return nil
+}
+
+func isBuiltinCodec(c Codec) bool {
+	switch c.(type) {
+	case JsonCodec, *JsonCodec, ProtoCodec, *ProtoCodec, GobCodec, *GobCodec:
+		return true
+	default:
+		return false
+	}
+}
+
+func getCodec(id byte) (Codec, bool) {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	c, ok := codecs[id]
+	return c, ok
+}
+
+// Id 返回编解码器的编号。
+func (c JsonCodec) Id() byte {
+	return JsonCodecId
+}
+
+// Marshal 编码 v。
+func (c JsonCodec) Marshal(v any) ([]byte, error) {
+	return jsonx.Marshal(v)
+}
+
+// Unmarshal 解码 data 至 v。
+func (c JsonCodec) Unmarshal(data []byte, v any) error {
+	return jsonx.Unmarshal(data, v)
+}
+
+// Id 返回编解码器的编号。
+func (c ProtoCodec) Id() byte {
+	return ProtoCodecId
+}
+
+// Marshal 编码 v。
+func (c ProtoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+
+	return proto.Marshal(m)
+}
+
+// Unmarshal 解码 data 至 v。
+func (c ProtoCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+
+	return proto.Unmarshal(data, m)
+}
+
+// Id 返回编解码器的编号。
+func (c GobCodec) Id() byte {
+	return GobCodecId
+}
+
+// Marshal 编码 v。
+func (c GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 解码 data 至 v。
+func (c GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// encodeValue 编码缓存值，数据长度不小于 threshold（大于 0 时）则以 gzip 压缩。
+// 使用 JsonCodec 且未压缩时不写格式头，与旧版本缓存值保持兼容。
+func encodeValue(c Codec, threshold int, v any) ([]byte, error) {
+	if err := checkCodec(c); err != nil {
+		return nil, err
+	}
+
+	data, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	compress := compressNone
+	if threshold > 0 && len(data) >= threshold {
+		data = codec.Gzip(data)
+		compress = compressGzip
+	}
+	if c.Id() == JsonCodecId && compress == compressNone {
+		return data, nil
+	}
+
+	buf := make([]byte, 0, formatHeaderSize+len(data))
+	buf = append(buf, formatVersion, c.Id(), compress)
+	return append(buf, data...), nil
+}
+
+// decodeValue 解码缓存值，按格式头选择编解码器及解压方式，无格式头时以 JSON 解码。
+func decodeValue(data []byte, v any) error {
+	if len(data) == 0 || data[0] != formatVersion {
+		return jsonx.Unmarshal(data, v)
+	}
+	if len(data) < formatHeaderSize {
+		return errInvalidFormat
+	}
+
+	c, ok := getCodec(data[1])
+	if !ok {
+		return fmt.Errorf("未注册编号为 %d 的编解码器", data[1])
+	}
+
+	payload := data[formatHeaderSize:]
+	switch data[2] {
+	case compressNone:
+	case compressGzip:
+		var err error
+		if payload, err = codec.Gunzip(payload); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("不支持的压缩方式 %d", data[2])
+	}
+
+	return c.Unmarshal(payload, v)
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gotid/god/lib/store/redis"
+	"github.com/gotid/god/lib/syncx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"strings"
+	"testing"
+)
+
+type (
+	codecUser struct {
+		Name string
+		Age  int
+	}
+
+	upperCodec struct{}
+
+	// lazyCodec 仅用于验证未注册的编解码器不可写入。
+	lazyCodec struct {
+		upperCodec
+	}
+
+	reservedCodec struct {
+		upperCodec
+	}
+)
+
+func (c lazyCodec) Id() byte {
+	return 17
+}
+
+func (c reservedCodec) Id() byte {
+	return 15
+}
+
+func (c upperCodec) Id() byte {
+	return 16
+}
+
+func (c upperCodec) Marshal(v any) ([]byte, error) {
+	return []byte(strings.ToUpper(v.(string))), nil
+}
+
+func (c upperCodec) Unmarshal(data []byte, v any) error {
+	*v.(*string) = string(data)
+	return nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	assert.True(t, errors.Is(RegisterCodec(JsonCodec{}), ErrReservedCodecId))
+	assert.True(t, errors.Is(RegisterCodec(reservedCodec{}), ErrReservedCodecId))
+	c, ok := getCodec(JsonCodecId)
+	assert.True(t, ok)
+	assert.Equal(t, JsonCodec{}, c)
+
+	// WithCodec 不注册编解码器，未注册或使用保留编号的编解码器写入时返回错误。
+	o := newOptions(WithCodec(lazyCodec{}))
+	assert.Equal(t, lazyCodec{}, o.Codec)
+	_, ok = getCodec(17)
+	assert.False(t, ok)
+	_, err := encodeValue(lazyCodec{}, 0, "kevin")
+	assert.True(t, errors.Is(err, ErrUnregisteredCodec))
+	_, err = encodeValue(reservedCodec{}, 0, "kevin")
+	assert.True(t, errors.Is(err, ErrReservedCodecId))
+
+	assert.Nil(t, RegisterCodec(lazyCodec{}))
+	c, ok = getCodec(17)
+	assert.True(t, ok)
+	assert.Equal(t, lazyCodec{}, c)
+	data, err := encodeValue(lazyCodec{}, 0, "kevin")
+	assert.Nil(t, err)
+	var val string
+	assert.Nil(t, decodeValue(data, &val))
+	assert.Equal(t, "KEVIN", val)
+}
+
+func TestEncodeValue(t *testing.T) {
+	user := codecUser{Name: "kevin", Age: 18}
+
+	data, err := encodeValue(JsonCodec{}, 0, &user)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Name":"kevin","Age":18}`, string(data))
+
+	for _, c := range []Codec{JsonCodec{}, GobCodec{}} {
+		for _, size := range []int{0, 1} {
+			data, err = encodeValue(c, size, &user)
+			assert.Nil(t, err)
+
+			var decoded codecUser
+			assert.Nil(t, decodeValue(data, &decoded))
+			assert.Equal(t, user, decoded)
+		}
+	}
+
+	data, err = encodeValue(JsonCodec{}, 1, &user)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{formatVersion, JsonCodecId, compressGzip}, data[:formatHeaderSize])
+}
+
+func TestProtoCodec(t *testing.T) {
+	data, err := encodeValue(ProtoCodec{}, 0, wrapperspb.String("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, ProtoCodecId, data[1])
+
+	var val wrapperspb.StringValue
+	assert.Nil(t, decodeValue(data, &val))
+	assert.Equal(t, "hello", val.Value)
+
+	_, err = ProtoCodec{}.Marshal("hello")
+	assert.Equal(t, ErrNotProtoMessage, err)
+	assert.Equal(t, ErrNotProtoMessage, ProtoCodec{}.Unmarshal(nil, new(string)))
+}
+
+func TestDecodeValueErrors(t *testing.T) {
+	var val string
+	assert.Equal(t, errInvalidFormat, decodeValue([]byte{formatVersion, JsonCodecId}, &val))
+	assert.NotNil(t, decodeValue([]byte{formatVersion, 99, compressNone}, &val))
+	assert.NotNil(t, decodeValue([]byte{formatVersion, JsonCodecId, 9}, &val))
+	assert.NotNil(t, decodeValue([]byte{formatVersion, JsonCodecId, compressGzip, 1}, &val))
+}
+
+func TestNodeWithCodec(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	rds := redis.New(r.Addr())
+	c := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound,
+		WithCodec(GobCodec{}), WithCompression(16))
+
+	var user codecUser
+	assert.Nil(t, c.Take(&user, "user", func(val any) error {
+		*val.(*codecUser) = codecUser{Name: strings.Repeat("kevin", 10), Age: 18}
+		return nil
+	}))
+	data, err := r.Get("user")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{formatVersion, GobCodecId, compressGzip}, []byte(data[:formatHeaderSize]))
+
+	var cached codecUser
+	assert.Nil(t, c.Get("user", &cached))
+	assert.Equal(t, user, cached)
+
+	// 旧的 JSON 缓存值仍可读取。
+	assert.Nil(t, r.Set("legacy", `{"Name":"kevin","Age":18}`))
+	assert.Nil(t, c.Get("legacy", &cached))
+	assert.Equal(t, codecUser{Name: "kevin", Age: 18}, cached)
+
+	// 自定义编解码器写入的值，可被其他编解码器的节点读取。
+	custom := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound, WithCodec(upperCodec{}))
+	assert.True(t, errors.Is(custom.Set("name", "kevin"), ErrUnregisteredCodec))
+	assert.Nil(t, RegisterCodec(upperCodec{}))
+	assert.Nil(t, custom.Set("name", "kevin"))
+	var name string
+	assert.Nil(t, c.Get("name", &name))
+	assert.Equal(t, "KEVIN", name)
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"github.com/gotid/god/lib/contextx"
+	"github.com/gotid/god/lib/logx"
+	"github.com/gotid/god/lib/stringx"
+	"github.com/gotid/god/lib/threading"
+	"github.com/gotid/god/lib/timex"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 带元数据的缓存项前缀，格式为 ~<逻辑过期时间毫秒>:<查询耗时毫秒>:<值>。
+	// JSON 值不会以 ~ 开头，故可与普通缓存项共存。
+	entryPrefix        = "~"
+	refreshLockSuffix  = ":refresh"
+	refreshLockSeconds = 10
+	refreshOwnerLen    = 16
+	// 仅当锁仍由 ARGV[1] 持有时删除。
+	refreshUnlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+else
+    return 0
+end`
+)
+
+// entryMeta 是缓存项的元数据，expireAt 为 0 表示普通缓存项。
+type entryMeta struct {
+	expireAt int64
+	delta    int64
+}
+
+// stale 判断缓存项是否已过期，仅在旧值返回窗口内可读到已过期的缓存项。
+func (m entryMeta) stale() bool {
+	return m.expireAt > 0 && time.Now().UnixMilli() >= m.expireAt
+}
+
+// setCache 设置缓存项，开启旧值返回或提前过期时附带元数据，delta 为查询耗时。
+func (n node) setCache(ctx context.Context, key string, val any, expire, delta time.Duration) error {
+	value, expire, err := n.cacheValue(val, expire, delta)
+	if err != nil {
+		return err
+	}
+
+	return n.rds.SetExCtx(ctx, key, value, int(expire/time.Second))
+}
+
+// cacheValue 返回待写入的缓存项及 redis 中的存活时长（向上取整至秒）。
+func (n node) cacheValue(val any, expire, delta time.Duration) (string, time.Duration, error) {
+	data, err := encodeValue(n.valueCodec(), n.compressSize, val)
+	if err != nil {
+		return "", 0, err
+	}
+
+	value := string(data)
+	if n.withMeta() {
+		value = encodeEntry(value, entryMeta{
+			expireAt: time.Now().Add(expire).UnixMilli(),
+			delta:    delta.Milliseconds(),
+		})
+		expire += n.staleWindow
+	}
+
+	return value, time.Duration(math.Ceil(expire.Seconds())) * time.Second, nil
+}
+
+// revalidate 在缓存项已过期（旧值）或概率提前过期时，调用者立即沿用当前值返回，
+// 由获得刷新锁的单个调用者（跨实例）在后台查询数据库刷新缓存。
+func (n node) revalidate(ctx context.Context, key string, val any, meta entryMeta,
+	query func(val any) error,
+	cacheVal func(ctx context.Context, val any, delta time.Duration) error) {
+	if meta.expireAt == 0 {
+		return
+	}
+
+	stale := meta.stale()
+	if !stale && !n.expireEarly(meta) {
+		return
+	}
+	if stale {
+		n.stat.IncrStale()
+	}
+
+	lockKey := key + refreshLockSuffix
+	owner := stringx.Randn(refreshOwnerLen)
+	ok, err := n.rds.SetNXExCtx(ctx, lockKey, owner, refreshLockSeconds)
+	if err != nil || !ok {
+		return
+	}
+
+	// 刷新不受调用者的超时及取消控制，但保留上下文中的链路信息。
+	ctx = contextx.ValueOnlyFrom(ctx)
+	typ := reflect.TypeOf(val).Elem()
+	threading.GoSafe(func() {
+		n.refresh(ctx, key, reflect.New(typ).Interface(), query, cacheVal)
+		// 仅释放自己持有的锁，以免锁过期后误删其他实例的锁。
+		if _, err := n.rds.EvalCtx(ctx, refreshUnlockScript, []string{lockKey}, owner); err != nil {
+			logx.WithContext(ctx).Errorf("释放缓存刷新锁失败，键：%s，错误：%v", lockKey, err)
+		}
+	})
+}
+
+// refresh 查询数据库并刷新缓存，数据已不存在时写入占位符，查询失败时保留当前缓存项。
+func (n node) refresh(ctx context.Context, key string, val any,
+	query func(val any) error,
+	cacheVal func(ctx context.Context, val any, delta time.Duration) error) {
+	logger := logx.WithContext(ctx)
+	start := timex.Now()
+	if err := query(val); err == n.errNotFound {
+		if err = n.setCacheWithNotFound(ctx, key); err != nil {
+			logger.Error(err)
+		}
+		return
+	} else if err != nil {
+		n.stat.IncrDbFails()
+		logger.Errorf("刷新缓存失败，保留当前值，键：%s，错误：%v", key, err)
+		return
+	}
+
+	n.stat.IncrRefresh()
+	if err := cacheVal(ctx, val, timex.Since(start)); err != nil {
+		logger.Error(err)
+	}
+}
+
+// expireEarly 按 XFetch 算法判断是否提前过期：now - delta * beta * ln(rand) >= expireAt。
+func (n node) expireEarly(meta entryMeta) bool {
+	if n.xfetchBeta <= 0 || meta.delta <= 0 {
+		return false
+	}
+
+	n.lock.Lock()
+	// 取值范围 (0, 1]，避免 ln(0)。
+	r := 1 - n.r.Float64()
+	n.lock.Unlock()
+
+	gap := -float64(meta.delta) * n.xfetchBeta * math.Log(r)
+	return float64(time.Now().UnixMilli())+gap >= float64(meta.expireAt)
+}
+
+func (n node) withMeta() bool {
+	return n.staleWindow > 0 || n.xfetchBeta > 0
+}
+
+func encodeEntry(data string, meta entryMeta) string {
+	return entryPrefix + strconv.FormatInt(meta.expireAt, 10) + ":" +
+		strconv.FormatInt(meta.delta, 10) + ":" + data
+}
+
+// decodeEntry 解析缓存项的元数据，普通缓存项原样返回。
+func decodeEntry(data string) (entryMeta, string) {
+	if !strings.HasPrefix(data, entryPrefix) {
+		return entryMeta{}, data
+	}
+
+	fields := strings.SplitN(data[len(entryPrefix):], ":", 3)
+	if len(fields) != 3 {
+		return entryMeta{}, data
+	}
+
+	expireAt, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return entryMeta{}, data
+	}
+	delta, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return entryMeta{}, data
+	}
+
+	return entryMeta{
+		expireAt: expireAt,
+		delta:    delta,
+	}, fields[2]
+}
@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gotid/god/lib/store/redis"
+	"github.com/gotid/god/lib/syncx"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNode_StaleWhileRevalidate(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	st := NewStat("any")
+	c := NewNode(redis.New(r.Addr()), syncx.NewSingleFlight(), st, errTestNotFound,
+		WithExpire(time.Millisecond*100), WithStaleWhileRevalidate(time.Minute))
+
+	var queries int
+	query := func(val any) error {
+		queries++
+		*val.(*int) = queries
+		return nil
+	}
+
+	var val int
+	assert.Nil(t, c.Take(&val, "key", query))
+	assert.Equal(t, 1, val)
+	ttl := r.TTL("key")
+	assert.True(t, ttl > time.Minute)
+
+	time.Sleep(time.Millisecond * 150)
+	// 其他调用者持有刷新锁时，返回旧值且不刷新。
+	assert.Nil(t, r.Set("key"+refreshLockSuffix, "other"))
+	assert.Nil(t, c.Take(&val, "key", query))
+	assert.Equal(t, 1, val)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&st.Stale))
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, 1, queries)
+
+	// 获得刷新锁的调用者立即返回旧值，并在后台刷新缓存。
+	r.Del("key" + refreshLockSuffix)
+	assert.Nil(t, c.Take(&val, "key", query))
+	assert.Equal(t, 1, val)
+	waitFor(t, func() bool {
+		return atomic.LoadUint64(&st.Refresh) == 1 && !r.Exists("key"+refreshLockSuffix)
+	})
+
+	val = 0
+	assert.Nil(t, c.Take(&val, "key", query))
+	assert.Equal(t, 2, val)
+	assert.Equal(t, 2, queries)
+}
+
+func TestNode_TakeWithExpireStale(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNode(redis.New(r.Addr()), syncx.NewSingleFlight(), NewStat("any"), errTestNotFound,
+		WithExpire(time.Millisecond*100), WithStaleWhileRevalidate(time.Minute))
+
+	var queries int
+	query := func(val any, _ time.Duration) error {
+		queries++
+		*val.(*int) = queries
+		return nil
+	}
+
+	var val int
+	assert.Nil(t, c.TakeWithExpire(&val, "key", query))
+	assert.Equal(t, 1, val)
+	assert.Nil(t, c.TakeWithExpire(&val, "key", query))
+	assert.Equal(t, 1, queries)
+
+	// 不在后台刷新，已过期的旧值视为未命中并同步查询。
+	time.Sleep(time.Millisecond * 150)
+	assert.Nil(t, c.TakeWithExpire(&val, "key", query))
+	assert.Equal(t, 2, val)
+	assert.False(t, r.Exists("key"+refreshLockSuffix))
+}
+
+func TestNode_RevalidateKeepsOthersLock(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNode(redis.New(r.Addr()), syncx.NewSingleFlight(), NewStat("any"), errTestNotFound,
+		WithExpire(time.Millisecond*100), WithStaleWhileRevalidate(time.Minute))
+
+	var val int
+	assert.Nil(t, c.Take(&val, "key", func(val any) error {
+		*val.(*int) = 1
+		return nil
+	}))
+	time.Sleep(time.Millisecond * 150)
+
+	done := make(chan struct{})
+	assert.Nil(t, c.Take(&val, "key", func(val any) error {
+		// 模拟刷新期间锁过期并被其他实例获取。
+		r.Set("key"+refreshLockSuffix, "other")
+		*val.(*int) = 2
+		close(done)
+		return nil
+	}))
+	assert.Equal(t, 1, val)
+	<-done
+	time.Sleep(time.Millisecond * 50)
+	owner, err := r.Get("key" + refreshLockSuffix)
+	assert.Nil(t, err)
+	assert.Equal(t, "other", owner)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待条件超时")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestNode_StaleWhileRevalidateFailures(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNode(redis.New(r.Addr()), syncx.NewSingleFlight(), NewStat("any"), errTestNotFound,
+		WithExpire(time.Millisecond*100), WithStaleWhileRevalidate(time.Minute))
+
+	var val string
+	assert.Nil(t, c.Take(&val, "key", func(val any) error {
+		*val.(*string) = "value"
+		return nil
+	}))
+	time.Sleep(time.Millisecond * 150)
+
+	// 刷新失败时返回旧值，并保留当前缓存项。
+	assert.Nil(t, c.Take(&val, "key", func(val any) error {
+		*val.(*string) = "broken"
+		return errors.New("db down")
+	}))
+	assert.Equal(t, "value", val)
+	waitFor(t, func() bool {
+		return !r.Exists("key" + refreshLockSuffix)
+	})
+	assert.Nil(t, c.Get("key", &val))
+	assert.Equal(t, "value", val)
+
+	// 数据已删除时，本次返回旧值，刷新后返回未找到。
+	assert.Nil(t, c.Take(&val, "key", func(val any) error {
+		return errTestNotFound
+	}))
+	waitFor(t, func() bool {
+		return c.IsNotFound(c.Get("key", &val))
+	})
+}
+
+func TestNode_TakeWithExpireMeta(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNode(redis.New(r.Addr()), syncx.NewSingleFlight(), NewStat("any"), errTestNotFound,
+		WithXFetch(1))
+
+	var val string
+	assert.Nil(t, c.TakeWithExpire(&val, "key", func(val any, expire time.Duration) error {
+		time.Sleep(time.Millisecond * 5)
+		*val.(*string) = "value"
+		return nil
+	}))
+	data, err := r.Get("key")
+	assert.Nil(t, err)
+	meta, payload := decodeEntry(data)
+	assert.True(t, meta.expireAt > time.Now().UnixMilli())
+	assert.True(t, meta.delta >= 5)
+	assert.Equal(t, `"value"`, payload)
+
+	assert.Nil(t, c.Set("other", "any"))
+	assert.Nil(t, c.Get("other", &val))
+	assert.Equal(t, "any", val)
+}
+
+func TestNode_ExpireEarly(t *testing.T) {
+	n := node{
+		r:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		lock:       new(sync.Mutex),
+		xfetchBeta: 1,
+	}
+	now := time.Now().UnixMilli()
+	assert.False(t, n.expireEarly(entryMeta{expireAt: now + time.Hour.Milliseconds()}))
+	assert.False(t, n.expireEarly(entryMeta{expireAt: now + time.Hour.Milliseconds(), delta: 1}))
+	assert.True(t, n.expireEarly(entryMeta{expireAt: now - 1, delta: 1}))
+
+	var early int
+	for i := 0; i < 1000; i++ {
+		if n.expireEarly(entryMeta{expireAt: now + 100, delta: 100}) {
+			early++
+		}
+	}
+	// P(-ln(r) >= 1) = 1/e
+	assert.True(t, early > 200 && early < 600)
+
+	n.xfetchBeta = 0
+	assert.False(t, n.expireEarly(entryMeta{expireAt: now - 1, delta: 1}))
+}
+
+func TestDecodeEntry(t *testing.T) {
+	tests := []struct {
+		data   string
+		meta   entryMeta
+		expect string
+	}{
+		{data: `"value"`, expect: `"value"`},
+		{data: `~1:2:"a:b"`, meta: entryMeta{expireAt: 1, delta: 2}, expect: `"a:b"`},
+		{data: `~1:2`, expect: `~1:2`},
+		{data: `~a:2:1`, expect: `~a:2:1`},
+		{data: `~1:b:1`, expect: `~1:b:1`},
+	}
+	for _, test := range tests {
+		meta, data := decodeEntry(test.data)
+		assert.Equal(t, test.meta, meta)
+		assert.Equal(t, test.expect, data)
+	}
+
+	assert.Equal(t, `~1:2:"a"`, encodeEntry(`"a"`, entryMeta{expireAt: 1, delta: 2}))
+}
@@ -0,0 +1,298 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/logx"
+	"github.com/gotid/god/lib/store/redis"
+	"github.com/gotid/god/lib/timex"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// 批量查询在 singleflight 中的键前缀及分隔符，使用 NUL 以免与单键 Take 的键冲突。
+	manyFlightPrefix    = "many\x00"
+	manyFlightSeparator = "\x00"
+)
+
+// ErrInvalidValues 表示批量结果的类型不是 *map[string]T。
+var ErrInvalidValues = errors.New("批量结果须为 *map[string]T")
+
+type (
+	// ManyQueryFn 批量查询未缓存的键，返回查询到的键值，值为 T 或 *T，未返回的键视为不存在。
+	ManyQueryFn func(missing []string) (map[string]any, error)
+
+	// batchNode 是支持批量读写的缓存节点。
+	batchNode interface {
+		getMany(ctx context.Context, keys []string, vals valueMap) ([]string, error)
+		loadMany(vals valueMap, missing []string, query ManyQueryFn, save saveManyFn) error
+		setMany(ctx context.Context, values map[string]any, notFound []string, delta time.Duration)
+	}
+
+	// saveManyFn 将批量查询到的键值及不存在的键写入缓存，delta 为查询耗时。
+	saveManyFn func(values map[string]any, notFound []string, delta time.Duration)
+
+	// valueMap 包装 *map[string]T 形式的批量结果。
+	valueMap struct {
+		m    reflect.Value
+		elem reflect.Type
+	}
+)
+
+// GetMany 批量获取给定 keys 的缓存并填充至 vals，参见 GetManyCtx。
+func (n node) GetMany(keys []string, vals any) error {
+	return n.GetManyCtx(context.Background(), keys, vals)
+}
+
+// GetManyCtx 批量获取给定 keys 的缓存并填充至 vals（*map[string]T），仅填充命中的键。
+func (n node) GetManyCtx(ctx context.Context, keys []string, vals any) error {
+	m, err := newValueMap(vals)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.getMany(ctx, keys, m)
+	return err
+}
+
+// TakeMany 批量获取缓存，未缓存的键以 query 一次批量查询并写入缓存，参见 TakeManyCtx。
+func (n node) TakeMany(vals any, keys []string, query ManyQueryFn) error {
+	return n.TakeManyCtx(context.Background(), vals, keys, query)
+}
+
+// TakeManyCtx 批量获取给定 keys 的缓存并填充至 vals（*map[string]T），
+// 未缓存的键以 query 一次批量查询并写入缓存，不存在的键将缓存未找到占位符且不填充至 vals。
+func (n node) TakeManyCtx(ctx context.Context, vals any, keys []string, query ManyQueryFn) error {
+	m, err := newValueMap(vals)
+	if err != nil {
+		return err
+	}
+
+	missing, err := n.getMany(ctx, keys, m)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	return n.loadMany(m, missing, query, func(values map[string]any, notFound []string, delta time.Duration) {
+		n.setMany(ctx, values, notFound, delta)
+	})
+}
+
+// getMany 批量读取缓存至 vals，返回未缓存的键，已缓存为未找到占位符的键不在其中。
+func (n node) getMany(ctx context.Context, keys []string, vals valueMap) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := n.rawGetMany(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for i, key := range keys {
+		n.stat.IncrTotal()
+		data := values[i]
+		if len(data) == 0 {
+			n.stat.IncrMiss()
+			missing = append(missing, key)
+			continue
+		}
+
+		n.stat.IncrHit()
+		if data == notFoundPlaceholder {
+			continue
+		}
+
+		_, data = decodeEntry(data)
+		v := vals.newValue()
+		if err = n.processCache(ctx, key, data, v); err != nil {
+			missing = append(missing, key)
+			continue
+		}
+		vals.set(key, v)
+	}
+
+	return missing, nil
+}
+
+// rawGetMany 单节点使用 MGET，集群的键可能位于不同槽位，使用管道逐个 GET。
+func (n node) rawGetMany(ctx context.Context, keys []string) ([]string, error) {
+	if n.rds.Type != redis.ClusterType {
+		return n.rds.MGetCtx(ctx, keys...)
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	err := n.rds.PipelinedCtx(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	values := make([]string, len(keys))
+	for i, cmd := range cmds {
+		values[i] = cmd.Val()
+	}
+
+	return values, nil
+}
+
+// setMany 以管道批量写入缓存，失败时仅记录日志。
+func (n node) setMany(ctx context.Context, values map[string]any, notFound []string, delta time.Duration) {
+	if len(values) == 0 && len(notFound) == 0 {
+		return
+	}
+
+	err := n.rds.PipelinedCtx(ctx, func(pipe redis.Pipeliner) error {
+		for key, val := range values {
+			value, expire, err := n.cacheValue(val, n.aroundDuration(n.expire), delta)
+			if err != nil {
+				return err
+			}
+			pipe.SetEX(ctx, key, value, expire)
+		}
+		for _, key := range notFound {
+			pipe.SetEX(ctx, key, notFoundPlaceholder, n.aroundDuration(n.notFoundExpire))
+		}
+		return nil
+	})
+	if err != nil {
+		logx.WithContext(ctx).Errorf("批量写入缓存失败，节点：%s，错误：%v", n.rds.Addr, err)
+	}
+}
+
+// loadMany 以 query 批量查询未缓存的 missing 键，经 save 写入缓存后填充至 vals。
+// 键集相同的并发调用共享同一次数据库查询，共享的结果经编解码器复制，以免调用者共用同一个值。
+func (n node) loadMany(vals valueMap, missing []string, query ManyQueryFn, save saveManyFn) error {
+	keys := append([]string(nil), missing...)
+	sort.Strings(keys)
+	flightKey := manyFlightPrefix + strings.Join(keys, manyFlightSeparator)
+	result, fresh, err := n.barrier.DoEx(flightKey, func() (any, error) {
+		start := timex.Now()
+		values, notFound, err := queryMany(vals, missing, query)
+		if err != nil {
+			n.stat.IncrDbFails()
+			return nil, err
+		}
+
+		save(values, notFound, timex.Since(start))
+		return values, nil
+	})
+	if err != nil || fresh {
+		return err
+	}
+
+	codec := n.valueCodec()
+	for key, val := range result.(map[string]any) {
+		data, err := codec.Marshal(val)
+		if err != nil {
+			return err
+		}
+
+		v := vals.newValue()
+		if err = codec.Unmarshal(data, v); err != nil {
+			return err
+		}
+		vals.set(key, v)
+	}
+
+	return nil
+}
+
+// queryMany 以 query 批量查询未缓存的键并填充至 vals，返回查询到的键值及不存在的键。
+func queryMany(vals valueMap, missing []string, query ManyQueryFn) (map[string]any, []string, error) {
+	found, err := query(missing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make(map[string]any, len(found))
+	var notFound []string
+	for _, key := range missing {
+		v, ok := found[key]
+		if !ok {
+			notFound = append(notFound, key)
+			continue
+		}
+
+		if err = vals.setValue(key, v); err != nil {
+			return nil, nil, err
+		}
+		values[key] = v
+	}
+
+	return values, notFound, nil
+}
+
+func newValueMap(vals any) (valueMap, error) {
+	rv := reflect.ValueOf(vals)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Map ||
+		rv.Elem().Type().Key().Kind() != reflect.String {
+		return valueMap{}, ErrInvalidValues
+	}
+
+	m := rv.Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	return valueMap{
+		m:    m,
+		elem: m.Type().Elem(),
+	}, nil
+}
+
+// newValue 返回用于解码的值指针，值类型为指针时直接分配其指向的类型。
+func (m valueMap) newValue() any {
+	if m.elem.Kind() == reflect.Ptr {
+		return reflect.New(m.elem.Elem()).Interface()
+	}
+
+	return reflect.New(m.elem).Interface()
+}
+
+// set 设置由 newValue 分配并解码后的值。
+func (m valueMap) set(key string, v any) {
+	rv := reflect.ValueOf(v)
+	if m.elem.Kind() != reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	m.m.SetMapIndex(m.key(key), rv)
+}
+
+// setValue 设置查询到的值，值可以为 T 或 *T。
+func (m valueMap) setValue(key string, v any) error {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.IsValid() && rv.Type().AssignableTo(m.elem):
+	case rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Type().AssignableTo(m.elem):
+		rv = rv.Elem()
+	default:
+		return fmt.Errorf("%w：键 %s 的值类型 %T 与 %s 不符", ErrInvalidValues, key, v, m.elem)
+	}
+
+	m.m.SetMapIndex(m.key(key), rv)
+	return nil
+}
+
+func (m valueMap) get(key string) (any, bool) {
+	v := m.m.MapIndex(m.key(key))
+	if !v.IsValid() {
+		return nil, false
+	}
+
+	return v.Interface(), true
+}
+
+func (m valueMap) key(key string) reflect.Value {
+	return reflect.ValueOf(key).Convert(m.m.Type().Key())
+}
@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/store/redis"
+	"github.com/gotid/god/lib/store/redis/redistest"
+	"github.com/gotid/god/lib/syncx"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type manyUser struct {
+	Id   int
+	Name string
+}
+
+func TestNode_GetMany(t *testing.T) {
+	for _, typ := range []string{redis.NodeType, redis.ClusterType} {
+		typ := typ
+		t.Run(typ, func(t *testing.T) {
+			rds, clean, err := redistest.CreateRedis()
+			assert.Nil(t, err)
+			defer clean()
+			rds.Type = typ
+
+			n := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+			assert.Nil(t, n.Set("a", manyUser{Id: 1, Name: "a"}))
+			assert.Nil(t, n.Set("b", manyUser{Id: 2, Name: "b"}))
+			assert.Nil(t, rds.Set("c", notFoundPlaceholder))
+
+			var users map[string]manyUser
+			assert.Nil(t, n.GetMany([]string{"a", "b", "c", "d"}, &users))
+			assert.Equal(t, map[string]manyUser{
+				"a": {Id: 1, Name: "a"},
+				"b": {Id: 2, Name: "b"},
+			}, users)
+
+			var ptrs map[string]*manyUser
+			assert.Nil(t, n.GetMany([]string{"a"}, &ptrs))
+			assert.Equal(t, &manyUser{Id: 1, Name: "a"}, ptrs["a"])
+		})
+	}
+}
+
+func TestNode_GetManyInvalidValues(t *testing.T) {
+	rds, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	n := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	var users map[string]manyUser
+	assert.ErrorIs(t, n.GetMany([]string{"a"}, users), ErrInvalidValues)
+	var list []manyUser
+	assert.ErrorIs(t, n.GetMany([]string{"a"}, &list), ErrInvalidValues)
+}
+
+func TestNode_TakeMany(t *testing.T) {
+	rds, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	n := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	assert.Nil(t, n.Set("a", manyUser{Id: 1, Name: "a"}))
+
+	var queried [][]string
+	query := func(missing []string) (map[string]any, error) {
+		queried = append(queried, missing)
+		return map[string]any{
+			"b": manyUser{Id: 2, Name: "b"},
+			"c": &manyUser{Id: 3, Name: "c"},
+		}, nil
+	}
+
+	var users map[string]manyUser
+	assert.Nil(t, n.TakeMany(&users, []string{"a", "b", "c", "d"}, query))
+	assert.Equal(t, [][]string{{"b", "c", "d"}}, queried)
+	assert.Len(t, users, 3)
+	assert.Equal(t, manyUser{Id: 3, Name: "c"}, users["c"])
+
+	val, err := rds.Get("d")
+	assert.Nil(t, err)
+	assert.Equal(t, notFoundPlaceholder, val)
+
+	users = nil
+	assert.Nil(t, n.TakeMany(&users, []string{"a", "b", "c", "d"}, query))
+	assert.Len(t, queried, 1)
+	assert.Len(t, users, 3)
+}
+
+func TestNode_TakeManySingleFlight(t *testing.T) {
+	rds, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	n := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	var calls int32
+	query := func(missing []string) (map[string]any, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(100 * time.Millisecond)
+		return map[string]any{"a": &manyUser{Id: 1}, "b": &manyUser{Id: 2}}, nil
+	}
+
+	const callers = 10
+	results := make([]map[string]*manyUser, callers)
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, n.TakeMany(&results[i], []string{"b", "a"}, query))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for i := 1; i < callers; i++ {
+		assert.Equal(t, results[0], results[i])
+		// 共享的结果须各自复制，调用者之间不共用同一个值。
+		assert.NotSame(t, results[0]["a"], results[i]["a"])
+	}
+}
+
+func TestNode_TakeManyErrors(t *testing.T) {
+	rds, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	n := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	var users map[string]manyUser
+	errDb := errors.New("db")
+	assert.Equal(t, errDb, n.TakeMany(&users, []string{"a"}, func(missing []string) (map[string]any, error) {
+		return nil, errDb
+	}))
+	assert.ErrorIs(t, n.TakeMany(&users, []string{"a"}, func(missing []string) (map[string]any, error) {
+		return map[string]any{"a": "bad"}, nil
+	}), ErrInvalidValues)
+}
+
+func TestCluster_TakeMany(t *testing.T) {
+	r1, clean1, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean1()
+	r2, clean2, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean2()
+
+	conf := ClusterConfig{
+		{Config: redis.Config{Host: r1.Addr, Type: redis.NodeType}, Weight: 100},
+		{Config: redis.Config{Host: r2.Addr, Type: redis.NodeType}, Weight: 100},
+	}
+	c := New(conf, syncx.NewSingleFlight(), NewStat("mock"), errTestNotFound)
+
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user/%d", i)
+	}
+	assert.Nil(t, c.Set(keys[0], 0))
+
+	var calls int
+	var queried []string
+	query := func(missing []string) (map[string]any, error) {
+		calls++
+		queried = append(queried, missing...)
+		vals := make(map[string]any)
+		for _, key := range missing {
+			var id int
+			fmt.Sscanf(key, "user/%d", &id)
+			if id%2 == 0 {
+				vals[key] = id
+			}
+		}
+		return vals, nil
+	}
+
+	var ids map[string]int
+	assert.Nil(t, c.TakeMany(&ids, keys, query))
+	assert.Equal(t, 1, calls)
+	sort.Strings(queried)
+	expect := append([]string(nil), keys[1:]...)
+	sort.Strings(expect)
+	assert.Equal(t, expect, queried)
+	assert.Len(t, ids, 10)
+
+	ids = nil
+	assert.Nil(t, c.TakeMany(&ids, keys, query))
+	assert.Equal(t, 1, calls)
+	assert.Len(t, ids, 10)
+
+	ids = nil
+	assert.Nil(t, c.GetMany(keys, &ids))
+	assert.Len(t, ids, 10)
+	assert.Equal(t, 4, ids["user/4"])
+}
+
+// 含切片字段的编解码器不可比较，以其为字段的节点不能作为 map 的键。
+type taggedCodec struct {
+	JsonCodec
+	tags []string
+}
+
+func (c taggedCodec) Id() byte {
+	return 100
+}
+
+func TestCluster_NonComparableCodec(t *testing.T) {
+	r1, clean1, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean1()
+	r2, clean2, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean2()
+
+	conf := ClusterConfig{
+		{Config: redis.Config{Host: r1.Addr, Type: redis.NodeType}, Weight: 100},
+		{Config: redis.Config{Host: r2.Addr, Type: redis.NodeType}, Weight: 100},
+	}
+	assert.Nil(t, RegisterCodec(taggedCodec{}))
+	c := New(conf, syncx.NewSingleFlight(), NewStat("mock"), errTestNotFound,
+		WithCodec(taggedCodec{tags: []string{"any"}}))
+
+	keys := make([]string, 10)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user/%d", i)
+	}
+	var ids map[string]int
+	assert.Nil(t, c.TakeMany(&ids, keys, func(missing []string) (map[string]any, error) {
+		vals := make(map[string]any)
+		for _, key := range missing {
+			vals[key] = len(key)
+		}
+		return vals, nil
+	}))
+	assert.Len(t, ids, 10)
+
+	ids = nil
+	assert.Nil(t, c.GetMany(keys, &ids))
+	assert.Len(t, ids, 10)
+	assert.Nil(t, c.Del(keys...))
+	ids = nil
+	assert.Nil(t, c.GetMany(keys, &ids))
+	assert.Empty(t, ids)
+}
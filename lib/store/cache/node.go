@@ -4,12 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/gotid/god/lib/jsonx"
 	"github.com/gotid/god/lib/logx"
 	"github.com/gotid/god/lib/mathx"
 	"github.com/gotid/god/lib/stat"
 	"github.com/gotid/god/lib/store/redis"
 	"github.com/gotid/god/lib/syncx"
+	"github.com/gotid/god/lib/timex"
 	"math"
 	"math/rand"
 	"sync"
@@ -37,6 +37,10 @@ type node struct {
 	unstableExpire mathx.Unstable
 	stat           *Stat
 	errNotFound    error
+	staleWindow    time.Duration
+	xfetchBeta     float64
+	codec          Codec
+	compressSize   int
 }
 
 // NewNode 返回一个缓存节点 node。
@@ -52,6 +56,10 @@ func NewNode(rds *redis.Redis, barrier syncx.SingleFlight, st *Stat, errNotFound
 		unstableExpire: mathx.NewUnstable(expireDeviation),
 		stat:           st,
 		errNotFound:    errNotFound,
+		staleWindow:    o.StaleWindow,
+		xfetchBeta:     o.XFetchBeta,
+		codec:          o.Codec,
+		compressSize:   o.CompressSize,
 	}
 }
 
@@ -89,7 +97,7 @@ func (n node) Get(key string, val any) error {
 
 // GetCtx 获取给定 key 的缓存并填充至 val。
 func (n node) GetCtx(ctx context.Context, key string, val any) error {
-	err := n.doGetCache(ctx, key, val)
+	_, err := n.doGetCache(ctx, key, val)
 	if err == errPlaceholder {
 		return n.errNotFound
 	}
@@ -119,12 +127,7 @@ func (n node) SetWithExpire(key string, val any, expire time.Duration) error {
 
 // SetWithExpireCtx 设置给定的键值对及过期时长。
 func (n node) SetWithExpireCtx(ctx context.Context, key string, val any, expire time.Duration) error {
-	data, err := jsonx.Marshal(val)
-	if err != nil {
-		return err
-	}
-
-	return n.rds.SetExCtx(ctx, key, string(data), int(math.Ceil(expire.Seconds())))
+	return n.setCache(ctx, key, val, expire, 0)
 }
 
 // String 返回一个节点的字符串表示形式。
@@ -138,9 +141,11 @@ func (n node) Take(val any, key string, query func(val any) error) error {
 }
 
 // TakeCtx 首先从缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
+// 开启旧值返回或提前过期时，query 可能在调用者返回后于后台再次调用以刷新缓存，
+// 须仅填充传入的 val 而不写入调用者的变量；其捕获的 ctx 已取消时刷新失败，保留当前缓存项。
 func (n node) TakeCtx(ctx context.Context, val any, key string, query func(val any) error) error {
-	return n.doTake(ctx, val, key, query, func(v any) error {
-		return n.SetCtx(ctx, key, val)
+	return n.doTake(ctx, val, key, true, query, func(ctx context.Context, v any, delta time.Duration) error {
+		return n.setCache(ctx, key, v, n.aroundDuration(n.expire), delta)
 	})
 }
 
@@ -150,12 +155,13 @@ func (n node) TakeWithExpire(val any, key string, query func(val any, expire tim
 }
 
 // TakeWithExpireCtx 首先从缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
+// query 通常有填充 val 以外的副作用（如 sqlc 的索引查询），故不在后台刷新，已过期的旧值视为未命中。
 func (n node) TakeWithExpireCtx(ctx context.Context, val any, key string, query func(val any, expire time.Duration) error) error {
 	expire := n.aroundDuration(n.expire)
-	return n.doTake(ctx, val, key, func(val any) error {
+	return n.doTake(ctx, val, key, false, func(val any) error {
 		return query(val, expire)
-	}, func(val any) error {
-		return n.SetWithExpireCtx(ctx, key, val, expire)
+	}, func(ctx context.Context, v any, delta time.Duration) error {
+		return n.setCache(ctx, key, v, expire, delta)
 	})
 }
 
@@ -166,58 +172,70 @@ func (n node) asyncRetryDelCache(keys ...string) {
 	}, keys...)
 }
 
-func (n node) doGetCache(ctx context.Context, key string, val any) error {
+func (n node) doGetCache(ctx context.Context, key string, val any) (entryMeta, error) {
 	n.stat.IncrTotal()
 	data, err := n.rds.GetCtx(ctx, key)
 	if err != nil {
 		n.stat.IncrMiss()
-		return err
+		return entryMeta{}, err
 	}
 
 	if len(data) == 0 {
 		n.stat.IncrMiss()
-		return n.errNotFound
+		return entryMeta{}, n.errNotFound
 	}
 
 	n.stat.IncrHit()
 	if data == notFoundPlaceholder {
-		return errPlaceholder
+		return entryMeta{}, errPlaceholder
 	}
 
-	return n.processCache(ctx, key, data, val)
+	meta, data := decodeEntry(data)
+	return meta, n.processCache(ctx, key, data, val)
 }
 
-func (n node) doTake(ctx context.Context, val any, key string,
+// doTake 获取缓存，未命中时查询并写入缓存，revalidate 表示是否可在后台调用 query 刷新旧值。
+func (n node) doTake(ctx context.Context, val any, key string, revalidate bool,
 	query func(val any) error,
-	cacheVal func(val any) error) error {
+	cacheVal func(ctx context.Context, val any, delta time.Duration) error) error {
 	logger := logx.WithContext(ctx)
 	data, fresh, err := n.barrier.DoEx(key, func() (any, error) {
-		if err := n.doGetCache(ctx, key, val); err != nil {
-			if err == errPlaceholder {
-				return nil, n.errNotFound
-			} else if err != n.errNotFound {
-				// 为何我们马上返回错误而不是继续从db查询，因为我们不允许灾难传递到db。
-				// 有错即返，以防拖垮db。
-				return nil, err
-			}
-
-			if err = query(val); err == n.errNotFound {
-				if err = n.setCacheWithNotFound(ctx, key); err != nil {
-					logger.Error(err)
-				}
+		meta, err := n.doGetCache(ctx, key, val)
+		if err == nil && !revalidate && meta.stale() {
+			err = n.errNotFound
+		}
 
-				return nil, n.errNotFound
-			} else if err != nil {
-				n.stat.IncrDbFails()
-				return nil, err
+		switch {
+		case err == nil:
+			if revalidate {
+				n.revalidate(ctx, key, val, meta, query, cacheVal)
 			}
+			return n.valueCodec().Marshal(val)
+		case err == errPlaceholder:
+			return nil, n.errNotFound
+		case err != n.errNotFound:
+			// 为何我们马上返回错误而不是继续从db查询，因为我们不允许灾难传递到db。
+			// 有错即返，以防拖垮db。
+			return nil, err
+		}
 
-			if err = cacheVal(val); err != nil {
+		start := timex.Now()
+		if err = query(val); err == n.errNotFound {
+			if err = n.setCacheWithNotFound(ctx, key); err != nil {
 				logger.Error(err)
 			}
+
+			return nil, n.errNotFound
+		} else if err != nil {
+			n.stat.IncrDbFails()
+			return nil, err
+		}
+
+		if err = cacheVal(ctx, val, timex.Since(start)); err != nil {
+			logger.Error(err)
 		}
 
-		return jsonx.Marshal(val)
+		return n.valueCodec().Marshal(val)
 	})
 	if err != nil {
 		return err
@@ -233,11 +251,11 @@ func (n node) doTake(ctx context.Context, val any, key string,
 	n.stat.IncrTotal()
 	n.stat.IncrHit()
 
-	return jsonx.Unmarshal(data.([]byte), val)
+	return n.valueCodec().Unmarshal(data.([]byte), val)
 }
 
 func (n node) processCache(ctx context.Context, key, data string, val any) error {
-	err := jsonx.Unmarshal([]byte(data), val)
+	err := decodeValue([]byte(data), val)
 	if err == nil {
 		return nil
 	}
@@ -260,6 +278,14 @@ func (n node) aroundDuration(expire time.Duration) time.Duration {
 	return n.unstableExpire.AroundDuration(expire)
 }
 
+func (n node) valueCodec() Codec {
+	if n.codec == nil {
+		return JsonCodec{}
+	}
+
+	return n.codec
+}
+
 func (n node) setCacheWithNotFound(ctx context.Context, key string) error {
 	seconds := int(math.Ceil(n.aroundDuration(n.notFoundExpire).Seconds()))
 	return n.rds.SetExCtx(ctx, key, notFoundPlaceholder, seconds)
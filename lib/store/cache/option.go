@@ -1,6 +1,8 @@
 package cache
 
-import "time"
+import (
+	"time"
+)
 
 const (
 	defaultExpire         = 7 * 24 * time.Hour
@@ -12,6 +14,10 @@ type (
 	Options struct {
 		Expire         time.Duration // 缓存项的过期时间
 		NotFoundExpire time.Duration // 未命中缓存项的缓存过期时间
+		StaleWindow    time.Duration // 缓存项过期后仍可作为旧值返回的时长
+		XFetchBeta     float64       // 概率提前过期（XFetch）的系数，0 为关闭
+		Codec          Codec         // 缓存值的编解码器
+		CompressSize   int           // 编码后不小于该字节数的值将被压缩，0 为关闭
 	}
 
 	// Option 自定义缓存选项 Options。
@@ -30,6 +36,9 @@ func newOptions(opts ...Option) Options {
 	if o.NotFoundExpire <= 0 {
 		o.NotFoundExpire = defaultNotFoundExpire
 	}
+	if o.Codec == nil {
+		o.Codec = JsonCodec{}
+	}
 
 	return o
 }
@@ -47,3 +56,34 @@ func WithNotFoundExpire(expire time.Duration) Option {
 		o.NotFoundExpire = expire
 	}
 }
+
+// WithStaleWhileRevalidate 开启过期旧值返回，缓存项过期后的 window 时长内，
+// 所有调用者直接返回旧值，仅一个调用者（跨实例）在后台查询数据库刷新缓存。
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(o *Options) {
+		o.StaleWindow = window
+	}
+}
+
+// WithXFetch 开启概率提前过期，越接近过期时间、查询越慢，越可能由单个调用者提前刷新缓存，
+// 避免热点缓存同时过期。beta 通常取 1，大于 1 更倾向于提前刷新。
+func WithXFetch(beta float64) Option {
+	return func(o *Options) {
+		o.XFetchBeta = beta
+	}
+}
+
+// WithCodec 设置缓存值的编解码器，默认为 JsonCodec。
+// 自定义编解码器须先以 RegisterCodec 注册（通常在 init 中），否则写入缓存时返回 ErrUnregisteredCodec。
+func WithCodec(c Codec) Option {
+	return func(o *Options) {
+		o.Codec = c
+	}
+}
+
+// WithCompression 开启压缩，编码后不小于 size 字节的缓存值将以 gzip 压缩。
+func WithCompression(size int) Option {
+	return func(o *Options) {
+		o.CompressSize = size
+	}
+}
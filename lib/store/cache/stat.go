@@ -15,6 +15,10 @@ type Stat struct {
 	Hit     uint64
 	Miss    uint64
 	DbFails uint64
+	// Stale 是过期后返回旧值的次数。
+	Stale uint64
+	// Refresh 是过期或提前过期后刷新缓存的次数。
+	Refresh uint64
 }
 
 // NewStat 返回一个给定名称的缓存统计。
@@ -47,6 +51,16 @@ func (s *Stat) IncrDbFails() {
 	atomic.AddUint64(&s.DbFails, 1)
 }
 
+// IncrStale 递增返回旧值的次数。
+func (s *Stat) IncrStale() {
+	atomic.AddUint64(&s.Stale, 1)
+}
+
+// IncrRefresh 递增刷新缓存的次数。
+func (s *Stat) IncrRefresh() {
+	atomic.AddUint64(&s.Refresh, 1)
+}
+
 func (s *Stat) statLoop() {
 	ticker := time.NewTicker(statInterval)
 	defer ticker.Stop()
@@ -61,7 +75,9 @@ func (s *Stat) statLoop() {
 		percent := 100 * float32(hit) / float32(total)
 		miss := atomic.SwapUint64(&s.Miss, 0)
 		dbFails := atomic.SwapUint64(&s.DbFails, 0)
-		logx.Statf("数据库缓存(%s) - 请求数(m): %d, 命中率: %.1f%%, 命中: %d, 未命中: %d, 数据库错误L %d",
-			s.name, total, percent, hit, miss, dbFails)
+		stale := atomic.SwapUint64(&s.Stale, 0)
+		refresh := atomic.SwapUint64(&s.Refresh, 0)
+		logx.Statf("数据库缓存(%s) - 请求数(m): %d, 命中率: %.1f%%, 命中: %d, 未命中: %d, 数据库错误L %d, "+
+			"旧值: %d, 刷新: %d", s.name, total, percent, hit, miss, dbFails, stale, refresh)
 	}
 }
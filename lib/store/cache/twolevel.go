@@ -0,0 +1,354 @@
+package cache
+
+import (
+	"context"
+	"github.com/gotid/god/lib/collection"
+	"github.com/gotid/god/lib/jsonx"
+	"github.com/gotid/god/lib/logx"
+	"github.com/gotid/god/lib/store/redis"
+	"time"
+)
+
+const (
+	defaultLocalExpire         = 5 * time.Second
+	defaultLocalLimit          = 10000
+	defaultLocalName           = "twolevel"
+	defaultInvalidationChannel = "god:cache:invalidation"
+)
+
+type (
+	// TwoLevelOption 自定义两级缓存的方法。
+	TwoLevelOption func(o *twoLevelOptions)
+
+	// TwoLevelCache 是本地内存 + redis 的两级缓存。
+	// 读取优先命中本地缓存，未命中时经由 redis 缓存读取并回填本地缓存；
+	// 写入及删除作用于 redis 缓存，并通过 redis 发布订阅通知所有实例淘汰本地副本。
+	// 本地副本的有效期较短，以限制通知丢失或并发回填时的不一致时长。
+	TwoLevelCache struct {
+		remote  Cache
+		local   *collection.Cache
+		rds     *redis.Redis
+		channel string
+		sub     *redis.Subscription
+		codec   Codec
+	}
+
+	// codecCache 是可提供编解码器的缓存，本地缓存与其使用相同的编解码器。
+	codecCache interface {
+		valueCodec() Codec
+	}
+
+	// localNotFound 是本地缓存的未找到占位符，保存 redis 缓存返回的未找到错误。
+	localNotFound struct {
+		err error
+	}
+
+	twoLevelOptions struct {
+		expire  time.Duration
+		limit   int
+		name    string
+		channel string
+	}
+)
+
+// WithLocalExpire 自定义本地缓存的过期时间，默认 5 秒。
+func WithLocalExpire(expire time.Duration) TwoLevelOption {
+	return func(o *twoLevelOptions) {
+		o.expire = expire
+	}
+}
+
+// WithLocalLimit 自定义本地缓存的最大条目数，默认 10000。
+func WithLocalLimit(limit int) TwoLevelOption {
+	return func(o *twoLevelOptions) {
+		o.limit = limit
+	}
+}
+
+// WithLocalName 自定义本地缓存的名称，用于统计日志。
+func WithLocalName(name string) TwoLevelOption {
+	return func(o *twoLevelOptions) {
+		o.name = name
+	}
+}
+
+// WithInvalidationChannel 自定义失效通知的 redis 频道，共享同一 redis 缓存的实例须使用相同频道。
+func WithInvalidationChannel(channel string) TwoLevelOption {
+	return func(o *twoLevelOptions) {
+		o.channel = channel
+	}
+}
+
+// NewTwoLevel 返回一个两级缓存，remote 通常由 New 或 NewNode 创建，rds 用于发布及订阅失效通知。
+// 使用完毕须调用 Close 取消订阅。
+func NewTwoLevel(remote Cache, rds *redis.Redis, opts ...TwoLevelOption) (*TwoLevelCache, error) {
+	o := twoLevelOptions{
+		expire:  defaultLocalExpire,
+		limit:   defaultLocalLimit,
+		name:    defaultLocalName,
+		channel: defaultInvalidationChannel,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	local, err := collection.NewCache(o.expire, collection.WithLimit(o.limit), collection.WithName(o.name))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &TwoLevelCache{
+		remote:  remote,
+		local:   local,
+		rds:     rds,
+		channel: o.channel,
+		codec:   JsonCodec{},
+	}
+	if cc, ok := remote.(codecCache); ok {
+		c.codec = cc.valueCodec()
+	}
+	c.sub, err = rds.Subscribe(context.Background(), c.onInvalidation, o.channel)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Close 取消失效通知的订阅。
+func (c *TwoLevelCache) Close() error {
+	return c.sub.Close()
+}
+
+// Del 删除给定 keys 的缓存。
+func (c *TwoLevelCache) Del(keys ...string) error {
+	return c.DelCtx(context.Background(), keys...)
+}
+
+// DelCtx 删除给定 keys 的缓存，并通知所有实例淘汰本地副本。
+func (c *TwoLevelCache) DelCtx(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := c.remote.DelCtx(ctx, keys...)
+	c.invalidate(ctx, keys...)
+	return err
+}
+
+// Get 获取给定 key 的缓存并填充至 val。
+func (c *TwoLevelCache) Get(key string, val any) error {
+	return c.GetCtx(context.Background(), key, val)
+}
+
+// GetCtx 获取给定 key 的缓存并填充至 val。
+func (c *TwoLevelCache) GetCtx(ctx context.Context, key string, val any) error {
+	if ok, err := c.getLocal(key, val); ok {
+		return err
+	}
+
+	err := c.remote.GetCtx(ctx, key, val)
+	c.setLocal(key, val, err)
+	return err
+}
+
+// GetMany 批量获取给定 keys 的缓存并填充至 vals。
+func (c *TwoLevelCache) GetMany(keys []string, vals any) error {
+	return c.GetManyCtx(context.Background(), keys, vals)
+}
+
+// GetManyCtx 批量获取给定 keys 的缓存并填充至 vals（*map[string]T），本地缓存未命中的键经由 redis 缓存读取。
+func (c *TwoLevelCache) GetManyCtx(ctx context.Context, keys []string, vals any) error {
+	m, remaining, err := c.getManyLocal(keys, vals)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+
+	if err = c.remote.GetManyCtx(ctx, remaining, vals); err != nil {
+		return err
+	}
+
+	c.setManyLocal(m, remaining)
+	return nil
+}
+
+// IsNotFound 判断给定错误是否为预定义的未找到错误。
+func (c *TwoLevelCache) IsNotFound(err error) bool {
+	return c.remote.IsNotFound(err)
+}
+
+// Set 设置键值对缓存。
+func (c *TwoLevelCache) Set(key string, val any) error {
+	return c.SetCtx(context.Background(), key, val)
+}
+
+// SetCtx 设置键值对缓存，并通知所有实例淘汰本地副本。
+func (c *TwoLevelCache) SetCtx(ctx context.Context, key string, val any) error {
+	if err := c.remote.SetCtx(ctx, key, val); err != nil {
+		return err
+	}
+
+	c.invalidate(ctx, key)
+	return nil
+}
+
+// SetWithExpire 设置给定的键值对及过期时长。
+func (c *TwoLevelCache) SetWithExpire(key string, val any, expire time.Duration) error {
+	return c.SetWithExpireCtx(context.Background(), key, val, expire)
+}
+
+// SetWithExpireCtx 设置给定的键值对及过期时长，并通知所有实例淘汰本地副本。
+func (c *TwoLevelCache) SetWithExpireCtx(ctx context.Context, key string, val any, expire time.Duration) error {
+	if err := c.remote.SetWithExpireCtx(ctx, key, val, expire); err != nil {
+		return err
+	}
+
+	c.invalidate(ctx, key)
+	return nil
+}
+
+// Take 首先从缓存中获取结果，如果未找到则从DB查询并设置缓存，然后返回结果。
+func (c *TwoLevelCache) Take(val any, key string, query func(val any) error) error {
+	return c.TakeCtx(context.Background(), val, key, query)
+}
+
+// TakeCtx 首先从本地缓存及 redis 缓存中获取结果，如果未找到则从DB查询并设置缓存，然后返回结果。
+func (c *TwoLevelCache) TakeCtx(ctx context.Context, val any, key string, query func(val any) error) error {
+	if ok, err := c.getLocal(key, val); ok {
+		return err
+	}
+
+	err := c.remote.TakeCtx(ctx, val, key, query)
+	c.setLocal(key, val, err)
+	return err
+}
+
+// TakeMany 首先从缓存中批量获取结果，未命中的键以 query 一次批量查询并设置缓存，然后将结果填充至 vals。
+func (c *TwoLevelCache) TakeMany(vals any, keys []string, query ManyQueryFn) error {
+	return c.TakeManyCtx(context.Background(), vals, keys, query)
+}
+
+// TakeManyCtx 首先从本地缓存及 redis 缓存中批量获取结果，未命中的键以 query 一次批量查询并设置缓存，
+// 然后将结果填充至 vals（*map[string]T）。
+func (c *TwoLevelCache) TakeManyCtx(ctx context.Context, vals any, keys []string, query ManyQueryFn) error {
+	m, remaining, err := c.getManyLocal(keys, vals)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+
+	if err = c.remote.TakeManyCtx(ctx, vals, remaining, query); err != nil {
+		return err
+	}
+
+	c.setManyLocal(m, remaining)
+	return nil
+}
+
+// TakeWithExpire 首先从缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
+func (c *TwoLevelCache) TakeWithExpire(val any, key string, query func(val any, expire time.Duration) error) error {
+	return c.TakeWithExpireCtx(context.Background(), val, key, query)
+}
+
+// TakeWithExpireCtx 首先从本地缓存及 redis 缓存中获取结果，如果未找到则从DB查询并设置为给定过期时长，然后返回结果。
+func (c *TwoLevelCache) TakeWithExpireCtx(ctx context.Context, val any, key string,
+	query func(val any, expire time.Duration) error) error {
+	if ok, err := c.getLocal(key, val); ok {
+		return err
+	}
+
+	err := c.remote.TakeWithExpireCtx(ctx, val, key, query)
+	c.setLocal(key, val, err)
+	return err
+}
+
+// getLocal 从本地缓存获取结果，第一个返回值表示本地缓存是否命中。
+func (c *TwoLevelCache) getLocal(key string, val any) (bool, error) {
+	data, ok := c.local.Get(key)
+	if !ok {
+		return false, nil
+	}
+
+	if notFound, ok := data.(localNotFound); ok {
+		return true, notFound.err
+	}
+
+	if err := c.codec.Unmarshal(data.([]byte), val); err != nil {
+		c.local.Del(key)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// setLocal 以 redis 缓存的读取结果回填本地缓存，未找到同样缓存以避免穿透至 redis。
+func (c *TwoLevelCache) setLocal(key string, val any, err error) {
+	switch {
+	case err == nil:
+		data, err := c.codec.Marshal(val)
+		if err != nil {
+			return
+		}
+		c.local.Set(key, data)
+	case c.remote.IsNotFound(err):
+		c.local.Set(key, localNotFound{err: err})
+	}
+}
+
+// getManyLocal 从本地缓存批量获取结果，返回本地缓存未命中的键，本地缓存为未找到的键不在其中。
+func (c *TwoLevelCache) getManyLocal(keys []string, vals any) (valueMap, []string, error) {
+	m, err := newValueMap(vals)
+	if err != nil {
+		return valueMap{}, nil, err
+	}
+
+	var remaining []string
+	for _, key := range keys {
+		v := m.newValue()
+		ok, err := c.getLocal(key, v)
+		switch {
+		case !ok:
+			remaining = append(remaining, key)
+		case err == nil:
+			m.set(key, v)
+		}
+	}
+
+	return m, remaining, nil
+}
+
+// setManyLocal 以 redis 缓存的批量读取结果回填本地缓存，仅回填命中的键。
+func (c *TwoLevelCache) setManyLocal(m valueMap, keys []string) {
+	for _, key := range keys {
+		if v, ok := m.get(key); ok {
+			c.setLocal(key, v, nil)
+		}
+	}
+}
+
+// invalidate 淘汰本地副本并通知其他实例，通知失败时仅记录日志，由本地缓存的过期兜底。
+func (c *TwoLevelCache) invalidate(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		c.local.Del(key)
+	}
+
+	payload, err := jsonx.Marshal(keys)
+	if err != nil {
+		return
+	}
+
+	if _, err = c.rds.PublishCtx(ctx, c.channel, payload); err != nil {
+		logx.WithContext(ctx).Errorf("发布缓存失效通知失败，键：%q，错误：%v", keys, err)
+	}
+}
+
+func (c *TwoLevelCache) onInvalidation(msg *redis.Message) {
+	var keys []string
+	if err := jsonx.UnmarshalFromString(msg.Payload, &keys); err != nil {
+		logx.Errorf("解析缓存失效通知失败，内容：%s，错误：%v", msg.Payload, err)
+		return
+	}
+
+	for _, key := range keys {
+		c.local.Del(key)
+	}
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gotid/god/lib/store/redis"
+	"github.com/gotid/god/lib/syncx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTwoLevelCache(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	rds := redis.New(r.Addr())
+	remote := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	c, err := NewTwoLevel(remote, rds, WithLocalExpire(time.Minute), WithLocalLimit(10),
+		WithLocalName("test"), WithInvalidationChannel("test:invalidation"))
+	assert.Nil(t, err)
+	defer c.Close()
+
+	var queries int
+	query := func(val any) error {
+		queries++
+		*val.(*string) = "value"
+		return nil
+	}
+
+	var val string
+	assert.Nil(t, c.Take(&val, "key", query))
+	assert.Equal(t, "value", val)
+	assert.True(t, r.Exists("key"))
+
+	// 删除 redis 中的值后，仍命中本地缓存。
+	r.Del("key")
+	val = ""
+	assert.Nil(t, c.Take(&val, "key", query))
+	assert.Equal(t, "value", val)
+	assert.Nil(t, c.Get("key", &val))
+	assert.Equal(t, 1, queries)
+
+	assert.Nil(t, c.Set("key", "changed"))
+	assert.Nil(t, c.Get("key", &val))
+	assert.Equal(t, "changed", val)
+
+	assert.Nil(t, c.SetWithExpire("other", "any", time.Minute))
+	assert.Nil(t, c.TakeWithExpire(&val, "other", func(val any, expire time.Duration) error {
+		t.Fatal("不应查询")
+		return nil
+	}))
+	assert.Equal(t, "any", val)
+
+	assert.Nil(t, c.Del("key", "other"))
+	assert.Nil(t, c.Del())
+	assert.True(t, c.IsNotFound(c.Get("key", &val)))
+	r.Set("key", `"remote"`)
+	// 未找到同样缓存在本地。
+	assert.True(t, c.IsNotFound(c.Get("key", &val)))
+}
+
+func TestTwoLevelCacheCodec(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	rds := redis.New(r.Addr())
+	remote := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound, WithCodec(GobCodec{}))
+	c, err := NewTwoLevel(remote, rds)
+	assert.Nil(t, err)
+	defer c.Close()
+	assert.Equal(t, GobCodec{}, c.codec)
+
+	var val string
+	assert.Nil(t, c.Take(&val, "key", func(val any) error {
+		*val.(*string) = "value"
+		return nil
+	}))
+	// 本地副本同样以配置的编解码器编码。
+	data, ok := c.local.Get("key")
+	assert.True(t, ok)
+	expect, err := GobCodec{}.Marshal("value")
+	assert.Nil(t, err)
+	assert.Equal(t, expect, data)
+	val = ""
+	assert.Nil(t, c.Get("key", &val))
+	assert.Equal(t, "value", val)
+}
+
+func TestTwoLevelCacheInvalidation(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	rds := redis.New(r.Addr())
+	remote := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	a, err := NewTwoLevel(remote, rds, WithLocalExpire(time.Minute))
+	assert.Nil(t, err)
+	defer a.Close()
+	b, err := NewTwoLevel(remote, rds, WithLocalExpire(time.Minute))
+	assert.Nil(t, err)
+	defer b.Close()
+
+	assert.Nil(t, remote.Set("key", "value"))
+	var val string
+	assert.Nil(t, a.Get("key", &val))
+	assert.Nil(t, b.Get("key", &val))
+
+	assert.Nil(t, a.Del("key"))
+	assert.Eventually(t, func() bool {
+		_, ok := b.local.Get("key")
+		return !ok
+	}, time.Second, time.Millisecond*10)
+	assert.True(t, b.IsNotFound(b.Get("key", &val)))
+}
+
+func TestTwoLevelCacheBadRedis(t *testing.T) {
+	rds := redis.New("anyredis:8888", func(r *redis.Redis) {
+		r.Type = "bad"
+	})
+	remote := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	_, err := NewTwoLevel(remote, rds)
+	assert.NotNil(t, err)
+}
+
+func TestTwoLevelCacheTakeMany(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	rds := redis.New(r.Addr())
+	remote := NewNode(rds, syncx.NewSingleFlight(), NewStat("any"), errTestNotFound)
+	c, err := NewTwoLevel(remote, rds, WithLocalExpire(time.Minute))
+	assert.Nil(t, err)
+	defer c.Close()
+
+	var queries int
+	query := func(missing []string) (map[string]any, error) {
+		queries++
+		return map[string]any{"a": "1", "b": "2"}, nil
+	}
+
+	var vals map[string]string
+	assert.Nil(t, c.TakeMany(&vals, []string{"a", "b", "c"}, query))
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, vals)
+
+	// 删除 redis 中的值后，仍命中本地缓存。
+	r.Del("a")
+	vals = nil
+	assert.Nil(t, c.GetMany([]string{"a", "b"}, &vals))
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, vals)
+	assert.Nil(t, c.TakeMany(&vals, []string{"a", "b", "c"}, query))
+	assert.Equal(t, 1, queries)
+}
@@ -5,16 +5,52 @@ import (
 	"errors"
 	"github.com/gotid/god/lib/errorx"
 	"github.com/gotid/god/lib/hash"
+	"github.com/gotid/god/lib/lang"
 	"github.com/gotid/god/lib/store/cache"
 	"github.com/gotid/god/lib/store/redis"
 	"log"
 )
 
-var ErrNoRedisNode = errors.New("未找到键对应的 redis 节点")
+const (
+	// Scan 游标高 16 位为节点序号，低 48 位为节点内的游标。
+	scanNodeShift  = 48
+	scanCursorMask = 1<<scanNodeShift - 1
+)
+
+var (
+	// ErrNoRedisNode 表示未找到键对应的 redis 节点。
+	ErrNoRedisNode = errors.New("未找到键对应的 redis 节点")
+	// ErrCrossNode 表示多键命令涉及的键分布于多个 redis 节点。
+	ErrCrossNode = errors.New("键分布于多个 redis 节点")
+)
 
 type (
 	// Store 接口代表一个键值对存储 KVStore。
 	Store interface {
+		// BitCount 统计 key 中 [start, end] 字节范围内被设置为 1 的比特数。
+		BitCount(key string, start, end int64) (int64, error)
+		// BitCountCtx 统计 key 中 [start, end] 字节范围内被设置为 1 的比特数。
+		BitCountCtx(ctx context.Context, key string, start, end int64) (int64, error)
+		// BitOpAnd 对 keys 做按位与运算并保存至 destKey，所有键须位于同一节点。
+		BitOpAnd(destKey string, keys ...string) (int64, error)
+		// BitOpAndCtx 对 keys 做按位与运算并保存至 destKey，所有键须位于同一节点。
+		BitOpAndCtx(ctx context.Context, destKey string, keys ...string) (int64, error)
+		// BitOpNot 对 key 做按位取反运算并保存至 destKey，两个键须位于同一节点。
+		BitOpNot(destKey, key string) (int64, error)
+		// BitOpNotCtx 对 key 做按位取反运算并保存至 destKey，两个键须位于同一节点。
+		BitOpNotCtx(ctx context.Context, destKey, key string) (int64, error)
+		// BitOpOr 对 keys 做按位或运算并保存至 destKey，所有键须位于同一节点。
+		BitOpOr(destKey string, keys ...string) (int64, error)
+		// BitOpOrCtx 对 keys 做按位或运算并保存至 destKey，所有键须位于同一节点。
+		BitOpOrCtx(ctx context.Context, destKey string, keys ...string) (int64, error)
+		// BitOpXor 对 keys 做按位异或运算并保存至 destKey，所有键须位于同一节点。
+		BitOpXor(destKey string, keys ...string) (int64, error)
+		// BitOpXorCtx 对 keys 做按位异或运算并保存至 destKey，所有键须位于同一节点。
+		BitOpXorCtx(ctx context.Context, destKey string, keys ...string) (int64, error)
+		// BitPos 返回 key 中 [start, end] 字节范围内第一个值为 bit 的比特位置。
+		BitPos(key string, bit, start, end int64) (int64, error)
+		// BitPosCtx 返回 key 中 [start, end] 字节范围内第一个值为 bit 的比特位置。
+		BitPosCtx(ctx context.Context, key string, bit, start, end int64) (int64, error)
 		// Decr 将 key 中储存的数值减1。
 		Decr(key string) (int64, error)
 		// DecrCtx 将 key 中储存的数值减1。
@@ -31,6 +67,10 @@ type (
 		Eval(script string, key string, args ...any) (any, error)
 		// EvalCtx 对 Lua 脚本及键值参数 keys, args 求值。
 		EvalCtx(ctx context.Context, script string, key string, args ...any) (any, error)
+		// EvalSha 对缓存的 sha 脚本及键值参数 key, args 求值。
+		EvalSha(sha string, key string, args ...any) (any, error)
+		// EvalShaCtx 对缓存的 sha 脚本及键值参数 key, args 求值。
+		EvalShaCtx(ctx context.Context, sha string, key string, args ...any) (any, error)
 		// Exists 检查 key 是否存在。
 		Exists(key string) (bool, error)
 		// ExistsCtx 检查 key 是否存在。
@@ -43,6 +83,32 @@ type (
 		ExpireAt(key string, expireTime int64) error
 		// ExpireAtCtx 设置 key 的过期时间，过期会自动删除。
 		ExpireAtCtx(ctx context.Context, key string, expireTime int64) error
+		// GeoAdd 将地理空间位置 geoLocation 添加到 key 中。
+		GeoAdd(key string, geoLocation ...*redis.GeoLocation) (int64, error)
+		// GeoAddCtx 将地理空间位置 geoLocation 添加到 key 中。
+		GeoAddCtx(ctx context.Context, key string, geoLocation ...*redis.GeoLocation) (int64, error)
+		// GeoDist 返回 key 中两个成员之间的距离，unit 为距离单位。
+		GeoDist(key, member1, member2, unit string) (float64, error)
+		// GeoDistCtx 返回 key 中两个成员之间的距离，unit 为距离单位。
+		GeoDistCtx(ctx context.Context, key, member1, member2, unit string) (float64, error)
+		// GeoHash 返回 key 中成员 members 的 Geohash 表示。
+		GeoHash(key string, members ...string) ([]string, error)
+		// GeoHashCtx 返回 key 中成员 members 的 Geohash 表示。
+		GeoHashCtx(ctx context.Context, key string, members ...string) ([]string, error)
+		// GeoPos 返回 key 中成员 members 的经纬度。
+		GeoPos(key string, members ...string) ([]*redis.GeoPos, error)
+		// GeoPosCtx 返回 key 中成员 members 的经纬度。
+		GeoPosCtx(ctx context.Context, key string, members ...string) ([]*redis.GeoPos, error)
+		// GeoRadius 返回 key 中距离给定经纬度在 query 范围内的成员。
+		GeoRadius(key string, longitude, latitude float64, query *redis.GeoRadiusQuery) ([]redis.GeoLocation, error)
+		// GeoRadiusCtx 返回 key 中距离给定经纬度在 query 范围内的成员。
+		GeoRadiusCtx(ctx context.Context, key string, longitude, latitude float64,
+			query *redis.GeoRadiusQuery) ([]redis.GeoLocation, error)
+		// GeoRadiusByMember 返回 key 中距离成员 member 在 query 范围内的成员。
+		GeoRadiusByMember(key, member string, query *redis.GeoRadiusQuery) ([]redis.GeoLocation, error)
+		// GeoRadiusByMemberCtx 返回 key 中距离成员 member 在 query 范围内的成员。
+		GeoRadiusByMemberCtx(ctx context.Context, key, member string, query *redis.GeoRadiusQuery) (
+			[]redis.GeoLocation, error)
 		// Get 获取 key 的值。
 		Get(key string) (string, error)
 		// GetCtx 获取 key 的值。
@@ -87,13 +153,22 @@ type (
 		HMGet(key string, fields ...string) ([]string, error)
 		// HMGetCtx 获取哈希 key 中所有给定字段 fields 的值。
 		HMGetCtx(ctx context.Context, key string, fields ...string) ([]string, error)
+		// HScan 迭代哈希 key 中的字段。
+		HScan(key string, cursor uint64, match string, count int64) (keys []string, cur uint64, err error)
+		// HScanCtx 迭代哈希 key 中的字段。
+		HScanCtx(ctx context.Context, key string, cursor uint64, match string, count int64) (
+			keys []string, cur uint64, err error)
 		// HSet 设置哈希 key 的字段 field 的值为 value。
 		HSet(key, field, value string) error
 		// HSetCtx 设置哈希 key 的字段 field 的值为 value。
 		HSetCtx(ctx context.Context, key, field, value string) error
-		// HSetNx 当哈希 key 中字段 field 不存在时，增加字段值 field:value。
+		// HSetNX 当哈希 key 中字段 field 不存在时，增加字段值 field:value。
+		HSetNX(key, field, value string) (bool, error)
+		// HSetNXCtx 当哈希 key 中字段 field 不存在时，增加字段值 field:value。
+		HSetNXCtx(ctx context.Context, key, field, value string) (bool, error)
+		// HSetNx 同 HSetNX，为兼容保留。
 		HSetNx(key, field, value string) (bool, error)
-		// HSetNxCtx 当哈希 key 中字段 field 不存在时，增加字段值 field:value。
+		// HSetNxCtx 同 HSetNXCtx，为兼容保留。
 		HSetNxCtx(ctx context.Context, key, field, value string) (bool, error)
 		// HMSet 同时设置多个键值到哈希 key。
 		HMSet(key string, fieldsAndValues map[string]string) error
@@ -111,6 +186,10 @@ type (
 		IncrBy(key string, increment int64) (int64, error)
 		// IncrByCtx 将 key 所储存的值加上给定的增量值（increment） 。
 		IncrByCtx(ctx context.Context, key string, increment int64) (int64, error)
+		// Keys 返回所有节点中匹配 pattern 的键。
+		Keys(pattern string) ([]string, error)
+		// KeysCtx 返回所有节点中匹配 pattern 的键。
+		KeysCtx(ctx context.Context, pattern string) ([]string, error)
 		// LLen 获取列表长度。
 		LLen(key string) (int, error)
 		// LLenCtx 获取列表长度。
@@ -139,6 +218,10 @@ type (
 		LTrim(key string, start, stop int64) error
 		// LTrimCtx 修剪列表，只保留指定起止区间的元素。
 		LTrimCtx(ctx context.Context, key string, start, stop int64) error
+		// MGet 按 keys 的顺序返回其值，不存在的键返回空字符串，键可分布于多个节点。
+		MGet(keys ...string) ([]string, error)
+		// MGetCtx 按 keys 的顺序返回其值，不存在的键返回空字符串，键可分布于多个节点。
+		MGetCtx(ctx context.Context, keys ...string) ([]string, error)
 		// Persist 移除 key 的过期时间，key 将持久保持。
 		Persist(key string) (bool, error)
 		// PersistCtx 移除 key 的过期时间，key 将持久保持。
@@ -151,6 +234,18 @@ type (
 		PFCount(key string) (int64, error)
 		// PFCountCtx 返回给定 HyperLogLog 的基数估算值。
 		PFCountCtx(ctx context.Context, key string) (val int64, err error)
+		// PFMerge 将 keys 的 HyperLogLog 合并至 dest，所有键须位于同一节点。
+		PFMerge(dest string, keys ...string) error
+		// PFMergeCtx 将 keys 的 HyperLogLog 合并至 dest，所有键须位于同一节点。
+		PFMergeCtx(ctx context.Context, dest string, keys ...string) error
+		// Ping 检测所有节点是否可用。
+		Ping() bool
+		// PingCtx 检测所有节点是否可用。
+		PingCtx(ctx context.Context) bool
+		// Pipelined 执行管道化函数，fn 涉及的键 keys 须位于同一节点。
+		Pipelined(fn func(redis.Pipeliner) error, keys ...string) error
+		// PipelinedCtx 执行管道化函数，fn 涉及的键 keys 须位于同一节点。
+		PipelinedCtx(ctx context.Context, fn func(redis.Pipeliner) error, keys ...string) error
 		// RPop 移除并返回列表的最后一个元素。
 		RPop(key string) (string, error)
 		// RPopCtx 移除并返回列表的最后一个元素。
@@ -171,6 +266,25 @@ type (
 		SCard(key string) (int64, error)
 		// SCardCtx 获取集合的成员数。
 		SCardCtx(ctx context.Context, key string) (val int64, err error)
+		// Scan 依次迭代所有节点中的键，游标高 16 位为节点序号，返回游标为 0 时迭代结束。
+		// 迭代期间增减节点将导致结果不完整。
+		Scan(cursor uint64, match string, count int64) (keys []string, cur uint64, err error)
+		// ScanCtx 依次迭代所有节点中的键，游标高 16 位为节点序号，返回游标为 0 时迭代结束。
+		// 迭代期间增减节点将导致结果不完整。
+		ScanCtx(ctx context.Context, cursor uint64, match string, count int64) (
+			keys []string, cur uint64, err error)
+		// ScriptLoad 将脚本 script 加载至所有节点的脚本缓存，返回脚本的 sha1 校验码。
+		ScriptLoad(script string) (string, error)
+		// ScriptLoadCtx 将脚本 script 加载至所有节点的脚本缓存，返回脚本的 sha1 校验码。
+		ScriptLoadCtx(ctx context.Context, script string) (string, error)
+		// SDiff 返回第一个集合与其余集合的差集，键可分布于多个节点。
+		SDiff(keys ...string) ([]string, error)
+		// SDiffCtx 返回第一个集合与其余集合的差集，键可分布于多个节点。
+		SDiffCtx(ctx context.Context, keys ...string) ([]string, error)
+		// SDiffStore 将第一个集合与其余集合的差集保存至 destination，所有键须位于同一节点。
+		SDiffStore(destination string, keys ...string) (int, error)
+		// SDiffStoreCtx 将第一个集合与其余集合的差集保存至 destination，所有键须位于同一节点。
+		SDiffStoreCtx(ctx context.Context, destination string, keys ...string) (int, error)
 		// Set 设置 key 的值。
 		Set(key, value string) error
 		// SetCtx 设置 key 的值。
@@ -191,6 +305,14 @@ type (
 		SetNXEx(key, value string, seconds int) (bool, error)
 		// SetNXExCtx 当 key 不存时，设置键值对及其存活秒数，过期会自动删除。
 		SetNXExCtx(ctx context.Context, key, value string, seconds int) (val bool, err error)
+		// SInter 返回所有集合的交集，键可分布于多个节点。
+		SInter(keys ...string) ([]string, error)
+		// SInterCtx 返回所有集合的交集，键可分布于多个节点。
+		SInterCtx(ctx context.Context, keys ...string) ([]string, error)
+		// SInterStore 将所有集合的交集保存至 destination，所有键须位于同一节点。
+		SInterStore(destination string, keys ...string) (int, error)
+		// SInterStoreCtx 将所有集合的交集保存至 destination，所有键须位于同一节点。
+		SInterStoreCtx(ctx context.Context, destination string, keys ...string) (int, error)
 		// SIsMember 判断 member 是否为集合 key 的成员。
 		SIsMember(key string, member any) (bool, error)
 		// SIsMemberCtx 判断 member 是否为集合 key 的成员。
@@ -211,10 +333,92 @@ type (
 		SRem(key string, members ...any) (int, error)
 		// SRemCtx 移除集合 key 中的一个或多个成员 members。
 		SRemCtx(ctx context.Context, key string, members ...any) (val int, err error)
+		// SUnion 返回所有集合的并集，键可分布于多个节点。
+		SUnion(keys ...string) ([]string, error)
+		// SUnionCtx 返回所有集合的并集，键可分布于多个节点。
+		SUnionCtx(ctx context.Context, keys ...string) ([]string, error)
+		// SUnionStore 将所有集合的并集保存至 destination，所有键须位于同一节点。
+		SUnionStore(destination string, keys ...string) (int, error)
+		// SUnionStoreCtx 将所有集合的并集保存至 destination，所有键须位于同一节点。
+		SUnionStoreCtx(ctx context.Context, destination string, keys ...string) (int, error)
 		// TTL 返回 key 的剩余生存秒数。
 		TTL(key string) (int, error)
 		// TTLCtx 返回 key 的剩余生存秒数。
 		TTLCtx(ctx context.Context, key string) (val int, err error)
+		// TxPipelined 以 MULTI/EXEC 事务执行管道化函数，fn 涉及的键 keys 须位于同一节点。
+		TxPipelined(fn func(redis.Pipeliner) error, keys ...string) error
+		// TxPipelinedCtx 以 MULTI/EXEC 事务执行管道化函数，fn 涉及的键 keys 须位于同一节点。
+		TxPipelinedCtx(ctx context.Context, fn func(redis.Pipeliner) error, keys ...string) error
+		// Watch 监视键 keys 并执行 fn，键被其他客户端修改时重试，keys 须位于同一节点。
+		Watch(ctx context.Context, fn func(tx *redis.Tx) error, keys ...string) error
+		// XAck 确认消费组 group 已处理流 stream 中的消息 ids。
+		XAck(stream, group string, ids ...string) (int64, error)
+		// XAckCtx 确认消费组 group 已处理流 stream 中的消息 ids。
+		XAckCtx(ctx context.Context, stream, group string, ids ...string) (int64, error)
+		// XAdd 向流追加消息，返回消息 ID。
+		XAdd(a *redis.XAddArgs) (string, error)
+		// XAddCtx 向流追加消息，返回消息 ID。
+		XAddCtx(ctx context.Context, a *redis.XAddArgs) (string, error)
+		// XAutoClaim 将空闲的待确认消息转移给 Consumer，返回转移的消息及下次扫描的起始 ID。
+		XAutoClaim(a *redis.XAutoClaimArgs) ([]redis.XMessage, string, error)
+		// XAutoClaimCtx 将空闲的待确认消息转移给 Consumer，返回转移的消息及下次扫描的起始 ID。
+		XAutoClaimCtx(ctx context.Context, a *redis.XAutoClaimArgs) ([]redis.XMessage, string, error)
+		// XClaim 将空闲的给定待确认消息转移给 Consumer。
+		XClaim(a *redis.XClaimArgs) ([]redis.XMessage, error)
+		// XClaimCtx 将空闲的给定待确认消息转移给 Consumer。
+		XClaimCtx(ctx context.Context, a *redis.XClaimArgs) ([]redis.XMessage, error)
+		// XGroupCreate 在流 stream 上创建消费组 group。
+		XGroupCreate(stream, group, start string) error
+		// XGroupCreateCtx 在流 stream 上创建消费组 group。
+		XGroupCreateCtx(ctx context.Context, stream, group, start string) error
+		// XGroupCreateMkStream 在流 stream 上创建消费组 group，流不存在时自动创建。
+		XGroupCreateMkStream(stream, group, start string) error
+		// XGroupCreateMkStreamCtx 在流 stream 上创建消费组 group，流不存在时自动创建。
+		XGroupCreateMkStreamCtx(ctx context.Context, stream, group, start string) error
+		// XGroupDestroy 销毁流 stream 上的消费组 group。
+		XGroupDestroy(stream, group string) (int64, error)
+		// XGroupDestroyCtx 销毁流 stream 上的消费组 group。
+		XGroupDestroyCtx(ctx context.Context, stream, group string) (int64, error)
+		// XInfoConsumers 返回流 stream 上消费组 group 的消费者信息。
+		XInfoConsumers(stream, group string) ([]redis.XInfoConsumer, error)
+		// XInfoConsumersCtx 返回流 stream 上消费组 group 的消费者信息。
+		XInfoConsumersCtx(ctx context.Context, stream, group string) ([]redis.XInfoConsumer, error)
+		// XInfoGroups 返回流 stream 的消费组信息。
+		XInfoGroups(stream string) ([]redis.XInfoGroup, error)
+		// XInfoGroupsCtx 返回流 stream 的消费组信息。
+		XInfoGroupsCtx(ctx context.Context, stream string) ([]redis.XInfoGroup, error)
+		// XInfoStream 返回流 stream 的信息。
+		XInfoStream(stream string) (*redis.XInfoStream, error)
+		// XInfoStreamCtx 返回流 stream 的信息。
+		XInfoStreamCtx(ctx context.Context, stream string) (*redis.XInfoStream, error)
+		// XLen 返回流 stream 的消息数量。
+		XLen(stream string) (int64, error)
+		// XLenCtx 返回流 stream 的消息数量。
+		XLenCtx(ctx context.Context, stream string) (int64, error)
+		// XPending 返回流 stream 上消费组 group 的待确认消息概况。
+		XPending(stream, group string) (*redis.XPending, error)
+		// XPendingCtx 返回流 stream 上消费组 group 的待确认消息概况。
+		XPendingCtx(ctx context.Context, stream, group string) (*redis.XPending, error)
+		// XPendingExt 返回消费组的待确认消息明细。
+		XPendingExt(a *redis.XPendingExtArgs) ([]redis.XPendingExt, error)
+		// XPendingExtCtx 返回消费组的待确认消息明细。
+		XPendingExtCtx(ctx context.Context, a *redis.XPendingExtArgs) ([]redis.XPendingExt, error)
+		// XRead 从流中读取消息，所有流须位于同一节点。
+		XRead(a *redis.XReadArgs) ([]redis.XStream, error)
+		// XReadCtx 从流中读取消息，所有流须位于同一节点。
+		XReadCtx(ctx context.Context, a *redis.XReadArgs) ([]redis.XStream, error)
+		// XReadGroup 以消费组中的消费者身份读取消息，所有流须位于同一节点。
+		XReadGroup(a *redis.XReadGroupArgs) ([]redis.XStream, error)
+		// XReadGroupCtx 以消费组中的消费者身份读取消息，所有流须位于同一节点。
+		XReadGroupCtx(ctx context.Context, a *redis.XReadGroupArgs) ([]redis.XStream, error)
+		// XTrimMaxLen 将流 stream 裁剪至最多 maxLen 条消息。
+		XTrimMaxLen(stream string, maxLen int64) (int64, error)
+		// XTrimMaxLenCtx 将流 stream 裁剪至最多 maxLen 条消息。
+		XTrimMaxLenCtx(ctx context.Context, stream string, maxLen int64) (int64, error)
+		// XTrimMinID 删除流 stream 中 ID 小于 minID 的消息。
+		XTrimMinID(stream, minID string) (int64, error)
+		// XTrimMinIDCtx 删除流 stream 中 ID 小于 minID 的消息。
+		XTrimMinIDCtx(ctx context.Context, stream, minID string) (int64, error)
 		// ZAdd 向有序集合 key 添加或更新一个成员及其分数。
 		ZAdd(key string, score int64, member string) (bool, error)
 		// ZAddFloat 向有序集合 key 添加或更新一个成员及其分数。
@@ -295,11 +499,17 @@ type (
 		ZRevRank(key, member string) (int64, error)
 		// ZRevRankCtx 获取有序集合中给定成员的降序索引排名。
 		ZRevRankCtx(ctx context.Context, key, member string) (val int64, err error)
+		// ZUnionStore 将 store 中有序集合的并集保存至 dest，所有键须位于同一节点。
+		ZUnionStore(dest string, store *redis.ZStore) (int64, error)
+		// ZUnionStoreCtx 将 store 中有序集合的并集保存至 dest，所有键须位于同一节点。
+		ZUnionStoreCtx(ctx context.Context, dest string, store *redis.ZStore) (int64, error)
 	}
 
 	// 基于缓存集群的 kv 存储
 	kvStore struct {
 		dispatcher *hash.ConsistentHash
+		// nodes 按配置顺序保存所有节点，用于 Keys、Scan 等需遍历所有节点的命令。
+		nodes []*redis.Redis
 	}
 )
 
@@ -312,14 +522,95 @@ func New(c Config) Store {
 	// 即使只有一个节点，我们也是用一致性哈希，
 	// 因为 kv存储和redis存储的方法不同。
 	dispatcher := hash.NewConsistentHash()
+	var nodes []*redis.Redis
 	for _, cfg := range c {
 		rds := cfg.NewRedis()
 		dispatcher.AddWithWeight(rds, cfg.Weight)
+		nodes = append(nodes, rds)
 	}
 
 	return kvStore{
 		dispatcher: dispatcher,
+		nodes:      nodes,
+	}
+}
+
+func (s kvStore) BitCount(key string, start, end int64) (int64, error) {
+	return s.BitCountCtx(context.Background(), key, start, end)
+}
+
+func (s kvStore) BitCountCtx(ctx context.Context, key string, start, end int64) (int64, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.BitCountCtx(ctx, key, start, end)
+}
+
+func (s kvStore) BitOpAnd(destKey string, keys ...string) (int64, error) {
+	return s.BitOpAndCtx(context.Background(), destKey, keys...)
+}
+
+func (s kvStore) BitOpAndCtx(ctx context.Context, destKey string, keys ...string) (int64, error) {
+	node, err := s.getKeysRedis(append([]string{destKey}, keys...))
+	if err != nil {
+		return 0, err
 	}
+
+	return node.BitOpAndCtx(ctx, destKey, keys...)
+}
+
+func (s kvStore) BitOpNot(destKey, key string) (int64, error) {
+	return s.BitOpNotCtx(context.Background(), destKey, key)
+}
+
+func (s kvStore) BitOpNotCtx(ctx context.Context, destKey, key string) (int64, error) {
+	node, err := s.getKeysRedis([]string{destKey, key})
+	if err != nil {
+		return 0, err
+	}
+
+	return node.BitOpNotCtx(ctx, destKey, key)
+}
+
+func (s kvStore) BitOpOr(destKey string, keys ...string) (int64, error) {
+	return s.BitOpOrCtx(context.Background(), destKey, keys...)
+}
+
+func (s kvStore) BitOpOrCtx(ctx context.Context, destKey string, keys ...string) (int64, error) {
+	node, err := s.getKeysRedis(append([]string{destKey}, keys...))
+	if err != nil {
+		return 0, err
+	}
+
+	return node.BitOpOrCtx(ctx, destKey, keys...)
+}
+
+func (s kvStore) BitOpXor(destKey string, keys ...string) (int64, error) {
+	return s.BitOpXorCtx(context.Background(), destKey, keys...)
+}
+
+func (s kvStore) BitOpXorCtx(ctx context.Context, destKey string, keys ...string) (int64, error) {
+	node, err := s.getKeysRedis(append([]string{destKey}, keys...))
+	if err != nil {
+		return 0, err
+	}
+
+	return node.BitOpXorCtx(ctx, destKey, keys...)
+}
+
+func (s kvStore) BitPos(key string, bit, start, end int64) (int64, error) {
+	return s.BitPosCtx(context.Background(), key, bit, start, end)
+}
+
+func (s kvStore) BitPosCtx(ctx context.Context, key string, bit, start, end int64) (int64, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.BitPosCtx(ctx, key, bit, start, end)
 }
 
 func (s kvStore) Decr(key string) (int64, error) {
@@ -386,6 +677,19 @@ func (s kvStore) EvalCtx(ctx context.Context, script, key string, args ...any) (
 	return node.EvalCtx(ctx, script, []string{key}, args...)
 }
 
+func (s kvStore) EvalSha(sha, key string, args ...any) (any, error) {
+	return s.EvalShaCtx(context.Background(), sha, key, args...)
+}
+
+func (s kvStore) EvalShaCtx(ctx context.Context, sha, key string, args ...any) (any, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.EvalShaCtx(ctx, sha, []string{key}, args...)
+}
+
 func (s kvStore) Exists(key string) (bool, error) {
 	return s.ExistsCtx(context.Background(), key)
 }
@@ -425,6 +729,89 @@ func (s kvStore) ExpireAtCtx(ctx context.Context, key string, expireTime int64)
 	return node.ExpireAtCtx(ctx, key, expireTime)
 }
 
+func (s kvStore) GeoAdd(key string, geoLocation ...*redis.GeoLocation) (int64, error) {
+	return s.GeoAddCtx(context.Background(), key, geoLocation...)
+}
+
+func (s kvStore) GeoAddCtx(ctx context.Context, key string, geoLocation ...*redis.GeoLocation) (
+	int64, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.GeoAddCtx(ctx, key, geoLocation...)
+}
+
+func (s kvStore) GeoDist(key, member1, member2, unit string) (float64, error) {
+	return s.GeoDistCtx(context.Background(), key, member1, member2, unit)
+}
+
+func (s kvStore) GeoDistCtx(ctx context.Context, key, member1, member2, unit string) (float64, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.GeoDistCtx(ctx, key, member1, member2, unit)
+}
+
+func (s kvStore) GeoHash(key string, members ...string) ([]string, error) {
+	return s.GeoHashCtx(context.Background(), key, members...)
+}
+
+func (s kvStore) GeoHashCtx(ctx context.Context, key string, members ...string) ([]string, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.GeoHashCtx(ctx, key, members...)
+}
+
+func (s kvStore) GeoPos(key string, members ...string) ([]*redis.GeoPos, error) {
+	return s.GeoPosCtx(context.Background(), key, members...)
+}
+
+func (s kvStore) GeoPosCtx(ctx context.Context, key string, members ...string) ([]*redis.GeoPos, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.GeoPosCtx(ctx, key, members...)
+}
+
+func (s kvStore) GeoRadius(key string, longitude, latitude float64, query *redis.GeoRadiusQuery) (
+	[]redis.GeoLocation, error) {
+	return s.GeoRadiusCtx(context.Background(), key, longitude, latitude, query)
+}
+
+func (s kvStore) GeoRadiusCtx(ctx context.Context, key string, longitude, latitude float64,
+	query *redis.GeoRadiusQuery) ([]redis.GeoLocation, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.GeoRadiusCtx(ctx, key, longitude, latitude, query)
+}
+
+func (s kvStore) GeoRadiusByMember(key, member string, query *redis.GeoRadiusQuery) (
+	[]redis.GeoLocation, error) {
+	return s.GeoRadiusByMemberCtx(context.Background(), key, member, query)
+}
+
+func (s kvStore) GeoRadiusByMemberCtx(ctx context.Context, key, member string, query *redis.GeoRadiusQuery) (
+	[]redis.GeoLocation, error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.GeoRadiusByMemberCtx(ctx, key, member, query)
+}
+
 func (s kvStore) Get(key string) (string, error) {
 	return s.GetCtx(context.Background(), key)
 }
@@ -542,6 +929,21 @@ func (s kvStore) HMGetCtx(ctx context.Context, key string, fields ...string) ([]
 	return node.HMGetCtx(ctx, key, fields...)
 }
 
+func (s kvStore) HScan(key string, cursor uint64, match string, count int64) (
+	keys []string, cur uint64, err error) {
+	return s.HScanCtx(context.Background(), key, cursor, match, count)
+}
+
+func (s kvStore) HScanCtx(ctx context.Context, key string, cursor uint64, match string, count int64) (
+	keys []string, cur uint64, err error) {
+	node, err := s.getRedis(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return node.HScanCtx(ctx, key, cursor, match, count)
+}
+
 func (s kvStore) HSet(key, field, value string) error {
 	return s.HSetCtx(context.Background(), key, field, value)
 }
@@ -555,11 +957,11 @@ func (s kvStore) HSetCtx(ctx context.Context, key, field, value string) error {
 	return node.HSetCtx(ctx, key, field, value)
 }
 
-func (s kvStore) HSetNx(key, field, value string) (bool, error) {
-	return s.HSetNxCtx(context.Background(), key, field, value)
+func (s kvStore) HSetNX(key, field, value string) (bool, error) {
+	return s.HSetNXCtx(context.Background(), key, field, value)
 }
 
-func (s kvStore) HSetNxCtx(ctx context.Context, key, field, value string) (bool, error) {
+func (s kvStore) HSetNXCtx(ctx context.Context, key, field, value string) (bool, error) {
 	node, err := s.getRedis(key)
 	if err != nil {
 		return false, err
@@ -568,6 +970,14 @@ func (s kvStore) HSetNxCtx(ctx context.Context, key, field, value string) (bool,
 	return node.HSetNXCtx(ctx, key, field, value)
 }
 
+func (s kvStore) HSetNx(key, field, value string) (bool, error) {
+	return s.HSetNXCtx(context.Background(), key, field, value)
+}
+
+func (s kvStore) HSetNxCtx(ctx context.Context, key, field, value string) (bool, error) {
+	return s.HSetNXCtx(ctx, key, field, value)
+}
+
 func (s kvStore) HMSet(key string, fieldsAndValues map[string]string) error {
 	return s.HMSetCtx(context.Background(), key, fieldsAndValues)
 }
@@ -620,6 +1030,28 @@ func (s kvStore) IncrByCtx(ctx context.Context, key string, increment int64) (in
 	return node.IncrByCtx(ctx, key, increment)
 }
 
+func (s kvStore) Keys(pattern string) ([]string, error) {
+	return s.KeysCtx(context.Background(), pattern)
+}
+
+func (s kvStore) KeysCtx(ctx context.Context, pattern string) ([]string, error) {
+	if len(s.nodes) == 0 {
+		return nil, ErrNoRedisNode
+	}
+
+	var val []string
+	for _, node := range s.nodes {
+		keys, err := node.KeysCtx(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		val = append(val, keys...)
+	}
+
+	return val, nil
+}
+
 func (s kvStore) LLen(key string) (int, error) {
 	return s.LLenCtx(context.Background(), key)
 }
@@ -711,6 +1143,36 @@ func (s kvStore) LTrimCtx(ctx context.Context, key string, start, stop int64) er
 	return node.LTrimCtx(ctx, key, start, stop)
 }
 
+func (s kvStore) MGet(keys ...string) ([]string, error) {
+	return s.MGetCtx(context.Background(), keys...)
+}
+
+func (s kvStore) MGetCtx(ctx context.Context, keys ...string) ([]string, error) {
+	nodes, groups, err := s.groupKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(keys))
+	for i, node := range nodes {
+		vals, err := node.MGetCtx(ctx, groups[i]...)
+		if err != nil {
+			return nil, err
+		}
+
+		for j, key := range groups[i] {
+			values[key] = vals[j]
+		}
+	}
+
+	val := make([]string, len(keys))
+	for i, key := range keys {
+		val[i] = values[key]
+	}
+
+	return val, nil
+}
+
 func (s kvStore) Persist(key string) (bool, error) {
 	return s.PersistCtx(context.Background(), key)
 }
@@ -750,6 +1212,50 @@ func (s kvStore) PFCountCtx(ctx context.Context, key string) (int64, error) {
 	return node.PFCountCtx(ctx, key)
 }
 
+func (s kvStore) PFMerge(dest string, keys ...string) error {
+	return s.PFMergeCtx(context.Background(), dest, keys...)
+}
+
+func (s kvStore) PFMergeCtx(ctx context.Context, dest string, keys ...string) error {
+	node, err := s.getKeysRedis(append([]string{dest}, keys...))
+	if err != nil {
+		return err
+	}
+
+	return node.PFMergeCtx(ctx, dest, keys...)
+}
+
+func (s kvStore) Ping() bool {
+	return s.PingCtx(context.Background())
+}
+
+func (s kvStore) PingCtx(ctx context.Context) bool {
+	if len(s.nodes) == 0 {
+		return false
+	}
+
+	for _, node := range s.nodes {
+		if !node.PingCtx(ctx) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s kvStore) Pipelined(fn func(redis.Pipeliner) error, keys ...string) error {
+	return s.PipelinedCtx(context.Background(), fn, keys...)
+}
+
+func (s kvStore) PipelinedCtx(ctx context.Context, fn func(redis.Pipeliner) error, keys ...string) error {
+	node, err := s.getKeysRedis(keys)
+	if err != nil {
+		return err
+	}
+
+	return node.PipelinedCtx(ctx, fn)
+}
+
 func (s kvStore) RPop(key string) (string, error) {
 	return s.RPopCtx(context.Background(), key)
 
@@ -803,6 +1309,94 @@ func (s kvStore) SCardCtx(ctx context.Context, key string) (int64, error) {
 	return node.SCardCtx(ctx, key)
 }
 
+func (s kvStore) Scan(cursor uint64, match string, count int64) (keys []string, cur uint64, err error) {
+	return s.ScanCtx(context.Background(), cursor, match, count)
+}
+
+func (s kvStore) ScanCtx(ctx context.Context, cursor uint64, match string, count int64) (
+	keys []string, cur uint64, err error) {
+	index := int(cursor >> scanNodeShift)
+	if index >= len(s.nodes) {
+		return nil, 0, ErrNoRedisNode
+	}
+
+	keys, cur, err = s.nodes[index].ScanCtx(ctx, cursor&scanCursorMask, match, count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if cur != 0 {
+		return keys, uint64(index)<<scanNodeShift | cur, nil
+	}
+	// 当前节点迭代完毕，继续迭代下一个节点。
+	if index+1 < len(s.nodes) {
+		return keys, uint64(index+1) << scanNodeShift, nil
+	}
+
+	return keys, 0, nil
+}
+
+func (s kvStore) ScriptLoad(script string) (string, error) {
+	return s.ScriptLoadCtx(context.Background(), script)
+}
+
+func (s kvStore) ScriptLoadCtx(ctx context.Context, script string) (string, error) {
+	if len(s.nodes) == 0 {
+		return "", ErrNoRedisNode
+	}
+
+	var sha string
+	for _, node := range s.nodes {
+		var err error
+		if sha, err = node.ScriptLoadCtx(ctx, script); err != nil {
+			return "", err
+		}
+	}
+
+	return sha, nil
+}
+
+func (s kvStore) SDiff(keys ...string) ([]string, error) {
+	return s.SDiffCtx(context.Background(), keys...)
+}
+
+func (s kvStore) SDiffCtx(ctx context.Context, keys ...string) ([]string, error) {
+	nodes, groups, err := s.groupKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// 首组包含第一个集合，先在其节点上求差集，再减去其余节点上的并集。
+	val, err := nodes[0].SDiffCtx(ctx, groups[0]...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 1; i < len(nodes) && len(val) > 0; i++ {
+		others, err := nodes[i].SUnionCtx(ctx, groups[i]...)
+		if err != nil {
+			return nil, err
+		}
+
+		val = filterMembers(val, others, false)
+	}
+
+	return val, nil
+}
+
+func (s kvStore) SDiffStore(destination string, keys ...string) (int, error) {
+	return s.SDiffStoreCtx(context.Background(), destination, keys...)
+}
+
+func (s kvStore) SDiffStoreCtx(ctx context.Context, destination string, keys ...string) (int, error) {
+	node, err := s.getKeysRedis(append([]string{destination}, keys...))
+	if err != nil {
+		return 0, err
+	}
+
+	return node.SDiffStoreCtx(ctx, destination, keys...)
+}
+
 func (s kvStore) Set(key, value string) error {
 	return s.SetCtx(context.Background(), key, value)
 }
@@ -894,6 +1488,46 @@ func (s kvStore) GetBitCtx(ctx context.Context, key string, offset int64) (int,
 	return node.GetBitCtx(ctx, key, offset)
 }
 
+func (s kvStore) SInter(keys ...string) ([]string, error) {
+	return s.SInterCtx(context.Background(), keys...)
+}
+
+func (s kvStore) SInterCtx(ctx context.Context, keys ...string) ([]string, error) {
+	nodes, groups, err := s.groupKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := nodes[0].SInterCtx(ctx, groups[0]...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 1; i < len(nodes) && len(val) > 0; i++ {
+		others, err := nodes[i].SInterCtx(ctx, groups[i]...)
+		if err != nil {
+			return nil, err
+		}
+
+		val = filterMembers(val, others, true)
+	}
+
+	return val, nil
+}
+
+func (s kvStore) SInterStore(destination string, keys ...string) (int, error) {
+	return s.SInterStoreCtx(context.Background(), destination, keys...)
+}
+
+func (s kvStore) SInterStoreCtx(ctx context.Context, destination string, keys ...string) (int, error) {
+	node, err := s.getKeysRedis(append([]string{destination}, keys...))
+	if err != nil {
+		return 0, err
+	}
+
+	return node.SInterStoreCtx(ctx, destination, keys...)
+}
+
 func (s kvStore) SIsMember(key string, value any) (bool, error) {
 	return s.SIsMemberCtx(context.Background(), key, value)
 }
@@ -974,6 +1608,48 @@ func (s kvStore) SScanCtx(ctx context.Context, key string, cursor uint64, match
 	return node.SScanCtx(ctx, key, cursor, match, count)
 }
 
+func (s kvStore) SUnion(keys ...string) ([]string, error) {
+	return s.SUnionCtx(context.Background(), keys...)
+}
+
+func (s kvStore) SUnionCtx(ctx context.Context, keys ...string) ([]string, error) {
+	nodes, groups, err := s.groupKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var val []string
+	seen := make(map[string]lang.PlaceholderType)
+	for i, node := range nodes {
+		members, err := node.SUnionCtx(ctx, groups[i]...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if _, ok := seen[member]; !ok {
+				seen[member] = lang.Placeholder
+				val = append(val, member)
+			}
+		}
+	}
+
+	return val, nil
+}
+
+func (s kvStore) SUnionStore(destination string, keys ...string) (int, error) {
+	return s.SUnionStoreCtx(context.Background(), destination, keys...)
+}
+
+func (s kvStore) SUnionStoreCtx(ctx context.Context, destination string, keys ...string) (int, error) {
+	node, err := s.getKeysRedis(append([]string{destination}, keys...))
+	if err != nil {
+		return 0, err
+	}
+
+	return node.SUnionStoreCtx(ctx, destination, keys...)
+}
+
 func (s kvStore) TTL(key string) (int, error) {
 	return s.TTLCtx(context.Background(), key)
 }
@@ -987,6 +1663,249 @@ func (s kvStore) TTLCtx(ctx context.Context, key string) (int, error) {
 	return node.TTLCtx(ctx, key)
 }
 
+func (s kvStore) TxPipelined(fn func(redis.Pipeliner) error, keys ...string) error {
+	return s.TxPipelinedCtx(context.Background(), fn, keys...)
+}
+
+func (s kvStore) TxPipelinedCtx(ctx context.Context, fn func(redis.Pipeliner) error, keys ...string) error {
+	node, err := s.getKeysRedis(keys)
+	if err != nil {
+		return err
+	}
+
+	return node.TxPipelinedCtx(ctx, fn)
+}
+
+func (s kvStore) Watch(ctx context.Context, fn func(tx *redis.Tx) error, keys ...string) error {
+	node, err := s.getKeysRedis(keys)
+	if err != nil {
+		return err
+	}
+
+	return node.Watch(ctx, fn, keys...)
+}
+
+func (s kvStore) XAck(stream, group string, ids ...string) (int64, error) {
+	return s.XAckCtx(context.Background(), stream, group, ids...)
+}
+
+func (s kvStore) XAckCtx(ctx context.Context, stream, group string, ids ...string) (int64, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.XAckCtx(ctx, stream, group, ids...)
+}
+
+func (s kvStore) XAdd(a *redis.XAddArgs) (string, error) {
+	return s.XAddCtx(context.Background(), a)
+}
+
+func (s kvStore) XAddCtx(ctx context.Context, a *redis.XAddArgs) (string, error) {
+	node, err := s.getRedis(a.Stream)
+	if err != nil {
+		return "", err
+	}
+
+	return node.XAddCtx(ctx, a)
+}
+
+func (s kvStore) XAutoClaim(a *redis.XAutoClaimArgs) ([]redis.XMessage, string, error) {
+	return s.XAutoClaimCtx(context.Background(), a)
+}
+
+func (s kvStore) XAutoClaimCtx(ctx context.Context, a *redis.XAutoClaimArgs) ([]redis.XMessage, string, error) {
+	node, err := s.getRedis(a.Stream)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return node.XAutoClaimCtx(ctx, a)
+}
+
+func (s kvStore) XClaim(a *redis.XClaimArgs) ([]redis.XMessage, error) {
+	return s.XClaimCtx(context.Background(), a)
+}
+
+func (s kvStore) XClaimCtx(ctx context.Context, a *redis.XClaimArgs) ([]redis.XMessage, error) {
+	node, err := s.getRedis(a.Stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XClaimCtx(ctx, a)
+}
+
+func (s kvStore) XGroupCreate(stream, group, start string) error {
+	return s.XGroupCreateCtx(context.Background(), stream, group, start)
+}
+
+func (s kvStore) XGroupCreateCtx(ctx context.Context, stream, group, start string) error {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return err
+	}
+
+	return node.XGroupCreateCtx(ctx, stream, group, start)
+}
+
+func (s kvStore) XGroupCreateMkStream(stream, group, start string) error {
+	return s.XGroupCreateMkStreamCtx(context.Background(), stream, group, start)
+}
+
+func (s kvStore) XGroupCreateMkStreamCtx(ctx context.Context, stream, group, start string) error {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return err
+	}
+
+	return node.XGroupCreateMkStreamCtx(ctx, stream, group, start)
+}
+
+func (s kvStore) XGroupDestroy(stream, group string) (int64, error) {
+	return s.XGroupDestroyCtx(context.Background(), stream, group)
+}
+
+func (s kvStore) XGroupDestroyCtx(ctx context.Context, stream, group string) (int64, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.XGroupDestroyCtx(ctx, stream, group)
+}
+
+func (s kvStore) XInfoConsumers(stream, group string) ([]redis.XInfoConsumer, error) {
+	return s.XInfoConsumersCtx(context.Background(), stream, group)
+}
+
+func (s kvStore) XInfoConsumersCtx(ctx context.Context, stream, group string) ([]redis.XInfoConsumer, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XInfoConsumersCtx(ctx, stream, group)
+}
+
+func (s kvStore) XInfoGroups(stream string) ([]redis.XInfoGroup, error) {
+	return s.XInfoGroupsCtx(context.Background(), stream)
+}
+
+func (s kvStore) XInfoGroupsCtx(ctx context.Context, stream string) ([]redis.XInfoGroup, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XInfoGroupsCtx(ctx, stream)
+}
+
+func (s kvStore) XInfoStream(stream string) (*redis.XInfoStream, error) {
+	return s.XInfoStreamCtx(context.Background(), stream)
+}
+
+func (s kvStore) XInfoStreamCtx(ctx context.Context, stream string) (*redis.XInfoStream, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XInfoStreamCtx(ctx, stream)
+}
+
+func (s kvStore) XLen(stream string) (int64, error) {
+	return s.XLenCtx(context.Background(), stream)
+}
+
+func (s kvStore) XLenCtx(ctx context.Context, stream string) (int64, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.XLenCtx(ctx, stream)
+}
+
+func (s kvStore) XPending(stream, group string) (*redis.XPending, error) {
+	return s.XPendingCtx(context.Background(), stream, group)
+}
+
+func (s kvStore) XPendingCtx(ctx context.Context, stream, group string) (*redis.XPending, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XPendingCtx(ctx, stream, group)
+}
+
+func (s kvStore) XPendingExt(a *redis.XPendingExtArgs) ([]redis.XPendingExt, error) {
+	return s.XPendingExtCtx(context.Background(), a)
+}
+
+func (s kvStore) XPendingExtCtx(ctx context.Context, a *redis.XPendingExtArgs) ([]redis.XPendingExt, error) {
+	node, err := s.getRedis(a.Stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XPendingExtCtx(ctx, a)
+}
+
+func (s kvStore) XRead(a *redis.XReadArgs) ([]redis.XStream, error) {
+	return s.XReadCtx(context.Background(), a)
+}
+
+func (s kvStore) XReadCtx(ctx context.Context, a *redis.XReadArgs) ([]redis.XStream, error) {
+	node, err := s.getStreamsRedis(a.Streams)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XReadCtx(ctx, a)
+}
+
+func (s kvStore) XReadGroup(a *redis.XReadGroupArgs) ([]redis.XStream, error) {
+	return s.XReadGroupCtx(context.Background(), a)
+}
+
+func (s kvStore) XReadGroupCtx(ctx context.Context, a *redis.XReadGroupArgs) ([]redis.XStream, error) {
+	node, err := s.getStreamsRedis(a.Streams)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XReadGroupCtx(ctx, a)
+}
+
+func (s kvStore) XTrimMaxLen(stream string, maxLen int64) (int64, error) {
+	return s.XTrimMaxLenCtx(context.Background(), stream, maxLen)
+}
+
+func (s kvStore) XTrimMaxLenCtx(ctx context.Context, stream string, maxLen int64) (int64, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.XTrimMaxLenCtx(ctx, stream, maxLen)
+}
+
+func (s kvStore) XTrimMinID(stream, minID string) (int64, error) {
+	return s.XTrimMinIDCtx(context.Background(), stream, minID)
+}
+
+func (s kvStore) XTrimMinIDCtx(ctx context.Context, stream, minID string) (int64, error) {
+	node, err := s.getRedis(stream)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.XTrimMinIDCtx(ctx, stream, minID)
+}
+
 func (s kvStore) ZAdd(key string, score int64, value string) (bool, error) {
 	return s.ZAddCtx(context.Background(), key, score, value)
 }
@@ -1246,6 +2165,19 @@ func (s kvStore) ZScoreCtx(ctx context.Context, key, value string) (int64, error
 	return node.ZScoreCtx(ctx, key, value)
 }
 
+func (s kvStore) ZUnionStore(dest string, store *redis.ZStore) (int64, error) {
+	return s.ZUnionStoreCtx(context.Background(), dest, store)
+}
+
+func (s kvStore) ZUnionStoreCtx(ctx context.Context, dest string, store *redis.ZStore) (int64, error) {
+	node, err := s.getKeysRedis(append([]string{dest}, store.Keys...))
+	if err != nil {
+		return 0, err
+	}
+
+	return node.ZUnionStoreCtx(ctx, dest, store)
+}
+
 func (s kvStore) getRedis(key string) (*redis.Redis, error) {
 	node, ok := s.dispatcher.Get(key)
 	if !ok {
@@ -1254,3 +2186,77 @@ func (s kvStore) getRedis(key string) (*redis.Redis, error) {
 
 	return node.(*redis.Redis), nil
 }
+
+// getKeysRedis 返回所有键所在的节点，键分布于多个节点时返回 ErrCrossNode。
+func (s kvStore) getKeysRedis(keys []string) (*redis.Redis, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoRedisNode
+	}
+
+	node, err := s.getRedis(keys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys[1:] {
+		other, err := s.getRedis(key)
+		if err != nil {
+			return nil, err
+		}
+		if other != node {
+			return nil, ErrCrossNode
+		}
+	}
+
+	return node, nil
+}
+
+// groupKeys 按所在节点对键分组，节点及组内的键均按首次出现的顺序排列。
+func (s kvStore) groupKeys(keys []string) ([]*redis.Redis, [][]string, error) {
+	if len(keys) == 0 {
+		return nil, nil, ErrNoRedisNode
+	}
+
+	var nodes []*redis.Redis
+	var groups [][]string
+	indexes := make(map[*redis.Redis]int)
+	for _, key := range keys {
+		node, err := s.getRedis(key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		i, ok := indexes[node]
+		if !ok {
+			i = len(nodes)
+			indexes[node] = i
+			nodes = append(nodes, node)
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], key)
+	}
+
+	return nodes, groups, nil
+}
+
+// getStreamsRedis 返回 streams（依次为流名称及起始 ID）中所有流所在的节点，流分布于多个节点时返回 ErrCrossNode。
+func (s kvStore) getStreamsRedis(streams []string) (*redis.Redis, error) {
+	return s.getKeysRedis(streams[:len(streams)/2])
+}
+
+// filterMembers 返回 members 中存在（keep 为 true）或不存在（keep 为 false）于 others 的成员。
+func filterMembers(members, others []string, keep bool) []string {
+	set := make(map[string]lang.PlaceholderType, len(others))
+	for _, member := range others {
+		set[member] = lang.Placeholder
+	}
+
+	val := make([]string, 0, len(members))
+	for _, member := range members {
+		if _, ok := set[member]; ok == keep {
+			val = append(val, member)
+		}
+	}
+
+	return val
+}
@@ -1,12 +1,14 @@
 package kv
 
 import (
+	"context"
 	"github.com/alicebob/miniredis/v2"
 	"github.com/gotid/god/lib/hash"
 	"github.com/gotid/god/lib/store/cache"
 	"github.com/gotid/god/lib/store/redis"
 	"github.com/gotid/god/lib/stringx"
 	"github.com/stretchr/testify/assert"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -133,6 +135,24 @@ func TestRedis_Hsetnx(t *testing.T) {
 	})
 }
 
+func TestRedis_HSetNX(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, err := store.HSetNX("a", "dd", "ddd")
+	assert.NotNil(t, err)
+
+	runOnCluster(func(client Store) {
+		ok, err := client.HSetNX("a", "aa", "aaa")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = client.HSetNXCtx(context.Background(), "a", "aa", "bbb")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		val, err := client.HGet("a", "aa")
+		assert.Nil(t, err)
+		assert.Equal(t, "aaa", val)
+	})
+}
+
 func TestRedis_HdelHlen(t *testing.T) {
 	store := kvStore{dispatcher: hash.NewConsistentHash()}
 	_, err := store.HDel("a", "aa")
@@ -733,6 +753,392 @@ func TestRedis_HyperLogLog(t *testing.T) {
 	})
 }
 
+func TestRedis_Stream(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, err := store.XAdd(&redis.XAddArgs{Stream: "s", Values: []string{"k", "v"}})
+	assert.NotNil(t, err)
+	_, err = store.XRead(&redis.XReadArgs{Streams: []string{"s", "0"}})
+	assert.Equal(t, ErrNoRedisNode, err)
+
+	runOnCluster(func(cluster Store) {
+		assert.Nil(t, cluster.XGroupCreateMkStream("s", "g", "0"))
+		id, err := cluster.XAdd(&redis.XAddArgs{Stream: "s", ID: "1-0", Values: []string{"k", "v"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "1-0", id)
+		n, err := cluster.XLen("s")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		streams, err := cluster.XRead(&redis.XReadArgs{Streams: []string{"s", "0"}, Block: -1})
+		assert.Nil(t, err)
+		assert.Len(t, streams[0].Messages, 1)
+
+		streams, err = cluster.XReadGroup(&redis.XReadGroupArgs{
+			Group:    "g",
+			Consumer: "c",
+			Streams:  []string{"s", ">"},
+			Block:    -1,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, streams[0].Messages, 1)
+		pending, err := cluster.XPending("s", "g")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), pending.Count)
+		n, err = cluster.XAck("s", "g", "1-0")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+		n, err = cluster.XGroupDestroy("s", "g")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		// 找到与 s 位于不同节点的流。
+		store := cluster.(kvStore)
+		node, _ := store.getRedis("s")
+		var other string
+		for i := 0; ; i++ {
+			other = "s" + strconv.Itoa(i)
+			if n, _ := store.getRedis(other); n != node {
+				break
+			}
+		}
+		_, err = cluster.XRead(&redis.XReadArgs{Streams: []string{"s", other, "0", "0"}, Block: -1})
+		assert.Equal(t, ErrCrossNode, err)
+	})
+}
+
+func TestRedis_Tx(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	assert.Equal(t, ErrNoRedisNode, store.TxPipelined(func(pipe redis.Pipeliner) error {
+		return nil
+	}, "key"))
+
+	runOnCluster(func(cluster Store) {
+		ctx := context.Background()
+		assert.Equal(t, ErrNoRedisNode, cluster.TxPipelined(func(pipe redis.Pipeliner) error {
+			return nil
+		}))
+
+		err := cluster.TxPipelined(func(pipe redis.Pipeliner) error {
+			pipe.Incr(ctx, "key")
+			pipe.IncrBy(ctx, "key", 2)
+			return nil
+		}, "key")
+		assert.Nil(t, err)
+
+		err = cluster.Watch(ctx, func(tx *redis.Tx) error {
+			n, err := tx.Get(ctx, "key").Int()
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, "key", n*2, 0)
+				return nil
+			})
+			return err
+		}, "key")
+		assert.Nil(t, err)
+		val, err := cluster.Get("key")
+		assert.Nil(t, err)
+		assert.Equal(t, "6", val)
+
+		_, other := splitKeys(t, cluster, "key")
+		assert.Equal(t, ErrCrossNode, cluster.TxPipelined(func(pipe redis.Pipeliner) error {
+			return nil
+		}, "key", other))
+		assert.Equal(t, ErrCrossNode, cluster.Watch(ctx, func(tx *redis.Tx) error {
+			return nil
+		}, "key", other))
+	})
+}
+
+func TestRedis_Bit(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, err := store.BitCount("key", 0, -1)
+	assert.NotNil(t, err)
+	_, err = store.BitPos("key", 1, 0, -1)
+	assert.NotNil(t, err)
+
+	runOnCluster(func(cluster Store) {
+		same, other := splitKeys(t, cluster, "key")
+		_, err := cluster.SetBit("key", 1, 1)
+		assert.Nil(t, err)
+		_, err = cluster.SetBit("key", 3, 1)
+		assert.Nil(t, err)
+		_, err = cluster.SetBit(same, 3, 1)
+		assert.Nil(t, err)
+
+		n, err := cluster.BitCount("key", 0, -1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), n)
+		n, err = cluster.BitPos("key", 1, 0, -1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		_, err = cluster.BitOpOr(same, "key", same)
+		assert.Nil(t, err)
+		n, err = cluster.BitCount(same, 0, -1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), n)
+		_, err = cluster.BitOpAnd(same, "key", same)
+		assert.Nil(t, err)
+		_, err = cluster.BitOpXor(same, "key", same)
+		assert.Nil(t, err)
+		n, err = cluster.BitCount(same, 0, -1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), n)
+		_, err = cluster.BitOpNot(same, "key")
+		assert.Nil(t, err)
+
+		_, err = cluster.BitOpAnd("key", same, other)
+		assert.Equal(t, ErrCrossNode, err)
+		_, err = cluster.BitOpNot(other, "key")
+		assert.Equal(t, ErrCrossNode, err)
+	})
+}
+
+func TestRedis_Geo(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, err := store.GeoAdd("geo", &redis.GeoLocation{Name: "a"})
+	assert.NotNil(t, err)
+
+	runOnCluster(func(cluster Store) {
+		n, err := cluster.GeoAdd("geo", &redis.GeoLocation{
+			Name:      "palermo",
+			Longitude: 13.361389,
+			Latitude:  38.115556,
+		}, &redis.GeoLocation{
+			Name:      "catania",
+			Longitude: 15.087269,
+			Latitude:  37.502669,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), n)
+
+		dist, err := cluster.GeoDist("geo", "palermo", "catania", "km")
+		assert.Nil(t, err)
+		assert.InDelta(t, 166.27, dist, 0.01)
+		// miniredis 未实现 GEOHASH，仅验证请求已路由至节点。
+		_, err = cluster.GeoHash("geo", "palermo")
+		assert.NotEqual(t, ErrNoRedisNode, err)
+		pos, err := cluster.GeoPos("geo", "palermo", "none")
+		assert.Nil(t, err)
+		assert.InDelta(t, 13.361389, pos[0].Longitude, 0.0001)
+		assert.Nil(t, pos[1])
+
+		locations, err := cluster.GeoRadius("geo", 15, 37, &redis.GeoRadiusQuery{
+			Radius: 200,
+			Unit:   "km",
+			Sort:   "ASC",
+		})
+		assert.Nil(t, err)
+		assert.Len(t, locations, 2)
+		assert.Equal(t, "catania", locations[0].Name)
+		locations, err = cluster.GeoRadiusByMember("geo", "palermo", &redis.GeoRadiusQuery{
+			Radius: 100,
+			Unit:   "km",
+		})
+		assert.Nil(t, err)
+		assert.Len(t, locations, 1)
+	})
+}
+
+func TestRedis_MGet(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, err := store.MGet("a", "b")
+	assert.NotNil(t, err)
+
+	runOnCluster(func(cluster Store) {
+		_, err := cluster.MGet()
+		assert.Equal(t, ErrNoRedisNode, err)
+
+		same, other := splitKeys(t, cluster, "key")
+		assert.Nil(t, cluster.Set("key", "1"))
+		assert.Nil(t, cluster.Set(same, "2"))
+		assert.Nil(t, cluster.Set(other, "3"))
+		vals, err := cluster.MGet(other, "key", "none", same, other)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"3", "1", "", "2", "3"}, vals)
+	})
+}
+
+func TestRedis_SetOperations(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, err := store.SUnion("a", "b")
+	assert.NotNil(t, err)
+
+	runOnCluster(func(cluster Store) {
+		same, other := splitKeys(t, cluster, "key")
+		_, err := cluster.SAdd("key", "a", "b", "c", "d")
+		assert.Nil(t, err)
+		_, err = cluster.SAdd(same, "b", "c", "e")
+		assert.Nil(t, err)
+		_, err = cluster.SAdd(other, "c", "f")
+		assert.Nil(t, err)
+
+		vals, err := cluster.SUnion("key", same, other)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e", "f"}, vals)
+		vals, err = cluster.SInter("key", same)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"b", "c"}, vals)
+		vals, err = cluster.SInter("key", same, other)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"c"}, vals)
+		vals, err = cluster.SDiff("key", other)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"a", "b", "d"}, vals)
+		vals, err = cluster.SDiff(other, same, "key")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"f"}, vals)
+		vals, err = cluster.SDiff("key", same, other)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"a", "d"}, vals)
+
+		n, err := cluster.SUnionStore("key", "key", same)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, n)
+		n, err = cluster.SInterStore("key", "key", same)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, n)
+		n, err = cluster.SDiffStore("key", "key", same)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, n)
+		_, err = cluster.SUnionStore("key", same, other)
+		assert.Equal(t, ErrCrossNode, err)
+		_, err = cluster.SInterStore(other, "key")
+		assert.Equal(t, ErrCrossNode, err)
+		_, err = cluster.SDiffStore("key", other)
+		assert.Equal(t, ErrCrossNode, err)
+	})
+}
+
+func TestRedis_Scan(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, _, err := store.Scan(0, "*", 10)
+	assert.Equal(t, ErrNoRedisNode, err)
+	_, err = store.Keys("*")
+	assert.Equal(t, ErrNoRedisNode, err)
+	assert.False(t, store.Ping())
+
+	runOnCluster(func(cluster Store) {
+		assert.True(t, cluster.Ping())
+
+		var expected []string
+		for i := 0; i < 20; i++ {
+			key := "key" + strconv.Itoa(i)
+			expected = append(expected, key)
+			assert.Nil(t, cluster.Set(key, "v"))
+		}
+		keys, err := cluster.Keys("key*")
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, expected, keys)
+
+		var cursor uint64
+		keys = nil
+		for {
+			vals, cur, err := cluster.Scan(cursor, "key*", 5)
+			assert.Nil(t, err)
+			keys = append(keys, vals...)
+			if cur == 0 {
+				break
+			}
+			cursor = cur
+		}
+		assert.ElementsMatch(t, expected, keys)
+
+		assert.Nil(t, cluster.HSet("hash", "field", "v"))
+		fields, _, err := cluster.HScan("hash", 0, "*", 10)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"field", "v"}, fields)
+	})
+}
+
+func TestRedis_Script(t *testing.T) {
+	store := kvStore{dispatcher: hash.NewConsistentHash()}
+	_, err := store.ScriptLoad("return 1")
+	assert.Equal(t, ErrNoRedisNode, err)
+	_, err = store.EvalSha("sha", "key")
+	assert.NotNil(t, err)
+
+	runOnCluster(func(cluster Store) {
+		sha, err := cluster.ScriptLoad(`return redis.call("GET", KEYS[1])`)
+		assert.Nil(t, err)
+
+		// 脚本已加载至所有节点，任意键均可执行。
+		_, other := splitKeys(t, cluster, "key")
+		for _, key := range []string{"key", other} {
+			assert.Nil(t, cluster.Set(key, key))
+			val, err := cluster.EvalSha(sha, key)
+			assert.Nil(t, err)
+			assert.Equal(t, key, val)
+		}
+	})
+}
+
+func TestRedis_MultiKeyRouting(t *testing.T) {
+	runOnCluster(func(cluster Store) {
+		ctx := context.Background()
+		same, other := splitKeys(t, cluster, "key")
+
+		_, err := cluster.PFAdd("key", "a", "b")
+		assert.Nil(t, err)
+		_, err = cluster.PFAdd(same, "b", "c")
+		assert.Nil(t, err)
+		assert.Nil(t, cluster.PFMerge("key", same))
+		n, err := cluster.PFCount("key")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, ErrCrossNode, cluster.PFMerge("key", other))
+
+		_, err = cluster.Del("key", same)
+		assert.Nil(t, err)
+		_, err = cluster.ZAdd(same, 1, "a")
+		assert.Nil(t, err)
+		n, err = cluster.ZUnionStore("key", &redis.ZStore{Keys: []string{same}})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+		_, err = cluster.ZUnionStore("key", &redis.ZStore{Keys: []string{same, other}})
+		assert.Equal(t, ErrCrossNode, err)
+
+		err = cluster.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, "key", "1", 0)
+			pipe.Set(ctx, same, "2", 0)
+			return nil
+		}, "key", same)
+		assert.Nil(t, err)
+		val, err := cluster.Get(same)
+		assert.Nil(t, err)
+		assert.Equal(t, "2", val)
+		assert.Equal(t, ErrCrossNode, cluster.Pipelined(func(pipe redis.Pipeliner) error {
+			return nil
+		}, "key", other))
+	})
+}
+
+// splitKeys 返回与 key 位于同一节点的另一个键，以及位于其他节点的键。
+func splitKeys(t *testing.T, cluster Store, key string) (same, other string) {
+	node := mustGetRedis(t, cluster, key)
+	for i := 0; len(same) == 0 || len(other) == 0; i++ {
+		k := key + strconv.Itoa(i)
+		if mustGetRedis(t, cluster, k) == node {
+			if len(same) == 0 {
+				same = k
+			}
+		} else if len(other) == 0 {
+			other = k
+		}
+	}
+
+	return
+}
+
+func mustGetRedis(t *testing.T, cluster Store, key string) *redis.Redis {
+	node, err := cluster.(kvStore).getRedis(key)
+	assert.Nil(t, err)
+	return node
+}
+
 func runOnCluster(fn func(cluster Store)) {
 	s1.FlushAll()
 	s2.FlushAll()
@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gotid/god/lib/hash"
+	"github.com/gotid/god/lib/logx"
+	"time"
+)
+
+const (
+	lockPrefix        = "god_migrate:"
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+// withLock 在数据库锁的保护下执行 fn，演练模式下不加锁。
+// MySQL 使用 GET_LOCK，PostgreSQL 使用咨询锁，二者均为会话级别，故须独占一个连接。
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if m.dryRun {
+		return fn()
+	}
+
+	db, err := m.conn.RawDB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.postgres {
+		err = m.lockPostgres(ctx, conn)
+	} else {
+		err = m.lockMySQL(ctx, conn)
+	}
+	if err != nil {
+		return err
+	}
+	defer m.unlock(conn)
+
+	return fn()
+}
+
+func (m *Migrator) lockMySQL(ctx context.Context, conn *sql.Conn) error {
+	var locked sql.NullInt64
+	seconds := int64(m.lockTimeout / time.Second)
+	if err := conn.QueryRowContext(ctx, "select get_lock(?, ?)", m.lockName(), seconds).
+		Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return ErrLocked
+	}
+
+	return nil
+}
+
+// lockPostgres 轮询 pg_try_advisory_lock 直至成功或超时，避免无限期阻塞。
+func (m *Migrator) lockPostgres(ctx context.Context, conn *sql.Conn) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "select pg_try_advisory_lock($1)", m.lockKey()).
+			Scan(&locked); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// unlock 释放锁，不受调用方 ctx 取消的影响。
+func (m *Migrator) unlock(conn *sql.Conn) {
+	var err error
+	if m.postgres {
+		_, err = conn.ExecContext(context.Background(), "select pg_advisory_unlock($1)", m.lockKey())
+	} else {
+		_, err = conn.ExecContext(context.Background(), "select release_lock(?)", m.lockName())
+	}
+	if err != nil {
+		logx.Errorf("释放迁移锁失败：%v", err)
+	}
+}
+
+func (m *Migrator) lockName() string {
+	return lockPrefix + m.table
+}
+
+func (m *Migrator) lockKey() int64 {
+	return int64(hash.Hash([]byte(m.lockName())))
+}
@@ -0,0 +1,398 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/logx"
+	"github.com/gotid/god/lib/store/sqlx"
+	"github.com/gotid/god/lib/timex"
+	"time"
+)
+
+const (
+	defaultTable       = "schema_migrations"
+	defaultLockTimeout = time.Minute
+)
+
+var (
+	// ErrDuplicateVersion 表示存在重复的迁移版本。
+	ErrDuplicateVersion = errors.New("迁移版本重复")
+	// ErrChecksumMismatch 表示已执行的迁移在执行后被修改。
+	ErrChecksumMismatch = errors.New("已执行的迁移被修改")
+	// ErrMissingMigration 表示已执行的迁移在迁移文件中不存在。
+	ErrMissingMigration = errors.New("已执行的迁移不存在")
+	// ErrOutOfOrder 表示待执行的迁移版本低于已执行的最新版本。
+	ErrOutOfOrder = errors.New("待执行的迁移版本低于已执行的最新版本")
+	// ErrEmptyMigration 表示升级脚本没有可执行的语句。
+	ErrEmptyMigration = errors.New("升级脚本没有可执行的语句")
+	// ErrNoDownMigration 表示迁移没有回滚脚本。
+	ErrNoDownMigration = errors.New("迁移没有回滚脚本")
+	// ErrLocked 表示在锁超时时间内未能获得迁移锁，可能有其他实例正在迁移。
+	ErrLocked = errors.New("未能获得迁移锁")
+)
+
+type (
+	// Option 自定义 Migrator。
+	Option func(m *Migrator)
+
+	// Migrator 是数据库迁移器，基于历史表记录已执行的迁移，
+	// 每个迁移在一个事务中执行，并以数据库锁保证同一时间只有一个实例在迁移。
+	// 注意：MySQL 的 DDL 会隐式提交事务，失败的迁移可能已部分生效。
+	Migrator struct {
+		conn        sqlx.Conn
+		migrations  []Migration
+		table       string
+		postgres    bool
+		dryRun      bool
+		lockTimeout time.Duration
+	}
+
+	// Status 是一个迁移的状态。
+	Status struct {
+		Version int64
+		Name    string
+		Applied bool
+		// AppliedAt 为执行时间，未执行时为零值。
+		AppliedAt time.Time
+		// Modified 表示已执行的迁移在执行后被修改。
+		Modified bool
+		// Missing 表示已执行的迁移在迁移文件中不存在。
+		Missing bool
+	}
+
+	record struct {
+		Version   int64  `db:"version"`
+		Name      string `db:"name"`
+		Checksum  string `db:"checksum"`
+		AppliedAt int64  `db:"applied_at"`
+	}
+)
+
+// WithTable 自定义历史表名，默认为 schema_migrations。
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithPostgres 使用 PostgreSQL 的占位符及咨询锁。
+func WithPostgres() Option {
+	return func(m *Migrator) {
+		m.postgres = true
+	}
+}
+
+// WithDryRun 仅打印将要执行的语句，不修改数据库。
+func WithDryRun() Option {
+	return func(m *Migrator) {
+		m.dryRun = true
+	}
+}
+
+// WithLockTimeout 自定义获取迁移锁的超时时间，默认 1 分钟。
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// New 返回一个迁移器，migrations 通常由 Load 或 LoadDir 加载。
+func New(conn sqlx.Conn, migrations []Migration, opts ...Option) *Migrator {
+	m := &Migrator{
+		conn:        conn,
+		migrations:  migrations,
+		table:       defaultTable,
+		lockTimeout: defaultLockTimeout,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Up 执行全部待执行的迁移，返回执行了的迁移。
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.UpTo(ctx, 0)
+}
+
+// UpTo 执行版本不大于 version 的待执行迁移，version 为 0 时执行全部。
+func (m *Migrator) UpTo(ctx context.Context, version int64) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func() error {
+		records, err := m.records(ctx)
+		if err != nil {
+			return err
+		}
+
+		pending, err := m.pending(records)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			if version > 0 && migration.Version > version {
+				break
+			}
+
+			if err = m.apply(ctx, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回回滚了的迁移。
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.withLock(ctx, func() error {
+		records, err := m.records(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err = m.validate(records); err != nil {
+			return err
+		}
+
+		migrations := m.index()
+		for i := len(records) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := migrations[records[i].Version]
+			if len(splitStatements(migration.Down)) == 0 {
+				return fmt.Errorf("%w：%s", ErrNoDownMigration, migration)
+			}
+
+			if err = m.rollback(ctx, migration); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status 返回全部迁移的状态，包括在迁移文件中已不存在的已执行迁移。
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+
+	var statuses []Status
+	migrations := m.index()
+	for _, r := range records {
+		if _, ok := migrations[r.Version]; !ok {
+			statuses = append(statuses, Status{
+				Version:   r.Version,
+				Name:      r.Name,
+				Applied:   true,
+				AppliedAt: time.UnixMilli(r.AppliedAt),
+				Missing:   true,
+			})
+		}
+	}
+	for _, migration := range m.migrations {
+		status := Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if r, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = time.UnixMilli(r.AppliedAt)
+			status.Modified = r.Checksum != migration.Checksum()
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Validate 校验已执行的迁移是否存在且未被修改。
+func (m *Migrator) Validate(ctx context.Context) error {
+	records, err := m.records(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.validate(records)
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	statements := splitStatements(migration.Up)
+	if m.dryRun {
+		m.printDryRun("执行", migration, statements)
+		return nil
+	}
+
+	start := timex.Now()
+	err := m.conn.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		for _, stmt := range statements {
+			if _, err := session.ExecCtx(ctx, stmt); err != nil {
+				return err
+			}
+		}
+
+		_, err := session.ExecCtx(ctx, fmt.Sprintf("insert into %s (version, name, checksum, applied_at) "+
+			"values (%s, %s, %s, %s)", m.table, m.placeholder(1), m.placeholder(2), m.placeholder(3),
+			m.placeholder(4)), migration.Version, migration.Name, migration.Checksum(), time.Now().UnixMilli())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("执行迁移 %s 失败：%w", migration, err)
+	}
+
+	logx.WithContext(ctx).WithDuration(timex.Since(start)).Infof("已执行迁移 %s", migration)
+	return nil
+}
+
+func (m *Migrator) rollback(ctx context.Context, migration Migration) error {
+	statements := splitStatements(migration.Down)
+	if m.dryRun {
+		m.printDryRun("回滚", migration, statements)
+		return nil
+	}
+
+	start := timex.Now()
+	err := m.conn.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		for _, stmt := range statements {
+			if _, err := session.ExecCtx(ctx, stmt); err != nil {
+				return err
+			}
+		}
+
+		_, err := session.ExecCtx(ctx, fmt.Sprintf("delete from %s where version = %s", m.table,
+			m.placeholder(1)), migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("回滚迁移 %s 失败：%w", migration, err)
+	}
+
+	logx.WithContext(ctx).WithDuration(timex.Since(start)).Infof("已回滚迁移 %s", migration)
+	return nil
+}
+
+func (m *Migrator) printDryRun(action string, migration Migration, statements []string) {
+	logx.Infof("[dry-run] %s迁移 %s", action, migration)
+	for _, stmt := range statements {
+		logx.Infof("[dry-run] %s;", stmt)
+	}
+}
+
+// pending 校验已执行的迁移并返回待执行的迁移。
+func (m *Migrator) pending(records []record) ([]Migration, error) {
+	if err := m.validate(records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]struct{}, len(records))
+	var latest int64
+	for _, r := range records {
+		applied[r.Version] = struct{}{}
+		if r.Version > latest {
+			latest = r.Version
+		}
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if migration.Version < latest {
+			return nil, fmt.Errorf("%w：%s", ErrOutOfOrder, migration)
+		}
+		pending = append(pending, migration)
+	}
+
+	return pending, nil
+}
+
+func (m *Migrator) validate(records []record) error {
+	migrations := m.index()
+	for _, r := range records {
+		migration, ok := migrations[r.Version]
+		if !ok {
+			return fmt.Errorf("%w：%d_%s", ErrMissingMigration, r.Version, r.Name)
+		}
+		if r.Checksum != migration.Checksum() {
+			return fmt.Errorf("%w：%s", ErrChecksumMismatch, migration)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) index() map[int64]Migration {
+	migrations := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		migrations[migration.Version] = migration
+	}
+
+	return migrations
+}
+
+// records 返回按版本升序排列的已执行迁移，历史表不存在时，非演练模式下将创建它。
+func (m *Migrator) records(ctx context.Context) ([]record, error) {
+	if m.dryRun {
+		exists, err := m.tableExists(ctx)
+		if err != nil || !exists {
+			return nil, err
+		}
+	} else if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var records []record
+	err := m.conn.QueryRowsCtx(ctx, &records, fmt.Sprintf("select version, name, checksum, applied_at "+
+		"from %s order by version", m.table))
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (m *Migrator) createTable(ctx context.Context) error {
+	_, err := m.conn.ExecCtx(ctx, fmt.Sprintf("create table if not exists %s ("+
+		"version bigint not null primary key, "+
+		"name varchar(255) not null, "+
+		"checksum char(64) not null, "+
+		"applied_at bigint not null)", m.table))
+	return err
+}
+
+func (m *Migrator) tableExists(ctx context.Context) (bool, error) {
+	var exists bool
+	var err error
+	if m.postgres {
+		err = m.conn.QueryRowCtx(ctx, &exists, "select to_regclass($1) is not null", m.table)
+	} else {
+		err = m.conn.QueryRowCtx(ctx, &exists, "select count(*) > 0 from information_schema.tables "+
+			"where table_schema = database() and table_name = ?", m.table)
+	}
+
+	return exists, err
+}
+
+func (m *Migrator) placeholder(index int) string {
+	if m.postgres {
+		return fmt.Sprintf("$%d", index)
+	}
+
+	return "?"
+}
@@ -0,0 +1,228 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gotid/god/lib/logx"
+	"github.com/gotid/god/lib/store/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var testMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_user",
+		Up:      "create table user (id bigint)",
+		Down:    "drop table user",
+	},
+	{
+		Version: 2,
+		Name:    "add_age",
+		Up:      "alter table user add age int; alter table user add index idx_age (age);",
+	},
+}
+
+func TestMigratorUp(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		expectLock(mock)
+		expectRecords(mock, testMigrations[0])
+		mock.ExpectBegin()
+		mock.ExpectExec("alter table user add age int").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("alter table user add index idx_age \\(age\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into schema_migrations \\(version, name, checksum, applied_at\\) values \\(\\?, \\?, \\?, \\?\\)").
+			WithArgs(2, "add_age", testMigrations[1].Checksum(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		applied, err := New(conn, testMigrations).Up(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, testMigrations[1:], applied)
+	})
+}
+
+func TestMigratorUpTo(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		expectLock(mock)
+		expectRecords(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("create table user").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		applied, err := New(conn, testMigrations).UpTo(context.Background(), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, testMigrations[:1], applied)
+	})
+}
+
+func TestMigratorUpFailure(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		errDDL := errors.New("ddl")
+		expectLock(mock)
+		expectRecords(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("create table user").WillReturnError(errDDL)
+		mock.ExpectRollback()
+		expectUnlock(mock)
+
+		applied, err := New(conn, testMigrations).Up(context.Background())
+		assert.True(t, errors.Is(err, errDDL))
+		assert.Empty(t, applied)
+	})
+}
+
+func TestMigratorValidate(t *testing.T) {
+	modified := testMigrations[0]
+	modified.Up = "create table user (id int)"
+
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		expectLock(mock)
+		expectRecords(mock, testMigrations[0])
+		expectUnlock(mock)
+
+		_, err := New(conn, []Migration{modified, testMigrations[1]}).Up(context.Background())
+		assert.True(t, errors.Is(err, ErrChecksumMismatch))
+	})
+
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		expectRecords(mock, testMigrations[0])
+		assert.True(t, errors.Is(New(conn, testMigrations[1:]).Validate(context.Background()), ErrMissingMigration))
+	})
+
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		expectLock(mock)
+		expectRecords(mock, testMigrations[1])
+		expectUnlock(mock)
+
+		_, err := New(conn, testMigrations).Up(context.Background())
+		assert.True(t, errors.Is(err, ErrOutOfOrder))
+	})
+}
+
+func TestMigratorDown(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		expectLock(mock)
+		expectRecords(mock, testMigrations...)
+		expectUnlock(mock)
+
+		_, err := New(conn, testMigrations).Down(context.Background(), 1)
+		assert.True(t, errors.Is(err, ErrNoDownMigration))
+	})
+
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		expectLock(mock)
+		expectRecords(mock, testMigrations[0])
+		mock.ExpectBegin()
+		mock.ExpectExec("drop table user").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("delete from schema_migrations where version = \\?").WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		rolledBack, err := New(conn, testMigrations).Down(context.Background(), 5)
+		assert.Nil(t, err)
+		assert.Equal(t, testMigrations[:1], rolledBack)
+	})
+}
+
+func TestMigratorStatus(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		missing := Migration{Version: 3, Name: "missing", Up: "select 1"}
+		expectRecords(mock, testMigrations[0], missing)
+
+		statuses, err := New(conn, testMigrations).Status(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, statuses, 3)
+		assert.Equal(t, Status{Version: 3, Name: "missing", Applied: true, AppliedAt: time.UnixMilli(1000),
+			Missing: true}, statuses[0])
+		assert.Equal(t, Status{Version: 1, Name: "create_user", Applied: true, AppliedAt: time.UnixMilli(1000)},
+			statuses[1])
+		assert.Equal(t, Status{Version: 2, Name: "add_age"}, statuses[2])
+	})
+}
+
+func TestMigratorDryRun(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("select count\\(\\*\\) > 0 from information_schema.tables").
+			WithArgs("migrations").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		applied, err := New(conn, testMigrations, WithDryRun(), WithTable("migrations")).Up(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, testMigrations, applied)
+	})
+}
+
+func TestMigratorLocked(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("select get_lock\\(\\?, \\?\\)").WithArgs("god_migrate:schema_migrations", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(0))
+
+		_, err := New(conn, testMigrations, WithLockTimeout(time.Second)).Up(context.Background())
+		assert.Equal(t, ErrLocked, err)
+	})
+}
+
+func TestMigratorPostgres(t *testing.T) {
+	runMigrateTest(t, func(conn sqlx.Conn, mock sqlmock.Sqlmock) {
+		m := New(conn, testMigrations[:1], WithPostgres(), WithLockTimeout(time.Millisecond))
+		mock.ExpectQuery("select pg_try_advisory_lock\\(\\$1\\)").WithArgs(m.lockKey()).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+		mock.ExpectQuery("select pg_try_advisory_lock\\(\\$1\\)").WithArgs(m.lockKey()).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		expectRecords(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("create table user").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into schema_migrations \\(version, name, checksum, applied_at\\) values \\(\\$1, \\$2, \\$3, \\$4\\)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec("select pg_advisory_unlock\\(\\$1\\)").WithArgs(m.lockKey()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		applied, err := m.Up(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, testMigrations[:1], applied)
+	})
+}
+
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("select get_lock\\(\\?, \\?\\)").WithArgs("god_migrate:schema_migrations", 60).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("select release_lock\\(\\?\\)").WithArgs("god_migrate:schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectRecords(mock sqlmock.Sqlmock, applied ...Migration) {
+	mock.ExpectExec("create table if not exists").WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"})
+	for _, migration := range applied {
+		rows.AddRow(migration.Version, migration.Name, migration.Checksum(), 1000)
+	}
+	mock.ExpectQuery("select version, name, checksum, applied_at from schema_migrations order by version").
+		WillReturnRows(rows)
+}
+
+func runMigrateTest(t *testing.T, fn func(conn sqlx.Conn, mock sqlmock.Sqlmock)) {
+	logx.Disable()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	fn(sqlx.NewConnFromDB(db), mock)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,249 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gotid/god/lib/mathx"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// Migration 是一个版本的迁移，由 <版本>_<名称>.up.sql 及可选的 <版本>_<名称>.down.sql 组成，
+// 版本为正整数，通常为 20060102150405 格式的时间戳。
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum 返回升级及回滚脚本的 SHA-256 校验和，用于发现已执行的迁移被修改。
+func (m Migration) Checksum() string {
+	h := sha256.New()
+	h.Write([]byte(m.Up))
+	// 以 0 分隔，避免脚本内容在升级与回滚之间移动时校验和不变。
+	h.Write([]byte{0})
+	h.Write([]byte(m.Down))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// UpStatements 返回升级脚本中的语句。
+func (m Migration) UpStatements() []string {
+	return splitStatements(m.Up)
+}
+
+// DownStatements 返回回滚脚本中的语句。
+func (m Migration) DownStatements() []string {
+	return splitStatements(m.Down)
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// LoadDir 从目录加载迁移。
+func LoadDir(dir string) ([]Migration, error) {
+	return Load(os.DirFS(dir), ".")
+}
+
+// Load 从文件系统（如 embed.FS）的 dir 目录加载迁移，按版本升序返回，忽略非 .sql 文件。
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make(map[int64]*Migration)
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(filename, ".sql") {
+			continue
+		}
+
+		version, name, up, err := parseFilename(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, filename))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			migrations[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("%w：%d（%s 与 %s）", ErrDuplicateVersion, version, m.Name, name)
+		}
+
+		if up {
+			if len(m.Up) > 0 {
+				return nil, fmt.Errorf("%w：%s", ErrDuplicateVersion, filename)
+			}
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if len(strings.TrimSpace(m.Up)) == 0 {
+			return nil, fmt.Errorf("迁移 %s 缺少升级脚本 %s%s", m, m, upSuffix)
+		}
+		// 仅含注释的升级脚本执行后将被记录为已执行，补写语句后无法再执行。
+		if len(m.UpStatements()) == 0 {
+			return nil, fmt.Errorf("%w：%s%s", ErrEmptyMigration, m, upSuffix)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// Filenames 返回给定版本及名称的升级及回滚脚本文件名。
+func Filenames(version int64, name string) (up, down string) {
+	base := fmt.Sprintf("%d_%s", version, name)
+	return base + upSuffix, base + downSuffix
+}
+
+func parseFilename(filename string) (version int64, name string, up bool, err error) {
+	var base string
+	switch {
+	case strings.HasSuffix(filename, upSuffix):
+		base, up = strings.TrimSuffix(filename, upSuffix), true
+	case strings.HasSuffix(filename, downSuffix):
+		base = strings.TrimSuffix(filename, downSuffix)
+	default:
+		return 0, "", false, fmt.Errorf("迁移文件名须以 %s 或 %s 结尾：%s", upSuffix, downSuffix, filename)
+	}
+
+	ver, name, ok := strings.Cut(base, "_")
+	if !ok || len(name) == 0 {
+		return 0, "", false, fmt.Errorf("迁移文件名须形如 <版本>_<名称>%s：%s", upSuffix, filename)
+	}
+
+	version, err = strconv.ParseInt(ver, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", false, fmt.Errorf("迁移文件的版本须为正整数：%s", filename)
+	}
+
+	return version, name, up, nil
+}
+
+// splitStatements 以分号拆分脚本，忽略引号、注释及 PostgreSQL 美元符引用（$$ 或 $tag$）中的分号。
+func splitStatements(script string) []string {
+	var statements []string
+	var b strings.Builder
+	flush := func() {
+		stmt := strings.TrimSpace(b.String())
+		if len(stmt) > 0 && !isComment(stmt) {
+			statements = append(statements, stmt)
+		}
+		b.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := indexFrom(script, string(ch), i+1)
+			b.WriteString(script[i : end+1])
+			i = end
+		case ch == '-' && strings.HasPrefix(script[i:], "--"):
+			end := indexFrom(script, "\n", i)
+			b.WriteString(script[i : end+1])
+			i = end
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := indexFrom(script, "*/", i+2) + 1
+			b.WriteString(script[i:mathx.MinInt(end+1, len(script))])
+			i = end
+		case ch == '$':
+			if tag := dollarTag(script[i:]); len(tag) > 0 {
+				end := indexFrom(script, tag, i+len(tag)) + len(tag) - 1
+				b.WriteString(script[i:mathx.MinInt(end+1, len(script))])
+				i = end
+			} else {
+				b.WriteByte(ch)
+			}
+		case ch == ';':
+			flush()
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// indexFrom 返回 sub 在 s[from:] 中的位置，不存在则返回末尾位置。
+func indexFrom(s, sub string, from int) int {
+	if from >= len(s) {
+		return len(s) - 1
+	}
+
+	index := strings.Index(s[from:], sub)
+	if index < 0 {
+		return len(s) - 1
+	}
+
+	return from + index
+}
+
+// dollarTag 返回 s 开头的美元符引用标签，如 $$ 或 $body$。
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '$':
+			return s[:i+1]
+		case ch == '_', 'a' <= ch && ch <= 'z', 'A' <= ch && ch <= 'Z':
+		case '0' <= ch && ch <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
+
+// isComment 判断语句是否仅由注释组成。
+func isComment(stmt string) bool {
+	for len(stmt) > 0 {
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			index := strings.IndexByte(stmt, '\n')
+			if index < 0 {
+				return true
+			}
+			stmt = stmt[index+1:]
+		case strings.HasPrefix(stmt, "/*"):
+			index := strings.Index(stmt, "*/")
+			if index < 0 {
+				return true
+			}
+			stmt = stmt[index+2:]
+		default:
+			return false
+		}
+		stmt = strings.TrimSpace(stmt)
+	}
+
+	return true
+}
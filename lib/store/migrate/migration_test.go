@@ -0,0 +1,125 @@
+package migrate
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/20220102000000_add_age.up.sql":     {Data: []byte("alter table user add age int;")},
+		"migrations/20220101000000_create_user.up.sql": {Data: []byte("create table user (id bigint);")},
+		"migrations/20220101000000_create_user.down.sql": {
+			Data: []byte("drop table user;"),
+		},
+		"migrations/README.md": {Data: []byte("readme")},
+	}
+
+	migrations, err := Load(fsys, "migrations")
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{
+		{
+			Version: 20220101000000,
+			Name:    "create_user",
+			Up:      "create table user (id bigint);",
+			Down:    "drop table user;",
+		},
+		{
+			Version: 20220102000000,
+			Name:    "add_age",
+			Up:      "alter table user add age int;",
+		},
+	}, migrations)
+	assert.Equal(t, "20220101000000_create_user", migrations[0].String())
+	assert.Len(t, migrations[0].Checksum(), 64)
+	assert.NotEqual(t, migrations[0].Checksum(), migrations[1].Checksum())
+
+	// 回滚脚本的修改同样改变校验和。
+	modified := migrations[0]
+	modified.Down = "drop table if exists user;"
+	assert.NotEqual(t, migrations[0].Checksum(), modified.Checksum())
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "bad suffix",
+			fsys: fstest.MapFS{"1_create.sql": {}},
+		},
+		{
+			name: "no name",
+			fsys: fstest.MapFS{"1.up.sql": {}},
+		},
+		{
+			name: "bad version",
+			fsys: fstest.MapFS{"v1_create.up.sql": {}},
+		},
+		{
+			name: "duplicate",
+			fsys: fstest.MapFS{
+				"1_create.up.sql": {Data: []byte("select 1")},
+				"1_update.up.sql": {Data: []byte("select 1")},
+			},
+		},
+		{
+			name: "no up",
+			fsys: fstest.MapFS{"1_create.down.sql": {Data: []byte("select 1")}},
+		},
+	}
+
+	// 仅含注释的升级脚本不可加载，以免被记录为已执行。
+	_, err := Load(fstest.MapFS{
+		"1_create.up.sql":   {Data: []byte("-- 在此编写升级语句\n")},
+		"1_create.down.sql": {Data: []byte("drop table user;")},
+	}, ".")
+	assert.True(t, errors.Is(err, ErrEmptyMigration))
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(test.fsys, ".")
+			assert.NotNil(t, err)
+		})
+	}
+
+	_, err = LoadDir("not_exists")
+	assert.NotNil(t, err)
+}
+
+func TestFilenames(t *testing.T) {
+	up, down := Filenames(1, "create_user")
+	assert.Equal(t, "1_create_user.up.sql", up)
+	assert.Equal(t, "1_create_user.down.sql", down)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- 用户表
+create table user (
+	id bigint, -- 主键;
+	name varchar(255) default 'a;b'
+);
+/* 索引; */
+create index idx_name on user (name);
+
+create function add(a int, b int) returns int as $body$
+begin
+	return a + b;
+end;
+$body$ language plpgsql;
+update user set name = "x;y" where id = $1;
+-- 结尾注释
+`
+	assert.Equal(t, []string{
+		"-- 用户表\ncreate table user (\n\tid bigint, -- 主键;\n\tname varchar(255) default 'a;b'\n)",
+		"/* 索引; */\ncreate index idx_name on user (name)",
+		"create function add(a int, b int) returns int as $body$\nbegin\n\treturn a + b;\nend;\n$body$ language plpgsql",
+		`update user set name = "x;y" where id = $1`,
+	}, splitStatements(script))
+	assert.Empty(t, splitStatements("  ;\n-- comment\n;/* block */"))
+	assert.Equal(t, []string{"select 'unterminated"}, splitStatements("select 'unterminated"))
+}
@@ -38,6 +38,19 @@ func CreateBlockingNode(r *Redis) (ClosableNode, error) {
 			ReadTimeout:  timeout,
 		})
 		return &clusterBridge{client}, nil
+	case SentinelType:
+		client := red.NewFailoverClient(&red.FailoverOptions{
+			MasterName:       r.Addr,
+			SentinelAddrs:    r.sentinelAddrs,
+			SentinelPassword: r.sentinelPass,
+			Password:         r.Pass,
+			DB:               defaultDatabase,
+			MaxRetries:       maxRetries,
+			PoolSize:         1,
+			MinIdleConns:     1,
+			ReadTimeout:      timeout,
+		})
+		return &clientBridge{client}, nil
 	default:
 		return nil, fmt.Errorf("未知的 redis 类型: %s", r.Type)
 	}
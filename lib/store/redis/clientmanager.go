@@ -5,6 +5,7 @@ import (
 	red "github.com/go-redis/redis/v8"
 	"github.com/gotid/god/lib/syncx"
 	"io"
+	"strings"
 )
 
 const (
@@ -41,3 +42,35 @@ func getClient(r *Redis) (*red.Client, error) {
 
 	return val.(*red.Client), nil
 }
+
+// getSentinel 返回哨兵模式的客户端，客户端按主节点名称及哨兵地址缓存，而非当前主节点地址，
+// 主从切换时由客户端订阅哨兵的 +switch-master 通知，关闭旧连接并连接新的主节点，缓存无需失效。
+func getSentinel(r *Redis) (*red.Client, error) {
+	key := r.Addr + "@" + strings.Join(r.sentinelAddrs, ",")
+	val, err := clientManager.Get(key, func() (io.Closer, error) {
+		var tlsConfig *tls.Config
+		if r.tls {
+			tlsConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+		client := red.NewFailoverClient(&red.FailoverOptions{
+			MasterName:       r.Addr,
+			SentinelAddrs:    r.sentinelAddrs,
+			SentinelPassword: r.sentinelPass,
+			Password:         r.Pass,
+			DB:               defaultDatabase,
+			MaxRetries:       maxRetries,
+			MinIdleConns:     idleConns,
+			TLSConfig:        tlsConfig,
+		})
+		client.AddHook(durationHook)
+
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val.(*red.Client), nil
+}
@@ -9,15 +9,23 @@ var (
 	ErrEmptyType = errors.New("redis 类型不能为空")
 	// ErrEmptyKey 是一个表示没设置 redis 键名的错误。
 	ErrEmptyKey = errors.New("redis 键名不能为空")
+	// ErrEmptyMasterName 是一个表示哨兵模式没设置主节点名称的错误。
+	ErrEmptyMasterName = errors.New("redis 哨兵模式的主节点名称不能为空")
+	// ErrEmptySentinelAddrs 是一个表示哨兵模式没设置哨兵地址的错误。
+	ErrEmptySentinelAddrs = errors.New("redis 哨兵模式的哨兵地址不能为空")
 )
 
 type (
 	// Config 是一个 redis 配置。
+	// 哨兵模式下无需 Host，由 MasterName 及 SentinelAddrs 指定主节点，Pass 为主从节点的密码。
 	Config struct {
-		Host string
-		Type string `json:",default=node,options=[node,cluster]"`
-		Pass string `json:",optional"`
-		Tls  bool   `json:",optional"`
+		Host          string   `json:",optional"`
+		Type          string   `json:",default=node,options=[node,cluster,sentinel]"`
+		Pass          string   `json:",optional"`
+		Tls           bool     `json:",optional"`
+		MasterName    string   `json:",optional"`
+		SentinelAddrs []string `json:",optional"`
+		SentinelPass  string   `json:",optional"`
 	}
 
 	// KeyConfig 是一个基于给定键的 redis 配置。
@@ -30,8 +38,16 @@ type (
 // NewRedis 基于配置返回一个 Redis 节点实例。
 func (c Config) NewRedis() *Redis {
 	var opts []Option
-	if c.Type == ClusterType {
+	addr := c.Host
+	switch c.Type {
+	case ClusterType:
 		opts = append(opts, WithCluster())
+	case SentinelType:
+		addr = c.MasterName
+		opts = append(opts, WithSentinel(c.SentinelAddrs...))
+		if len(c.SentinelPass) > 0 {
+			opts = append(opts, WithSentinelPass(c.SentinelPass))
+		}
 	}
 	if len(c.Pass) > 0 {
 		opts = append(opts, WithPass(c.Pass))
@@ -40,19 +56,29 @@ func (c Config) NewRedis() *Redis {
 		opts = append(opts, WithTLS())
 	}
 
-	return New(c.Host, opts...)
+	return New(addr, opts...)
 }
 
 // Validate 验证 Config 是否正确。
 func (c Config) Validate() error {
-	if len(c.Host) == 0 {
-		return ErrEmptyHost
-	}
-
 	if len(c.Type) == 0 {
 		return ErrEmptyType
 	}
 
+	if c.Type == SentinelType {
+		if len(c.MasterName) == 0 {
+			return ErrEmptyMasterName
+		}
+		if len(c.SentinelAddrs) == 0 {
+			return ErrEmptySentinelAddrs
+		}
+		return nil
+	}
+
+	if len(c.Host) == 0 {
+		return ErrEmptyHost
+	}
+
 	return nil
 }
 
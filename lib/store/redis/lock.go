@@ -2,11 +2,14 @@ package redis
 
 import (
 	"context"
+	"errors"
 	red "github.com/go-redis/redis/v8"
 	"github.com/gotid/god/lib/logx"
 	"github.com/gotid/god/lib/stringx"
+	"github.com/gotid/god/lib/threading"
 	"math/rand"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -15,7 +18,12 @@ const (
 	randomLen       = 16
 	millisPerSecond = 1000
 	tolerance       = 500 // 毫秒
-	lockCommand     = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+	// 开启看门狗且未设置过期时间时的默认租期。
+	defaultWatchdogSeconds = 30
+	lockMinBackoff         = 10 * time.Millisecond
+	lockMaxBackoff         = time.Second
+	// 普通锁以字符串存储持有者令牌，重复获取仅续期，一次释放即解锁。
+	lockCommand = `if redis.call("GET", KEYS[1]) == ARGV[1] then
     redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
     return "OK"
 else
@@ -26,27 +34,100 @@ end`
 else
     return 0
 end`
+	renewCommand = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+    return 0
+end`
+	// 可重入锁以哈希存储，字段为持有者令牌，值为重入次数。
+	// 键为其他类型（如普通锁）时视为被他人持有，以免与普通锁互相干扰。
+	reentrantLockCommand = `local t = redis.call("TYPE", KEYS[1]).ok
+if t == "none" or (t == "hash" and redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1) then
+    redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return "OK"
+else
+    return nil
+end`
+	// 返回 0 表示未持有锁，1 表示已完全释放，2 表示重入次数减一后仍持有锁。
+	reentrantDelCommand = `if redis.call("TYPE", KEYS[1]).ok ~= "hash" or redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+    return 0
+end
+if redis.call("HINCRBY", KEYS[1], ARGV[1], -1) > 0 then
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return 2
+end
+redis.call("DEL", KEYS[1])
+return 1`
+	reentrantRenewCommand = `if redis.call("TYPE", KEYS[1]).ok == "hash" and redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+    return 0
+end`
+	released  = 1
+	reentered = 2
 )
 
-// Lock 是一把 redis 锁。
-type Lock struct {
-	rds     *Redis
-	seconds uint32
-	key     string
-	id      string
-}
+// ErrLockNotAcquired 表示在上下文结束前未能获取锁。
+var ErrLockNotAcquired = errors.New("未能获取 redis 锁")
+
+type (
+	// Lock 是一把 redis 锁。
+	// 默认为普通锁：重复获取仅续期，一次释放即解锁；以 WithLockOwner 创建的锁为可重入锁。
+	Lock struct {
+		rds       *Redis
+		seconds   uint32
+		key       string
+		id        string
+		reentrant bool
+		watchdog  bool
+		lock      sync.Mutex
+		stop      chan struct{}
+	}
+
+	// LockOption 自定义 Lock 的方法。
+	LockOption func(l *Lock)
+)
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
 // NewLock 返回一个 Lock 实例。
-func NewLock(rds *Redis, key string) *Lock {
-	return &Lock{
+func NewLock(rds *Redis, key string, opts ...LockOption) *Lock {
+	l := &Lock{
 		rds: rds,
 		key: key,
 		id:  stringx.Randn(randomLen),
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// WithLockOwner 指定持有者令牌并开启按令牌计数的重入，令牌相同的锁视为同一持有者，获取几次须释放几次。
+// 可重入锁以哈希存储，与普通锁的存储格式不同：同一个键上两者互斥，但旧版本的普通锁遇到可重入锁将返回
+// WRONGTYPE 错误。已有的键改为可重入锁时，应在全部实例升级后再启用，或改用新的键名。
+func WithLockOwner(owner string) LockOption {
+	return func(l *Lock) {
+		l.id = owner
+		l.reentrant = true
+	}
+}
+
+// WithLockWatchdog 开启看门狗，持有锁期间每隔三分之一租期自动续期，
+// 直至完全释放锁或进程退出，未设置过期时间时租期默认为 30 秒。
+func WithLockWatchdog() LockOption {
+	return func(l *Lock) {
+		l.watchdog = true
+	}
+}
+
+// Owner 返回持有者令牌。
+func (l *Lock) Owner() string {
+	return l.id
 }
 
 // Acquire 获取 redis 锁。
@@ -56,35 +137,99 @@ func (l *Lock) Acquire() (bool, error) {
 
 // AcquireCtx 获取具有给定上下文的 redis 锁。
 func (l *Lock) AcquireCtx(ctx context.Context) (bool, error) {
-	seconds := atomic.LoadUint32(&l.seconds)
-	resp, err := l.rds.EvalCtx(ctx, lockCommand, []string{l.key}, []string{
-		l.id, strconv.Itoa(int(seconds)*millisPerSecond + tolerance),
+	script := lockCommand
+	if l.reentrant {
+		script = reentrantLockCommand
+	}
+
+	resp, err := l.rds.EvalCtx(ctx, script, []string{l.key}, []string{
+		l.id, strconv.Itoa(l.leaseMillis()),
 	})
 	if err == red.Nil {
 		return false, nil
 	} else if err != nil {
-		logx.Errorf("为键 %s 获取 redis 锁时发生错误：%s", l.key, err.Error())
+		logx.WithContext(ctx).Errorf("为键 %s 获取 redis 锁时发生错误：%s", l.key, err.Error())
 		return false, err
 	} else if resp == nil {
 		return false, nil
 	}
 
 	if reply, ok := resp.(string); ok && reply == "OK" {
+		l.startWatchdog()
 		return true, nil
 	}
 
-	logx.Errorf("为键 %s 获取 redis 锁时返回未知响应：%s", l.key, resp)
+	logx.WithContext(ctx).Errorf("为键 %s 获取 redis 锁时返回未知响应：%s", l.key, resp)
 	return false, nil
 }
 
+// AcquireWithTimeout 阻塞获取 redis 锁，获取失败时退避重试，直至获取成功或 ctx 结束。
+// ctx 结束前仍未获取到锁时返回 false。
+func (l *Lock) AcquireWithTimeout(ctx context.Context) (bool, error) {
+	var backoff time.Duration
+	for {
+		ok, err := l.AcquireCtx(ctx)
+		if ok {
+			return true, nil
+		}
+		if ctx.Err() != nil {
+			logx.WithContext(ctx).Infof("获取键 %s 的 redis 锁超时", l.key)
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		backoff = nextLockBackoff(backoff)
+		// 加入抖动，避免多个等待者同时重试。
+		timer := time.NewTimer(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logx.WithContext(ctx).Infof("获取键 %s 的 redis 锁超时", l.key)
+			return false, nil
+		case <-timer.C:
+		}
+	}
+}
+
+// LockedFunc 阻塞获取锁后执行 fn，无论 fn 返回错误或 panic 均会释放锁。
+// ctx 结束前未获取到锁时返回 ErrLockNotAcquired。
+func (l *Lock) LockedFunc(ctx context.Context, fn func() error) error {
+	ok, err := l.AcquireWithTimeout(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotAcquired
+	}
+
+	defer func() {
+		// 使用独立的上下文，确保 ctx 取消后仍能释放锁。
+		if _, err := l.ReleaseCtx(context.Background()); err != nil {
+			logx.WithContext(ctx).Errorf("释放键 %s 的 redis 锁时发生错误：%v", l.key, err)
+		}
+	}()
+
+	return fn()
+}
+
 // Release 释放 redis 锁。
 func (l *Lock) Release() (bool, error) {
 	return l.ReleaseCtx(context.Background())
 }
 
-// ReleaseCtx 释放给定上下文的 redis 锁。
+// ReleaseCtx 释放给定上下文的 redis 锁，可重入锁仅减少一次重入计数。
 func (l *Lock) ReleaseCtx(ctx context.Context) (bool, error) {
-	resp, err := l.rds.EvalCtx(ctx, delCommand, []string{l.key}, []string{l.id})
+	var resp any
+	var err error
+	if l.reentrant {
+		resp, err = l.rds.EvalCtx(ctx, reentrantDelCommand, []string{l.key}, []string{
+			l.id, strconv.Itoa(l.leaseMillis()),
+		})
+	} else {
+		resp, err = l.rds.EvalCtx(ctx, delCommand, []string{l.key}, []string{l.id})
+	}
 	if err != nil {
 		return false, err
 	}
@@ -94,10 +239,116 @@ func (l *Lock) ReleaseCtx(ctx context.Context) (bool, error) {
 		return false, nil
 	}
 
-	return reply == 1, nil
+	if reply != reentered {
+		l.stopWatchdog()
+	}
+
+	return reply == released || reply == reentered, nil
 }
 
 // SetExpire 设置过期时间
 func (l *Lock) SetExpire(seconds int) {
 	atomic.StoreUint32(&l.seconds, uint32(seconds))
 }
+
+func (l *Lock) leaseMillis() int {
+	seconds := atomic.LoadUint32(&l.seconds)
+	if seconds == 0 && l.watchdog {
+		seconds = defaultWatchdogSeconds
+	}
+
+	return int(seconds)*millisPerSecond + tolerance
+}
+
+func (l *Lock) renewInterval() time.Duration {
+	return time.Duration(l.leaseMillis()) * time.Millisecond / 3
+}
+
+func (l *Lock) renew() (bool, error) {
+	script := renewCommand
+	if l.reentrant {
+		script = reentrantRenewCommand
+	}
+
+	resp, err := l.rds.Eval(script, []string{l.key}, []string{
+		l.id, strconv.Itoa(l.leaseMillis()),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	reply, ok := resp.(int64)
+	return ok && reply == 1, nil
+}
+
+func (l *Lock) startWatchdog() {
+	if !l.watchdog {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.stop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	l.stop = stop
+	// 每次续期后按当前租期重新计算间隔，以便 SetExpire 及时生效。
+	timer := time.NewTimer(l.renewInterval())
+	threading.GoSafe(func() {
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-timer.C:
+				ok, err := l.renew()
+				if err != nil {
+					logx.Errorf("续期键 %s 的 redis 锁时发生错误：%v", l.key, err)
+				} else if !ok {
+					logx.Errorf("键 %s 的 redis 锁已丢失，停止续期", l.key)
+					l.stopWatchdogIf(stop)
+					return
+				}
+				timer.Reset(l.renewInterval())
+			}
+		}
+	})
+}
+
+func (l *Lock) stopWatchdog() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+	}
+}
+
+// stopWatchdogIf 仅在 stop 仍为当前看门狗时将其清除，避免误停新启动的看门狗。
+func (l *Lock) stopWatchdogIf(stop chan struct{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.stop == stop {
+		close(l.stop)
+		l.stop = nil
+	}
+}
+
+func nextLockBackoff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		return lockMinBackoff
+	}
+
+	backoff *= 2
+	if backoff > lockMaxBackoff {
+		return lockMaxBackoff
+	}
+
+	return backoff
+}
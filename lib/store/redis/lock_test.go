@@ -2,9 +2,12 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gotid/god/lib/stringx"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestLock(t *testing.T) {
@@ -61,3 +64,203 @@ func TestLock_Expire(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestLock_Reentrant(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := stringx.Rand()
+		owner := stringx.Rand()
+		lock := NewLock(client, key, WithLockOwner(owner))
+		lock.SetExpire(5)
+		// 同一持有者令牌的锁可重入。
+		nested := NewLock(client, key, WithLockOwner(owner))
+		nested.SetExpire(5)
+		other := NewLock(client, key, WithLockOwner(stringx.Rand()))
+
+		ok, err := lock.Acquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = nested.Acquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		ok, err = nested.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = other.Acquire()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		ok, err = lock.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = lock.Release()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		ok, err = other.Acquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestLock_NotReentrantByDefault(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := stringx.Rand()
+		lock := NewLock(client, key)
+		lock.SetExpire(5)
+
+		// 重复获取仅续期，一次释放即解锁。
+		for i := 0; i < 2; i++ {
+			ok, err := lock.Acquire()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+		}
+		ok, err := lock.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// 普通锁与可重入锁在同一个键上互斥。
+		reentrant := NewLock(client, key, WithLockOwner(stringx.Rand()))
+		ok, err = lock.Acquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = reentrant.Acquire()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		ok, err = reentrant.Release()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		ok, err = lock.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = reentrant.Acquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = lock.Acquire()
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestLock_WatchdogFollowsExpire(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	key := stringx.Rand()
+	lock := NewLock(New(s.Addr()), key, WithLockWatchdog())
+	lock.SetExpire(1)
+	ok, err := lock.Acquire()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	defer lock.Release()
+
+	// 首次续期（0.5 秒）后改用 3.5 秒租期，续期间隔随之变为约 1.17 秒。
+	lock.SetExpire(3)
+	time.Sleep(700 * time.Millisecond)
+	assert.True(t, s.TTL(key) > 3*time.Second)
+	s.FastForward(time.Second)
+	time.Sleep(600 * time.Millisecond)
+	assert.True(t, s.TTL(key) < 3*time.Second)
+	time.Sleep(700 * time.Millisecond)
+	assert.True(t, s.TTL(key) > 3*time.Second)
+}
+
+func TestLock_Watchdog(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	key := stringx.Rand()
+	lock := NewLock(New(s.Addr()), key, WithLockWatchdog())
+	lock.SetExpire(1)
+	ok, err := lock.Acquire()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// 租期为 1.5 秒，看门狗每 0.5 秒续期一次。
+	s.FastForward(time.Second)
+	time.Sleep(700 * time.Millisecond)
+	assert.True(t, s.TTL(key) > time.Second)
+
+	ok, err = lock.Release()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.False(t, s.Exists(key))
+
+	// 锁丢失后看门狗停止续期。
+	ok, err = lock.Acquire()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	s.Del(key)
+	time.Sleep(700 * time.Millisecond)
+	lock.lock.Lock()
+	assert.Nil(t, lock.stop)
+	lock.lock.Unlock()
+}
+
+func TestLock_AcquireWithTimeout(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := stringx.Rand()
+		holder := NewLock(client, key)
+		holder.SetExpire(5)
+		ok, err := holder.Acquire()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		lock := NewLock(client, key)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		ok, err = lock.AcquireWithTimeout(ctx)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, ErrLockNotAcquired, lock.LockedFunc(ctx, func() error {
+			return nil
+		}))
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = holder.Release()
+		}()
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ok, err = lock.AcquireWithTimeout(ctx)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		ok, err = lock.Release()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestLock_LockedFunc(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := stringx.Rand()
+		lock := NewLock(client, key)
+		errDummy := errors.New("dummy")
+		assert.Equal(t, errDummy, lock.LockedFunc(context.Background(), func() error {
+			ok, err := NewLock(client, key).Acquire()
+			assert.Nil(t, err)
+			assert.False(t, ok)
+			return errDummy
+		}))
+		ok, err := client.Exists(key)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		assert.Panics(t, func() {
+			_ = lock.LockedFunc(context.Background(), func() error {
+				panic("dummy")
+			})
+		})
+		ok, err = client.Exists(key)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestNextLockBackoff(t *testing.T) {
+	assert.Equal(t, lockMinBackoff, nextLockBackoff(0))
+	assert.Equal(t, 2*lockMinBackoff, nextLockBackoff(lockMinBackoff))
+	assert.Equal(t, lockMaxBackoff, nextLockBackoff(lockMaxBackoff))
+}
@@ -0,0 +1,301 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	red "github.com/go-redis/redis/v8"
+	"github.com/gotid/god/lib/logx"
+	"github.com/gotid/god/lib/threading"
+	"github.com/gotid/god/lib/timex"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	subscribeCommand  = "subscribe"
+	psubscribeCommand = "psubscribe"
+
+	// 消息缓冲区大小，处理消息过慢导致缓冲区满时，等待 subscribeSendTimeout 后丢弃消息。
+	subscribeBufferSize  = 100
+	subscribeSendTimeout = time.Minute
+	// 超过 subscribePingInterval 未收到消息时发送 PING 探测连接，以便及时发现失效连接并重新订阅。
+	subscribePingInterval = 30 * time.Second
+	subscribeMinBackoff   = 100 * time.Millisecond
+	subscribeMaxBackoff   = 5 * time.Second
+)
+
+type (
+	// Message 是 redis.Message 的别名，表示一条订阅消息。
+	Message = red.Message
+
+	// Subscription 表示一个频道或模式订阅，使用完毕须调用 Close 或 Stop 取消订阅。
+	Subscription struct {
+		newPubSub func() *red.PubSub
+		command   string
+		cancel    context.CancelFunc
+		done      chan struct{}
+		lock      sync.Mutex
+		pubsub    *red.PubSub
+		closed    bool
+	}
+
+	subscriber interface {
+		Subscribe(ctx context.Context, channels ...string) *red.PubSub
+		PSubscribe(ctx context.Context, patterns ...string) *red.PubSub
+	}
+)
+
+// Publish 向频道发布消息，返回收到消息的订阅者数量。
+func (r *Redis) Publish(channel string, message any) (int64, error) {
+	return r.PublishCtx(context.Background(), channel, message)
+}
+
+// PublishCtx 向频道发布消息，返回收到消息的订阅者数量。
+func (r *Redis) PublishCtx(ctx context.Context, channel string, message any) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.Publish(ctx, channel, message).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// Subscribe 订阅给定频道，并在后台协程中依次以 handler 处理消息，参见 PSubscribe。
+func (r *Redis) Subscribe(ctx context.Context, handler func(msg *Message), channels ...string) (
+	*Subscription, error) {
+	return r.subscribe(ctx, subscribeCommand, handler, func(client subscriber) *red.PubSub {
+		return client.Subscribe(ctx, channels...)
+	})
+}
+
+// PSubscribe 订阅匹配给定模式的频道，并在后台协程中依次以 handler 处理消息。
+// 消息经由有界缓冲区交给 handler，处理过慢导致缓冲区持续满载时将丢弃消息；
+// 连接断开后自动重连并重新订阅，断开期间发布的消息将丢失。ctx 取消或调用 Close、Stop 后停止订阅。
+func (r *Redis) PSubscribe(ctx context.Context, handler func(msg *Message), patterns ...string) (
+	*Subscription, error) {
+	return r.subscribe(ctx, psubscribeCommand, handler, func(client subscriber) *red.PubSub {
+		return client.PSubscribe(ctx, patterns...)
+	})
+}
+
+func (r *Redis) subscribe(ctx context.Context, command string, handler func(msg *Message),
+	fn func(client subscriber) *red.PubSub) (*Subscription, error) {
+	client, err := getSubscriber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := timex.Now()
+	newPubSub := func() *red.PubSub {
+		return fn(client)
+	}
+	pubsub := newPubSub()
+	// 等待订阅确认，以便及时返回连接错误。
+	_, err = pubsub.Receive(ctx)
+	metricReqDur.Observe(int64(timex.Since(start)/time.Millisecond), command)
+	if err != nil {
+		metricReqErr.Inc(command, formatError(err))
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &Subscription{
+		newPubSub: newPubSub,
+		command:   command,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		pubsub:    pubsub,
+	}
+	messages := make(chan *Message, subscribeBufferSize)
+	threading.GoSafe(func() {
+		sub.receive(ctx, messages)
+	})
+	threading.GoSafe(func() {
+		defer close(sub.done)
+		for msg := range messages {
+			threading.RunSafe(func() {
+				handler(msg)
+			})
+		}
+	})
+	threading.GoSafe(func() {
+		// ctx 取消时关闭连接，以便及时结束阻塞中的接收。
+		<-ctx.Done()
+		_ = sub.close()
+	})
+
+	return sub, nil
+}
+
+// Close 取消订阅并等待正在处理的消息完成。
+// 在 handler 中调用将导致死锁，此时须使用 Stop。
+func (s *Subscription) Close() error {
+	err := s.Stop()
+	<-s.done
+	return err
+}
+
+// Stop 取消订阅，不等待正在处理的消息完成，可在 handler 中调用。
+func (s *Subscription) Stop() error {
+	s.cancel()
+	return s.close()
+}
+
+func (s *Subscription) close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	return s.pubsub.Close()
+}
+
+// resubscribe 丢弃当前订阅并以新连接重新订阅，已关闭时返回 nil。
+func (s *Subscription) resubscribe() *red.PubSub {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	_ = s.pubsub.Close()
+	s.pubsub = s.newPubSub()
+	return s.pubsub
+}
+
+// receive 持续接收消息并写入 messages，连接失效时退避并重新订阅。
+func (s *Subscription) receive(ctx context.Context, messages chan<- *Message) {
+	defer close(messages)
+
+	logger := logx.WithContext(ctx)
+	timer := time.NewTimer(subscribeSendTimeout)
+	defer timer.Stop()
+
+	s.lock.Lock()
+	pubsub := s.pubsub
+	s.lock.Unlock()
+
+	var backoff time.Duration
+	var pinged bool
+	for {
+		v, err := pubsub.ReceiveTimeout(ctx, subscribePingInterval)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, red.ErrClosed) {
+				return
+			}
+
+			if isTimeout(err) {
+				if !pinged {
+					// 长时间无消息，探测连接。
+					pinged = true
+					if err = pubsub.Ping(ctx); err == nil {
+						continue
+					}
+				} else {
+					// 探测后仍无响应，底层客户端不会因读超时重连，须丢弃连接并重新订阅。
+					if pubsub = s.resubscribe(); pubsub == nil {
+						return
+					}
+					pinged = false
+				}
+			}
+
+			// 连接断开时，底层客户端将在下次接收时重连并重新订阅。
+			metricReqErr.Inc(s.command, formatError(err))
+			logger.Errorf("redis 订阅连接失效，将重新订阅，错误：%v", err)
+			backoff = nextBackoff(backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			continue
+		}
+
+		pinged = false
+		switch msg := v.(type) {
+		case *red.Subscription:
+			if backoff > 0 {
+				logger.Infof("redis 重新订阅成功，%s：%s", msg.Kind, msg.Channel)
+				backoff = 0
+			}
+		case *red.Message:
+			if !send(ctx, timer, messages, msg) {
+				if ctx.Err() != nil {
+					return
+				}
+				metricReqErr.Inc(s.command, "dropped")
+				logger.Errorf("redis 订阅消息处理过慢，丢弃频道 %s 的消息", msg.Channel)
+			}
+		}
+	}
+}
+
+// send 将消息写入 messages，缓冲区满时至多等待 subscribeSendTimeout。
+func send(ctx context.Context, timer *time.Timer, messages chan<- *Message, msg *Message) bool {
+	select {
+	case messages <- msg:
+		return true
+	default:
+	}
+
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(subscribeSendTimeout)
+
+	select {
+	case messages <- msg:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func getSubscriber(r *Redis) (subscriber, error) {
+	switch r.Type {
+	case ClusterType:
+		return getCluster(r)
+	case NodeType:
+		return getClient(r)
+	case SentinelType:
+		return getSentinel(r)
+	default:
+		return nil, fmt.Errorf("不支持 redis 类型 '%s'", r.Type)
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		return subscribeMinBackoff
+	}
+
+	backoff *= 2
+	if backoff > subscribeMaxBackoff {
+		return subscribeMaxBackoff
+	}
+
+	return backoff
+}
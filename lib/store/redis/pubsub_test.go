@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRedis_PubSub(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		_, err := New(client.Addr, badType()).Publish("news", "hello")
+		assert.NotNil(t, err)
+		_, err = New(client.Addr, badType()).Subscribe(context.Background(), func(msg *Message) {}, "news")
+		assert.NotNil(t, err)
+
+		messages := make(chan *Message, 1)
+		sub, err := client.Subscribe(context.Background(), func(msg *Message) {
+			messages <- msg
+		}, "news")
+		assert.Nil(t, err)
+
+		n, err := client.Publish("news", "hello")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		select {
+		case msg := <-messages:
+			assert.Equal(t, "news", msg.Channel)
+			assert.Equal(t, "hello", msg.Payload)
+		case <-time.After(time.Second):
+			t.Fatal("未收到订阅消息")
+		}
+
+		assert.Nil(t, sub.Close())
+		assert.Nil(t, sub.Close())
+	})
+}
+
+func TestRedis_PSubscribe(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		_, err := New(client.Addr, badType()).PSubscribe(context.Background(), func(msg *Message) {}, "news.*")
+		assert.NotNil(t, err)
+
+		messages := make(chan *Message, 1)
+		sub, err := client.PSubscribe(context.Background(), func(msg *Message) {
+			messages <- msg
+		}, "news.*")
+		assert.Nil(t, err)
+		defer sub.Close()
+
+		_, err = client.Publish("news.sport", "goal")
+		assert.Nil(t, err)
+
+		select {
+		case msg := <-messages:
+			assert.Equal(t, "news.*", msg.Pattern)
+			assert.Equal(t, "news.sport", msg.Channel)
+			assert.Equal(t, "goal", msg.Payload)
+		case <-time.After(time.Second):
+			t.Fatal("未收到订阅消息")
+		}
+	})
+}
+
+func TestRedis_SubscribeCancel(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		ctx, cancel := context.WithCancel(context.Background())
+		sub, err := client.Subscribe(ctx, func(msg *Message) {}, "news")
+		assert.Nil(t, err)
+
+		cancel()
+		select {
+		case <-sub.done:
+		case <-time.After(time.Second):
+			t.Fatal("取消后未停止订阅")
+		}
+		assert.Nil(t, sub.Close())
+	})
+}
+
+func TestRedis_SubscribeStopInHandler(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		var sub *Subscription
+		ready := make(chan struct{})
+		stopped := make(chan error, 1)
+		sub, err := client.Subscribe(context.Background(), func(msg *Message) {
+			<-ready
+			stopped <- sub.Stop()
+		}, "news")
+		assert.Nil(t, err)
+		close(ready)
+
+		_, err = client.Publish("news", "hello")
+		assert.Nil(t, err)
+		select {
+		case err = <-stopped:
+			assert.Nil(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("handler 中停止订阅被阻塞")
+		}
+		select {
+		case <-sub.done:
+		case <-time.After(time.Second):
+			t.Fatal("停止后未结束订阅")
+		}
+		assert.Nil(t, sub.Close())
+	})
+}
+
+func TestRedis_Resubscribe(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	client := New(s.Addr())
+	messages := make(chan *Message, 10)
+	sub, err := client.Subscribe(context.Background(), func(msg *Message) {
+		messages <- msg
+	}, "news")
+	assert.Nil(t, err)
+	defer sub.Close()
+
+	// 重启后原连接断开，等待重新订阅。
+	s.Close()
+	assert.Nil(t, s.Restart())
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		n, _ := client.Publish("news", "again")
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("未重新订阅")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	select {
+	case msg := <-messages:
+		assert.Equal(t, "again", msg.Payload)
+	case <-time.After(time.Second):
+		t.Fatal("重新订阅后未收到消息")
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	assert.Equal(t, subscribeMinBackoff, nextBackoff(0))
+	assert.Equal(t, 2*subscribeMinBackoff, nextBackoff(subscribeMinBackoff))
+	assert.Equal(t, subscribeMaxBackoff, nextBackoff(subscribeMaxBackoff))
+}
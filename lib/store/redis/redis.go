@@ -17,6 +17,8 @@ const (
 	NodeType = "node"
 	// ClusterType 意为 redis 集群。
 	ClusterType = "cluster"
+	// SentinelType 意为由哨兵管理的 redis 主从，此时 Addr 为主节点名称。
+	SentinelType = "sentinel"
 	// Nil 是 redis.Nil 的别称。
 	Nil = red.Nil
 
@@ -48,6 +50,9 @@ type (
 		Pass string
 		tls  bool
 		brk  breaker.Breaker
+
+		sentinelAddrs []string
+		sentinelPass  string
 	}
 
 	// Node 接口表示一个 redis 节点。
@@ -2398,6 +2403,21 @@ func WithCluster() Option {
 	}
 }
 
+// WithSentinel 自定义 Redis 为哨兵模式，addrs 为哨兵地址，New 的 addr 参数为主节点名称。
+func WithSentinel(addrs ...string) Option {
+	return func(r *Redis) {
+		r.Type = SentinelType
+		r.sentinelAddrs = addrs
+	}
+}
+
+// WithSentinelPass 自定义哨兵的密码。
+func WithSentinelPass(pass string) Option {
+	return func(r *Redis) {
+		r.sentinelPass = pass
+	}
+}
+
 // WithPass 自定义 Redis 的密码。
 func WithPass(pass string) Option {
 	return func(r *Redis) {
@@ -2419,6 +2439,8 @@ func getRedis(r *Redis) (Node, error) {
 		return getCluster(r)
 	case NodeType:
 		return getClient(r)
+	case SentinelType:
+		return getSentinel(r)
 	default:
 		return nil, fmt.Errorf("不支持 redis 类型 '%s'", r.Type)
 	}
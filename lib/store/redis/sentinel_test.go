@@ -0,0 +1,210 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSentinel 是仅支持查询主节点地址及订阅主从切换通知的哨兵。
+type fakeSentinel struct {
+	listener net.Listener
+	lock     sync.Mutex
+	master   string
+	subs     []net.Conn
+}
+
+func newFakeSentinel(t *testing.T, master string) *fakeSentinel {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	s := &fakeSentinel{
+		listener: listener,
+		master:   master,
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSentinel) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSentinel) Close() {
+	_ = s.listener.Close()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, conn := range s.subs {
+		_ = conn.Close()
+	}
+}
+
+// switchMaster 切换主节点并发布 +switch-master 通知。
+func (s *fakeSentinel) switchMaster(name, master string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	oldHost, oldPort, _ := net.SplitHostPort(s.master)
+	newHost, newPort, _ := net.SplitHostPort(master)
+	s.master = master
+	payload := strings.Join([]string{name, oldHost, oldPort, newHost, newPort}, " ")
+	for _, conn := range s.subs {
+		_, _ = conn.Write([]byte(respArray("message", "+switch-master", payload)))
+	}
+}
+
+func (s *fakeSentinel) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSentinel) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		switch strings.ToLower(args[0]) {
+		case "sentinel":
+			switch strings.ToLower(args[1]) {
+			case "get-master-addr-by-name":
+				s.lock.Lock()
+				host, port, _ := net.SplitHostPort(s.master)
+				s.lock.Unlock()
+				reply = respArray(host, port)
+			default:
+				reply = "*0\r\n"
+			}
+		case "subscribe":
+			s.lock.Lock()
+			s.subs = append(s.subs, conn)
+			s.lock.Unlock()
+			for i, channel := range args[1:] {
+				reply += "*3\r\n$9\r\nsubscribe\r\n" + respBulk(channel) + ":" + strconv.Itoa(i+1) + "\r\n"
+			}
+		case "ping":
+			reply = respArray("pong", "")
+		default:
+			reply = "+OK\r\n"
+		}
+
+		s.lock.Lock()
+		_, err = conn.Write([]byte(reply))
+		s.lock.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if _, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		if args[i], err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(args[i], "\r\n")
+	}
+
+	return args, nil
+}
+
+func respArray(items ...string) string {
+	reply := fmt.Sprintf("*%d\r\n", len(items))
+	for _, item := range items {
+		reply += respBulk(item)
+	}
+	return reply
+}
+
+func respBulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func TestRedis_Sentinel(t *testing.T) {
+	m1, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer m1.Close()
+	m2, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer m2.Close()
+
+	sentinel := newFakeSentinel(t, m1.Addr())
+	defer sentinel.Close()
+
+	conf := Config{
+		Type:          SentinelType,
+		MasterName:    "mymaster",
+		SentinelAddrs: []string{sentinel.Addr()},
+		SentinelPass:  "any",
+	}
+	assert.Nil(t, conf.Validate())
+	client := conf.NewRedis()
+	assert.Equal(t, "mymaster", client.Addr)
+	assert.Equal(t, SentinelType, client.Type)
+
+	assert.Nil(t, client.Set("key", "one"))
+	val, err := m1.Get("key")
+	assert.Nil(t, err)
+	assert.Equal(t, "one", val)
+
+	// 主从切换后，缓存的客户端连接至新的主节点。
+	sentinel.switchMaster("mymaster", m2.Addr())
+	deadline := time.Now().Add(5 * time.Second)
+	for !m2.Exists("key") {
+		if time.Now().After(deadline) {
+			t.Fatal("主从切换后未连接至新的主节点")
+		}
+		_ = client.Set("key", "two")
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	val, err = client.Get("key")
+	assert.Nil(t, err)
+	assert.Equal(t, "two", val)
+
+	node, err := CreateBlockingNode(client)
+	assert.Nil(t, err)
+	node.Close()
+}
+
+func TestSentinelConfig(t *testing.T) {
+	assert.Equal(t, ErrEmptyMasterName, Config{
+		Type:          SentinelType,
+		SentinelAddrs: []string{"localhost:26379"},
+	}.Validate())
+	assert.Equal(t, ErrEmptySentinelAddrs, Config{
+		Type:       SentinelType,
+		MasterName: "mymaster",
+	}.Validate())
+}
@@ -0,0 +1,381 @@
+package redis
+
+import (
+	"context"
+	red "github.com/go-redis/redis/v8"
+	"strings"
+)
+
+type (
+	// XAddArgs 是 redis.XAddArgs 的别名，用于 XAdd 追加消息。
+	XAddArgs = red.XAddArgs
+	// XReadArgs 是 redis.XReadArgs 的别名，用于 XRead 读取消息。
+	// Streams 依次为流名称及起始 ID，如 s1 s2 0 0；Block 为负数时不阻塞，为 0 时一直阻塞。
+	XReadArgs = red.XReadArgs
+	// XReadGroupArgs 是 redis.XReadGroupArgs 的别名，用于 XReadGroup 以消费组读取消息。
+	XReadGroupArgs = red.XReadGroupArgs
+	// XPendingExtArgs 是 redis.XPendingExtArgs 的别名，用于 XPendingExt 查询待确认消息明细。
+	XPendingExtArgs = red.XPendingExtArgs
+	// XClaimArgs 是 redis.XClaimArgs 的别名，用于 XClaim 转移待确认消息。
+	XClaimArgs = red.XClaimArgs
+	// XAutoClaimArgs 是 redis.XAutoClaimArgs 的别名，用于 XAutoClaim 自动转移空闲的待确认消息。
+	XAutoClaimArgs = red.XAutoClaimArgs
+
+	// XMessage 是 redis.XMessage 的别名，表示一条流消息。
+	XMessage = red.XMessage
+	// XStream 是 redis.XStream 的别名，表示一个流及其消息。
+	XStream = red.XStream
+	// XPending 是 redis.XPending 的别名，表示消费组的待确认消息概况。
+	XPending = red.XPending
+	// XPendingExt 是 redis.XPendingExt 的别名，表示一条待确认消息的明细。
+	XPendingExt = red.XPendingExt
+	// XInfoStream 是 redis.XInfoStream 的别名，表示流的信息。
+	XInfoStream = red.XInfoStream
+	// XInfoGroup 是 redis.XInfoGroup 的别名，表示消费组的信息。
+	XInfoGroup = red.XInfoGroup
+	// XInfoConsumer 是 redis.XInfoConsumer 的别名，表示消费者的信息。
+	XInfoConsumer = red.XInfoConsumer
+)
+
+// XAck 确认消费组 group 已处理流 stream 中的消息 ids，返回确认的消息数量。
+func (r *Redis) XAck(stream, group string, ids ...string) (int64, error) {
+	return r.XAckCtx(context.Background(), stream, group, ids...)
+}
+
+// XAckCtx 确认消费组 group 已处理流 stream 中的消息 ids，返回确认的消息数量。
+func (r *Redis) XAckCtx(ctx context.Context, stream, group string, ids ...string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XAck(ctx, stream, group, ids...).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XAdd 向流追加消息，返回消息 ID。
+func (r *Redis) XAdd(a *XAddArgs) (string, error) {
+	return r.XAddCtx(context.Background(), a)
+}
+
+// XAddCtx 向流追加消息，返回消息 ID。
+func (r *Redis) XAddCtx(ctx context.Context, a *XAddArgs) (val string, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XAdd(ctx, a).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XAutoClaim 将空闲超过 MinIdle 的待确认消息转移给 Consumer，返回转移的消息及下次扫描的起始 ID。
+func (r *Redis) XAutoClaim(a *XAutoClaimArgs) ([]XMessage, string, error) {
+	return r.XAutoClaimCtx(context.Background(), a)
+}
+
+// XAutoClaimCtx 将空闲超过 MinIdle 的待确认消息转移给 Consumer，返回转移的消息及下次扫描的起始 ID。
+func (r *Redis) XAutoClaimCtx(ctx context.Context, a *XAutoClaimArgs) (val []XMessage, start string, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, start, err = node.XAutoClaim(ctx, a).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XClaim 将空闲超过 MinIdle 的给定待确认消息转移给 Consumer，返回转移的消息。
+func (r *Redis) XClaim(a *XClaimArgs) ([]XMessage, error) {
+	return r.XClaimCtx(context.Background(), a)
+}
+
+// XClaimCtx 将空闲超过 MinIdle 的给定待确认消息转移给 Consumer，返回转移的消息。
+func (r *Redis) XClaimCtx(ctx context.Context, a *XClaimArgs) (val []XMessage, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XClaim(ctx, a).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XGroupCreate 在流 stream 上创建消费组 group，start 为起始 ID，$ 表示仅消费新消息。
+// 流不存在或消费组已存在时返回错误。
+func (r *Redis) XGroupCreate(stream, group, start string) error {
+	return r.XGroupCreateCtx(context.Background(), stream, group, start)
+}
+
+// XGroupCreateCtx 在流 stream 上创建消费组 group，start 为起始 ID，$ 表示仅消费新消息。
+// 流不存在或消费组已存在时返回错误。
+func (r *Redis) XGroupCreateCtx(ctx context.Context, stream, group, start string) error {
+	return r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		return node.XGroupCreate(ctx, stream, group, start).Err()
+	}, groupAcceptable)
+}
+
+// XGroupCreateMkStream 在流 stream 上创建消费组 group，流不存在时自动创建，消费组已存在时返回错误。
+func (r *Redis) XGroupCreateMkStream(stream, group, start string) error {
+	return r.XGroupCreateMkStreamCtx(context.Background(), stream, group, start)
+}
+
+// XGroupCreateMkStreamCtx 在流 stream 上创建消费组 group，流不存在时自动创建，消费组已存在时返回错误。
+func (r *Redis) XGroupCreateMkStreamCtx(ctx context.Context, stream, group, start string) error {
+	return r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		return node.XGroupCreateMkStream(ctx, stream, group, start).Err()
+	}, groupAcceptable)
+}
+
+// XGroupDestroy 销毁流 stream 上的消费组 group，返回销毁的消费组数量。
+func (r *Redis) XGroupDestroy(stream, group string) (int64, error) {
+	return r.XGroupDestroyCtx(context.Background(), stream, group)
+}
+
+// XGroupDestroyCtx 销毁流 stream 上的消费组 group，返回销毁的消费组数量。
+func (r *Redis) XGroupDestroyCtx(ctx context.Context, stream, group string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XGroupDestroy(ctx, stream, group).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XInfoConsumers 返回流 stream 上消费组 group 的消费者信息。
+func (r *Redis) XInfoConsumers(stream, group string) ([]XInfoConsumer, error) {
+	return r.XInfoConsumersCtx(context.Background(), stream, group)
+}
+
+// XInfoConsumersCtx 返回流 stream 上消费组 group 的消费者信息。
+func (r *Redis) XInfoConsumersCtx(ctx context.Context, stream, group string) (val []XInfoConsumer, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XInfoConsumers(ctx, stream, group).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XInfoGroups 返回流 stream 的消费组信息。
+func (r *Redis) XInfoGroups(stream string) ([]XInfoGroup, error) {
+	return r.XInfoGroupsCtx(context.Background(), stream)
+}
+
+// XInfoGroupsCtx 返回流 stream 的消费组信息。
+func (r *Redis) XInfoGroupsCtx(ctx context.Context, stream string) (val []XInfoGroup, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XInfoGroups(ctx, stream).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XInfoStream 返回流 stream 的信息。
+func (r *Redis) XInfoStream(stream string) (*XInfoStream, error) {
+	return r.XInfoStreamCtx(context.Background(), stream)
+}
+
+// XInfoStreamCtx 返回流 stream 的信息。
+func (r *Redis) XInfoStreamCtx(ctx context.Context, stream string) (val *XInfoStream, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XInfoStream(ctx, stream).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XLen 返回流 stream 的消息数量。
+func (r *Redis) XLen(stream string) (int64, error) {
+	return r.XLenCtx(context.Background(), stream)
+}
+
+// XLenCtx 返回流 stream 的消息数量。
+func (r *Redis) XLenCtx(ctx context.Context, stream string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XLen(ctx, stream).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XPending 返回流 stream 上消费组 group 的待确认消息概况。
+func (r *Redis) XPending(stream, group string) (*XPending, error) {
+	return r.XPendingCtx(context.Background(), stream, group)
+}
+
+// XPendingCtx 返回流 stream 上消费组 group 的待确认消息概况。
+func (r *Redis) XPendingCtx(ctx context.Context, stream, group string) (val *XPending, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XPending(ctx, stream, group).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XPendingExt 返回消费组在 ID 区间内的待确认消息明细，可按消费者及空闲时长过滤。
+func (r *Redis) XPendingExt(a *XPendingExtArgs) ([]XPendingExt, error) {
+	return r.XPendingExtCtx(context.Background(), a)
+}
+
+// XPendingExtCtx 返回消费组在 ID 区间内的待确认消息明细，可按消费者及空闲时长过滤。
+func (r *Redis) XPendingExtCtx(ctx context.Context, a *XPendingExtArgs) (val []XPendingExt, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XPendingExt(ctx, a).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XRead 从一个或多个流中读取 ID 大于给定 ID 的消息，阻塞读取超时且无消息时返回 Nil。
+func (r *Redis) XRead(a *XReadArgs) ([]XStream, error) {
+	return r.XReadCtx(context.Background(), a)
+}
+
+// XReadCtx 从一个或多个流中读取 ID 大于给定 ID 的消息，阻塞读取超时且无消息时返回 Nil。
+func (r *Redis) XReadCtx(ctx context.Context, a *XReadArgs) (val []XStream, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XRead(ctx, a).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XReadGroup 以消费组中的消费者身份读取消息，ID 为 > 时读取从未投递的新消息，否则读取本消费者的待确认消息。
+// 阻塞读取超时且无消息时返回 Nil。
+func (r *Redis) XReadGroup(a *XReadGroupArgs) ([]XStream, error) {
+	return r.XReadGroupCtx(context.Background(), a)
+}
+
+// XReadGroupCtx 以消费组中的消费者身份读取消息，ID 为 > 时读取从未投递的新消息，否则读取本消费者的待确认消息。
+// 阻塞读取超时且无消息时返回 Nil。
+func (r *Redis) XReadGroupCtx(ctx context.Context, a *XReadGroupArgs) (val []XStream, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XReadGroup(ctx, a).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XTrimMaxLen 将流 stream 裁剪至最多 maxLen 条消息，返回删除的消息数量。
+func (r *Redis) XTrimMaxLen(stream string, maxLen int64) (int64, error) {
+	return r.XTrimMaxLenCtx(context.Background(), stream, maxLen)
+}
+
+// XTrimMaxLenCtx 将流 stream 裁剪至最多 maxLen 条消息，返回删除的消息数量。
+func (r *Redis) XTrimMaxLenCtx(ctx context.Context, stream string, maxLen int64) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XTrimMaxLen(ctx, stream, maxLen).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XTrimMinID 删除流 stream 中 ID 小于 minID 的消息，返回删除的消息数量。
+func (r *Redis) XTrimMinID(stream, minID string) (int64, error) {
+	return r.XTrimMinIDCtx(context.Background(), stream, minID)
+}
+
+// XTrimMinIDCtx 删除流 stream 中 ID 小于 minID 的消息，返回删除的消息数量。
+func (r *Redis) XTrimMinIDCtx(ctx context.Context, stream, minID string) (val int64, err error) {
+	err = r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		val, err = node.XTrimMinID(ctx, stream, minID).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// groupAcceptable 在 acceptable 的基础上，将消费组已存在视为正常结果，不计入断路器失败。
+func groupAcceptable(err error) bool {
+	return acceptable(err) || err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP")
+}
@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRedis_XAddRead(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		_, err := New(client.Addr, badType()).XAdd(&XAddArgs{Stream: "s", Values: []string{"k", "v"}})
+		assert.NotNil(t, err)
+
+		id, err := client.XAdd(&XAddArgs{Stream: "s", ID: "1-0", Values: []string{"k", "v1"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "1-0", id)
+		_, err = client.XAdd(&XAddArgs{Stream: "s", ID: "2-0", Values: map[string]any{"k": "v2"}})
+		assert.Nil(t, err)
+
+		n, err := client.XLen("s")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), n)
+
+		streams, err := client.XRead(&XReadArgs{Streams: []string{"s", "1-0"}, Block: -1})
+		assert.Nil(t, err)
+		assert.Len(t, streams, 1)
+		assert.Equal(t, "s", streams[0].Stream)
+		assert.Equal(t, []XMessage{{ID: "2-0", Values: map[string]any{"k": "v2"}}}, streams[0].Messages)
+
+		_, err = client.XRead(&XReadArgs{Streams: []string{"s", "2-0"}, Block: -1})
+		assert.Equal(t, Nil, err)
+
+		n, err = client.XTrimMaxLen("s", 1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+		_, err = client.XAdd(&XAddArgs{Stream: "s", ID: "3-0", Values: []string{"k", "v3"}})
+		assert.Nil(t, err)
+		n, err = client.XTrimMinID("s", "3-0")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		// miniredis 未完整实现 XINFO STREAM，仅验证错误返回。
+		_, err = New(client.Addr, badType()).XInfoStream("s")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRedis_XReadGroup(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		assert.NotNil(t, client.XGroupCreate("s", "g", "0"))
+		assert.Nil(t, client.XGroupCreateMkStream("s", "g", "0"))
+		err := client.XGroupCreate("s", "g", "0")
+		assert.NotNil(t, err)
+		assert.True(t, groupAcceptable(err))
+		assert.False(t, groupAcceptable(errors.New("any")))
+
+		for _, id := range []string{"1-0", "2-0"} {
+			_, err = client.XAdd(&XAddArgs{Stream: "s", ID: id, Values: []string{"k", id}})
+			assert.Nil(t, err)
+		}
+
+		streams, err := client.XReadGroup(&XReadGroupArgs{
+			Group:    "g",
+			Consumer: "c1",
+			Streams:  []string{"s", ">"},
+			Count:    10,
+			Block:    -1,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, streams[0].Messages, 2)
+
+		pending, err := client.XPending("s", "g")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), pending.Count)
+		assert.Equal(t, map[string]int64{"c1": 2}, pending.Consumers)
+
+		n, err := client.XAck("s", "g", "1-0")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		details, err := client.XPendingExt(&XPendingExtArgs{
+			Stream: "s",
+			Group:  "g",
+			Start:  "-",
+			End:    "+",
+			Count:  10,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, details, 1)
+		assert.Equal(t, "2-0", details[0].ID)
+
+		msgs, err := client.XClaim(&XClaimArgs{
+			Stream:   "s",
+			Group:    "g",
+			Consumer: "c2",
+			Messages: []string{"2-0"},
+		})
+		assert.Nil(t, err)
+		assert.Len(t, msgs, 1)
+
+		msgs, start, err := client.XAutoClaim(&XAutoClaimArgs{
+			Stream:   "s",
+			Group:    "g",
+			Consumer: "c3",
+			Start:    "0",
+			Count:    10,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, msgs, 1)
+		assert.Equal(t, "0-0", start)
+
+		groups, err := client.XInfoGroups("s")
+		assert.Nil(t, err)
+		assert.Len(t, groups, 1)
+		assert.Equal(t, "g", groups[0].Name)
+
+		// miniredis 未完整实现 XINFO CONSUMERS，仅验证错误返回。
+		_, err = New(client.Addr, badType()).XInfoConsumers("s", "g")
+		assert.NotNil(t, err)
+
+		n, err = client.XGroupDestroy("s", "g")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+}
@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	red "github.com/go-redis/redis/v8"
+)
+
+const (
+	// TxFailedErr 表示 WATCH 的键在事务执行前被修改，事务未执行。
+	TxFailedErr = red.TxFailedErr
+
+	// Watch 因冲突而重试的最大次数。
+	maxWatchRetries = 10
+)
+
+type (
+	// Tx 是 redis.Tx 的别名，表示 WATCH 了若干键的事务连接。
+	Tx = red.Tx
+
+	watcher interface {
+		Watch(ctx context.Context, fn func(*red.Tx) error, keys ...string) error
+	}
+)
+
+// TxPipelined 以 MULTI/EXEC 事务执行管道化函数。
+func (r *Redis) TxPipelined(fn func(Pipeliner) error) error {
+	return r.TxPipelinedCtx(context.Background(), fn)
+}
+
+// TxPipelinedCtx 以 MULTI/EXEC 事务执行管道化函数，fn 中的命令将原子执行。
+// 集群模式下所有键须位于同一槽位。
+func (r *Redis) TxPipelinedCtx(ctx context.Context, fn func(Pipeliner) error) error {
+	return r.brk.DoWithAcceptable(func() error {
+		node, err := getRedis(r)
+		if err != nil {
+			return err
+		}
+
+		_, err = node.TxPipelined(ctx, fn)
+		return err
+	}, acceptable)
+}
+
+// Watch 监视给定键并执行 fn，fn 中读取键后以 tx.TxPipelined 提交修改。
+// 提交前被监视的键被其他客户端修改时，重新执行 fn，重试 10 次后仍冲突则返回 TxFailedErr。
+// 集群模式下所有键须位于同一槽位。
+func (r *Redis) Watch(ctx context.Context, fn func(tx *Tx) error, keys ...string) error {
+	var err error
+	for i := 0; i < maxWatchRetries; i++ {
+		err = r.brk.DoWithAcceptable(func() error {
+			node, err := getRedis(r)
+			if err != nil {
+				return err
+			}
+
+			w, ok := node.(watcher)
+			if !ok {
+				return fmt.Errorf("redis 类型 '%s' 不支持 WATCH", r.Type)
+			}
+
+			return w.Watch(ctx, fn, keys...)
+		}, txAcceptable)
+		if err != TxFailedErr || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// txAcceptable 将乐观锁冲突视为可接受的错误，避免触发熔断。
+func txAcceptable(err error) bool {
+	return acceptable(err) || err == TxFailedErr
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestRedis_TxPipelined(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		assert.NotNil(t, New(client.Addr, badType()).TxPipelined(func(pipe Pipeliner) error {
+			return nil
+		}))
+
+		var incr *IntCmd
+		err := client.TxPipelined(func(pipe Pipeliner) error {
+			incr = pipe.Incr(context.Background(), "counter")
+			pipe.IncrBy(context.Background(), "counter", 2)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), incr.Val())
+		val, err := client.Get("counter")
+		assert.Nil(t, err)
+		assert.Equal(t, "3", val)
+	})
+}
+
+func TestRedis_Watch(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		ctx := context.Background()
+		assert.NotNil(t, New(client.Addr, badType()).Watch(ctx, func(tx *Tx) error {
+			return nil
+		}, "key"))
+
+		assert.Nil(t, client.Set("key", "1"))
+		incr := func(conflicts int) func(tx *Tx) error {
+			var attempts int
+			return func(tx *Tx) error {
+				n, err := tx.Get(ctx, "key").Int()
+				if err != nil {
+					return err
+				}
+
+				// 模拟其他客户端在提交前修改了键。
+				if attempts < conflicts {
+					attempts++
+					if err = client.Set("key", strconv.Itoa(n+10)); err != nil {
+						return err
+					}
+				}
+
+				_, err = tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+					pipe.Set(ctx, "key", n+1, 0)
+					return nil
+				})
+				return err
+			}
+		}
+
+		assert.Nil(t, client.Watch(ctx, incr(2), "key"))
+		val, err := client.Get("key")
+		assert.Nil(t, err)
+		assert.Equal(t, "22", val)
+
+		assert.Equal(t, TxFailedErr, client.Watch(ctx, incr(maxWatchRetries), "key"))
+	})
+}
@@ -3,10 +3,12 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"github.com/gotid/god/lib/logx"
 	"github.com/gotid/god/lib/store/cache"
 	"github.com/gotid/god/lib/store/redis"
 	"github.com/gotid/god/lib/store/sqlx"
 	"github.com/gotid/god/lib/syncx"
+	"github.com/gotid/god/lib/threading"
 	"time"
 )
 
@@ -24,8 +26,10 @@ var (
 type (
 	// CachedConn 是一个带缓存能力的数据库连接。
 	CachedConn struct {
-		db    sqlx.Conn
-		cache cache.Cache
+		db       sqlx.Conn
+		cache    cache.Cache
+		delDelay time.Duration
+		owner    *txOwner
 	}
 
 	// ExecFn 定义 sql 执行方法。
@@ -64,16 +68,34 @@ func NewConnWithCache(db sqlx.Conn, c cache.Cache) CachedConn {
 	return CachedConn{
 		db:    db,
 		cache: c,
+		owner: new(txOwner),
 	}
 }
 
+// WithDoubleDelete 返回一个启用延迟双删的 CachedConn，
+// 执行写操作或事务提交并删除缓存后，延迟 delay 再删除一次，以覆盖主从同步延迟期间回填的旧值。
+func (cc CachedConn) WithDoubleDelete(delay time.Duration) CachedConn {
+	cc.delDelay = delay
+	return cc
+}
+
 // DelCache 删除给定键的缓存。
 func (cc CachedConn) DelCache(keys ...string) error {
 	return cc.DelCacheCtx(context.Background(), keys...)
 }
 
 // DelCacheCtx 删除给定键的缓存。
+// 在 TransactCtx 的事务函数中以其 ctx 调用时，删除将推迟至事务提交后执行，事务回滚则不删除。
 func (cc CachedConn) DelCacheCtx(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if pending, ok := pendingKeysFromContext(ctx, cc.owner); ok {
+		pending.add(keys...)
+		return nil
+	}
+
 	return cc.cache.DelCtx(ctx, keys...)
 }
 
@@ -103,9 +125,14 @@ func (cc CachedConn) ExecCtx(ctx context.Context, exec ExecCtxFn, keys ...string
 		return nil, err
 	}
 
+	if _, ok := pendingKeysFromContext(ctx, cc.owner); ok {
+		return res, cc.DelCacheCtx(ctx, keys...)
+	}
+
 	if err := cc.DelCacheCtx(ctx, keys...); err != nil {
 		return nil, err
 	}
+	cc.delayDelete(keys...)
 
 	return res, nil
 }
@@ -173,8 +200,9 @@ func (cc CachedConn) QueryRowIndexCtx(ctx context.Context, v any, key string,
 		return nil
 	}
 
+	// 开启旧值返回时 query 可能在后台刷新缓存，须填充传入的 val 而非调用者的 v。
 	return cc.cache.TakeCtx(ctx, v, keyer(primaryKey), func(val any) error {
-		return primaryQuery(ctx, cc.db, v, primaryKey)
+		return primaryQuery(ctx, cc.db, val, primaryKey)
 	})
 }
 
@@ -222,6 +250,43 @@ func (cc CachedConn) Transact(fn func(sqlx.Session) error) error {
 }
 
 // TransactCtx 在事务模式中运行给定函数。
+// 底层连接开启了 sqlx.WithTransactRetry 时，死锁等可重试错误将自动重试整个函数。
+// 事务函数中以其 ctx 调用本连接的 DelCacheCtx 或 ExecCtx 删除的缓存，将在事务提交后统一删除，回滚则丢弃，
+// 避免提交前并发读取回填旧值。提交后删除缓存失败仅记录日志，不返回错误。
 func (cc CachedConn) TransactCtx(ctx context.Context, fn func(context.Context, sqlx.Session) error) error {
-	return cc.db.TransactCtx(ctx, fn)
+	pending := newPendingKeys()
+	err := cc.db.TransactCtx(withPendingKeys(ctx, cc.owner, pending), func(ctx context.Context, session sqlx.Session) error {
+		pending.reset()
+		return fn(ctx, session)
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := pending.take()
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 事务已提交，删除缓存失败仅记录日志，以免调用方误以为事务失败。
+	if err = cc.cache.DelCtx(ctx, keys...); err != nil {
+		logx.WithContext(ctx).Errorf("事务提交后删除缓存失败，键：%q，错误：%v", keys, err)
+	}
+	cc.delayDelete(keys...)
+
+	return nil
+}
+
+func (cc CachedConn) delayDelete(keys ...string) {
+	if cc.delDelay <= 0 || len(keys) == 0 {
+		return
+	}
+
+	time.AfterFunc(cc.delDelay, func() {
+		threading.RunSafe(func() {
+			if err := cc.cache.DelCtx(context.Background(), keys...); err != nil {
+				logx.Errorf("延迟删除缓存 %q 失败：%v", keys, err)
+			}
+		})
+	})
 }
@@ -118,6 +118,62 @@ func TestCachedConn_QueryRowIndex_NoCache(t *testing.T) {
 	assert.Equal(t, `"designer"`, val)
 }
 
+func TestCachedConn_QueryRowIndex_StaleWhileRevalidate(t *testing.T) {
+	resetStats()
+	r, clean, err := redistest.CreateRedis()
+	assert.Nil(t, err)
+	defer clean()
+
+	c := NewNodeConn(dummySqlConn{}, r, cache.WithExpire(time.Millisecond*100),
+		cache.WithStaleWhileRevalidate(time.Minute))
+
+	var indexQueries, primaryQueries int32
+	keyer := func(s any) string {
+		return fmt.Sprintf("%v/1234", s)
+	}
+	indexQuery := func(_ context.Context, _ sqlx.Conn, v any) (any, error) {
+		atomic.AddInt32(&indexQueries, 1)
+		*v.(*string) = "designer"
+		return "primary", nil
+	}
+	primaryQuery := func(_ context.Context, _ sqlx.Conn, v, primary any) error {
+		atomic.AddInt32(&primaryQueries, 1)
+		assert.Equal(t, "primary", primary)
+		*v.(*string) = "xin"
+		return nil
+	}
+
+	var str string
+	assert.Nil(t, c.QueryRowIndexCtx(context.Background(), &str, "index", keyer, indexQuery, primaryQuery))
+	assert.Equal(t, "designer", str)
+
+	// 索引缓存过期后同步查询索引，而非在后台以调用者的变量刷新。
+	time.Sleep(time.Millisecond * 150)
+	str = ""
+	assert.Nil(t, c.QueryRowIndexCtx(context.Background(), &str, "index", keyer, indexQuery, primaryQuery))
+	assert.Equal(t, "designer", str)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&indexQueries))
+
+	// 主键缓存过期时沿用旧值返回，后台刷新写入主键缓存而非调用者的变量。
+	assert.Nil(t, r.Set("index", `"primary"`))
+	assert.Nil(t, r.Set("primary/1234", `~1:0:"designer"`))
+	str = ""
+	assert.Nil(t, c.QueryRowIndexCtx(context.Background(), &str, "index", keyer, indexQuery, primaryQuery))
+	assert.Equal(t, "designer", str)
+	assert.Eventually(t, func() bool {
+		ok, err := r.Exists("primary/1234:refresh")
+		return err == nil && !ok && atomic.LoadInt32(&primaryQueries) == 1
+	}, time.Second, time.Millisecond*10)
+	assert.Equal(t, "designer", str)
+
+	var cached string
+	assert.Nil(t, c.GetCache("primary/1234", &cached))
+	assert.Equal(t, "xin", cached)
+	keys, err := r.Keys("*")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"index", "primary/1234"}, keys)
+}
+
 func TestCachedConn_QueryRowIndex_HasCache(t *testing.T) {
 	resetStats()
 	r, clean, err := redistest.CreateRedis()
@@ -640,6 +696,30 @@ func (d dummySqlConn) QueryRowsPartial(v any, query string, args ...any) error {
 	return nil
 }
 
+func (d dummySqlConn) ExecNamed(query string, arg any) (sql.Result, error) {
+	return nil, nil
+}
+
+func (d dummySqlConn) ExecNamedCtx(ctx context.Context, query string, arg any) (sql.Result, error) {
+	return nil, nil
+}
+
+func (d dummySqlConn) QueryRowNamed(v any, query string, arg any) error {
+	return nil
+}
+
+func (d dummySqlConn) QueryRowNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	return nil
+}
+
+func (d dummySqlConn) QueryRowsNamed(v any, query string, arg any) error {
+	return nil
+}
+
+func (d dummySqlConn) QueryRowsNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	return nil
+}
+
 func (d dummySqlConn) RawDB() (*sql.DB, error) {
 	return nil, nil
 }
@@ -687,3 +767,139 @@ func (c *trackedConn) TransactCtx(ctx context.Context, fn func(context.Context,
 	c.transactValue = true
 	return c.dummySqlConn.TransactCtx(ctx, fn)
 }
+
+func TestCachedConnTransactDelCache(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	const key = "user"
+	c := NewNodeConn(txConn{}, redis.New(r.Addr()), cache.WithExpire(time.Second*30))
+	assert.Nil(t, c.SetCache(key, "any"))
+
+	err = c.TransactCtx(context.Background(), func(ctx context.Context, session sqlx.Session) error {
+		_, err := c.ExecCtx(ctx, func(ctx context.Context, conn sqlx.Conn) (sql.Result, error) {
+			return nil, nil
+		}, key)
+		assert.Nil(t, err)
+		assert.Nil(t, c.DelCacheCtx(ctx, key))
+		assert.True(t, r.Exists(key))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.False(t, r.Exists(key))
+}
+
+func TestCachedConnTransactOtherConn(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	rds := redis.New(r.Addr())
+	c := NewNodeConn(txConn{}, rds, cache.WithExpire(time.Second*30))
+	other := NewNodeConn(dummySqlConn{}, rds, cache.WithExpire(time.Second*30))
+	assert.Nil(t, c.SetCache("user", "any"))
+	assert.Nil(t, other.SetCache("order", "any"))
+
+	errDummy := errors.New("dummy")
+	err = c.TransactCtx(context.Background(), func(ctx context.Context, session sqlx.Session) error {
+		assert.Nil(t, c.DelCacheCtx(ctx, "user"))
+		// 其他连接不参与本事务，立即删除。
+		assert.Nil(t, other.DelCacheCtx(ctx, "order"))
+		assert.False(t, r.Exists("order"))
+		return errDummy
+	})
+	assert.Equal(t, errDummy, err)
+	assert.True(t, r.Exists("user"))
+}
+
+func TestCachedConnTransactDelCacheFailed(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	c := NewNodeConn(txConn{}, redis.New(r.Addr()), cache.WithExpire(time.Second*30))
+	err = c.TransactCtx(context.Background(), func(ctx context.Context, session sqlx.Session) error {
+		assert.Nil(t, c.DelCacheCtx(ctx, "user"))
+		r.Close()
+		return nil
+	})
+	// 事务已提交，删除缓存失败不返回错误。
+	assert.Nil(t, err)
+}
+
+func TestCachedConnTransactRollbackKeepsCache(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	const key = "user"
+	errDummy := errors.New("dummy")
+	c := NewNodeConn(txConn{}, redis.New(r.Addr()), cache.WithExpire(time.Second*30))
+	assert.Nil(t, c.SetCache(key, "any"))
+
+	err = c.TransactCtx(context.Background(), func(ctx context.Context, session sqlx.Session) error {
+		assert.Nil(t, c.DelCacheCtx(ctx, key))
+		return errDummy
+	})
+	assert.Equal(t, errDummy, err)
+	assert.True(t, r.Exists(key))
+}
+
+func TestCachedConnTransactRetryResetsKeys(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNodeConn(txConn{retries: 1}, redis.New(r.Addr()), cache.WithExpire(time.Second*30))
+	assert.Nil(t, c.SetCache("first", "any"))
+	assert.Nil(t, c.SetCache("second", "any"))
+
+	var attempts int
+	err = c.TransactCtx(context.Background(), func(ctx context.Context, session sqlx.Session) error {
+		attempts++
+		if attempts == 1 {
+			return c.DelCacheCtx(ctx, "first")
+		}
+		return c.DelCacheCtx(ctx, "second")
+	})
+	assert.Nil(t, err)
+	assert.True(t, r.Exists("first"))
+	assert.False(t, r.Exists("second"))
+}
+
+func TestCachedConnDoubleDelete(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	const key = "user"
+	c := NewNodeConn(txConn{}, redis.New(r.Addr()), cache.WithExpire(time.Second*30)).
+		WithDoubleDelete(time.Millisecond * 50)
+
+	_, err = c.Exec(func(conn sqlx.Conn) (sql.Result, error) {
+		return nil, nil
+	}, key)
+	assert.Nil(t, err)
+	// 模拟删除后并发读取回填的旧值。
+	assert.Nil(t, c.SetCache(key, "stale"))
+	assert.Eventually(t, func() bool {
+		return !r.Exists(key)
+	}, time.Second, time.Millisecond*10)
+}
+
+// txConn 模拟事务，retries 为事务函数成功后模拟的可重试失败次数。
+type txConn struct {
+	dummySqlConn
+	retries int
+}
+
+func (c txConn) TransactCtx(ctx context.Context, fn func(context.Context, sqlx.Session) error) error {
+	for i := 0; ; i++ {
+		if err := fn(ctx, nil); err != nil {
+			return err
+		}
+		if i >= c.retries {
+			return nil
+		}
+	}
+}
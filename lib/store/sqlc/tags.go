@@ -0,0 +1,83 @@
+package sqlc
+
+import (
+	"context"
+	"github.com/gotid/god/lib/hash"
+	"github.com/gotid/god/lib/store/sqlx"
+	"github.com/gotid/god/lib/stringx"
+	"strings"
+)
+
+const (
+	tagKeyPrefix  = "sqlc:tag:"
+	tagVersionLen = 16
+)
+
+// TagKey 返回标签对应的缓存键，可作为 ExecCtx 或 DelCacheCtx 的键使标签下的全部列表缓存失效。
+func TagKey(tag string) string {
+	return tagKeyPrefix + tag
+}
+
+// TagKeys 返回多个标签对应的缓存键。
+func TagKeys(tags ...string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = TagKey(tag)
+	}
+
+	return keys
+}
+
+// DelTags 使给定标签下的全部列表缓存失效。
+func (cc CachedConn) DelTags(tags ...string) error {
+	return cc.DelTagsCtx(context.Background(), tags...)
+}
+
+// DelTagsCtx 使给定标签下的全部列表缓存失效，事务中调用时推迟至提交后执行。
+func (cc CachedConn) DelTagsCtx(ctx context.Context, tags ...string) error {
+	return cc.DelCacheCtx(ctx, TagKeys(tags...)...)
+}
+
+// QueryRows 查询缓存键 key 的列表到变量 v，参见 QueryRowsCtx。
+func (cc CachedConn) QueryRows(v any, key string, query QueryFn, tags ...string) error {
+	queryCtx := func(_ context.Context, conn sqlx.Conn, v any) error {
+		return query(conn, v)
+	}
+
+	return cc.QueryRowsCtx(context.Background(), v, key, queryCtx, tags...)
+}
+
+// QueryRowsCtx 查询缓存键 key 的列表到变量 v，若无缓存则使用 query 函数查询并缓存。
+// tags 为列表的失效标签，如 user:123:orders，删除任一标签（DelTagsCtx 或以 TagKey 为键的 ExecCtx）
+// 都将使该列表缓存失效，调用方无需记录每个列表的缓存键。
+// 实现上每个标签对应一个随机版本号，列表的实际缓存键包含全部标签的版本号，删除标签即更换版本号，
+// 旧版本的列表缓存不再被访问，并随过期时间自然淘汰。
+func (cc CachedConn) QueryRowsCtx(ctx context.Context, v any, key string, query QueryCtxFn,
+	tags ...string) error {
+	taggedKey, err := cc.taggedKey(ctx, key, tags)
+	if err != nil {
+		return err
+	}
+
+	return cc.cache.TakeCtx(ctx, v, taggedKey, func(val any) error {
+		return query(ctx, cc.db, val)
+	})
+}
+
+func (cc CachedConn) taggedKey(ctx context.Context, key string, tags []string) (string, error) {
+	if len(tags) == 0 {
+		return key, nil
+	}
+
+	versions := make([]string, len(tags))
+	for i, tag := range tags {
+		if err := cc.cache.TakeCtx(ctx, &versions[i], TagKey(tag), func(val any) error {
+			*val.(*string) = stringx.Randn(tagVersionLen)
+			return nil
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return key + "#" + hash.Md5Hex([]byte(strings.Join(versions, ","))), nil
+}
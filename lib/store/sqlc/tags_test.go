@@ -0,0 +1,92 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gotid/god/lib/store/cache"
+	"github.com/gotid/god/lib/store/redis"
+	"github.com/gotid/god/lib/store/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCachedConnQueryRowsWithTags(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNodeConn(txConn{}, redis.New(r.Addr()), cache.WithExpire(time.Second*30))
+	var queries int
+	query := func(conn sqlx.Conn, v any) error {
+		queries++
+		*v.(*[]int) = []int{1, 2, queries}
+		return nil
+	}
+
+	var orders []int
+	assert.Nil(t, c.QueryRows(&orders, "orders:user:1:page:1", query, "user:1:orders"))
+	assert.Equal(t, []int{1, 2, 1}, orders)
+	assert.Nil(t, c.QueryRows(&orders, "orders:user:1:page:1", query, "user:1:orders"))
+	assert.Equal(t, []int{1, 2, 1}, orders)
+	assert.Equal(t, 1, queries)
+
+	var others []int
+	assert.Nil(t, c.QueryRows(&others, "orders:user:2:page:1", query, "user:2:orders"))
+	assert.Equal(t, 2, queries)
+
+	_, err = c.Exec(func(conn sqlx.Conn) (sql.Result, error) {
+		return nil, nil
+	}, TagKey("user:1:orders"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.QueryRows(&orders, "orders:user:1:page:1", query, "user:1:orders"))
+	assert.Equal(t, []int{1, 2, 3}, orders)
+	assert.Nil(t, c.QueryRows(&others, "orders:user:2:page:1", query, "user:2:orders"))
+	assert.Equal(t, 3, queries)
+}
+
+func TestCachedConnQueryRowsWithoutTags(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNodeConn(txConn{}, redis.New(r.Addr()), cache.WithExpire(time.Second*30))
+	var names []string
+	assert.Nil(t, c.QueryRowsCtx(context.Background(), &names, "names",
+		func(ctx context.Context, conn sqlx.Conn, v any) error {
+			*v.(*[]string) = []string{"a", "b"}
+			return nil
+		}))
+	assert.True(t, r.Exists("names"))
+	assert.Nil(t, c.DelCache("names"))
+}
+
+func TestCachedConnDelTagsInTransaction(t *testing.T) {
+	r, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer r.Close()
+
+	c := NewNodeConn(txConn{}, redis.New(r.Addr()), cache.WithExpire(time.Second*30))
+	var queries int
+	query := func(conn sqlx.Conn, v any) error {
+		queries++
+		*v.(*[]int) = []int{queries}
+		return nil
+	}
+
+	var ids []int
+	assert.Nil(t, c.QueryRows(&ids, "ids", query, "a", "b"))
+	assert.Nil(t, c.TransactCtx(context.Background(), func(ctx context.Context, session sqlx.Session) error {
+		assert.Nil(t, c.DelTagsCtx(ctx, "b"))
+		assert.True(t, r.Exists(TagKey("b")))
+		return nil
+	}))
+	assert.False(t, r.Exists(TagKey("b")))
+
+	assert.Nil(t, c.QueryRows(&ids, "ids", query, "a", "b"))
+	assert.Equal(t, []int{2}, ids)
+	assert.Equal(t, []string{TagKey("a"), TagKey("b")}, TagKeys("a", "b"))
+	assert.Nil(t, c.DelTags("a"))
+}
@@ -0,0 +1,73 @@
+package sqlc
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// contextKey 以 CachedConn 的 owner 区分事务中待删除的缓存键，
+	// 避免其他 CachedConn 以同一 ctx 删除的缓存被推迟或误删。
+	contextKey struct {
+		owner *txOwner
+	}
+
+	// txOwner 标识一个 CachedConn。
+	txOwner struct {
+		_ byte
+	}
+
+	// pendingKeys 收集事务中待删除的缓存键，事务提交后统一删除，回滚则丢弃。
+	pendingKeys struct {
+		lock sync.Mutex
+		keys []string
+		seen map[string]struct{}
+	}
+)
+
+func newPendingKeys() *pendingKeys {
+	return &pendingKeys{
+		seen: make(map[string]struct{}),
+	}
+}
+
+func withPendingKeys(ctx context.Context, owner *txOwner, pending *pendingKeys) context.Context {
+	return context.WithValue(ctx, contextKey{owner: owner}, pending)
+}
+
+func pendingKeysFromContext(ctx context.Context, owner *txOwner) (*pendingKeys, bool) {
+	pending, ok := ctx.Value(contextKey{owner: owner}).(*pendingKeys)
+	return pending, ok
+}
+
+func (p *pendingKeys) add(keys ...string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, key := range keys {
+		if _, ok := p.seen[key]; ok {
+			continue
+		}
+
+		p.seen[key] = struct{}{}
+		p.keys = append(p.keys, key)
+	}
+}
+
+// reset 清空已收集的键，用于事务重试时丢弃上次尝试收集的键。
+func (p *pendingKeys) reset() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.keys = nil
+	p.seen = make(map[string]struct{})
+}
+
+func (p *pendingKeys) take() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	keys := p.keys
+	p.keys = nil
+	return keys
+}
@@ -1,7 +1,9 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/gotid/god/lib/executors"
 	"github.com/gotid/god/lib/logx"
@@ -14,39 +16,64 @@ const (
 	valuesKeyword = "values"
 	flushInterval = time.Second
 	maxBulkRows   = 1000
+	// 批次之间的分隔符 ", " 的长度
+	separatorBytes = 2
 )
 
-var emptyBulkStmt bulkStmt
+var (
+	emptyBulkStmt bulkStmt
+
+	errNoResult = errors.New("批次执行失败，没有执行结果")
+)
 
 type (
 	// BulkInserter 用于批量插入记录。
-	// 暂不支持 pg 和 oracle，因为它俩使用 `$` 和 `:` 进行格式化。
+	// 支持 MySQL 的 `?` 和 PostgreSQL 的 `$n` 占位符，暂不支持 oracle 的 `:n`。
+	// 支持 MySQL 的 ON DUPLICATE KEY UPDATE 及 PostgreSQL 的 ON CONFLICT 后缀，用于批量插入或更新。
 	BulkInserter struct {
 		executor *executors.PeriodicalExecutor
 		inserter *dbInserter
 		stmt     bulkStmt
 	}
 
-	// ResultHandler 是一个 sql.Result 结果处理函数。
+	// BulkOption 自定义 BulkInserter。
+	BulkOption func(in *dbInserter)
+
+	// ResultHandler 是一个 sql.Result 结果处理函数，每个批次调用一次，
+	// 批次执行成功时收到的 sql.Result 为 BatchResult，失败时为 nil。
 	ResultHandler func(sql.Result, error)
 
+	// BatchResult 是一个批次的执行结果。
+	BatchResult struct {
+		sql.Result
+		// Rows 为本批次提交的行数，与 RowsAffected 不同，
+		// MySQL 的 upsert 中更新的行计为 2 行影响，未变化的行计为 0 行。
+		Rows int
+	}
+
 	bulkStmt struct {
 		prefix      string
 		valueFormat string
 		suffix      string
+		postgres    bool
 	}
 )
 
 // NewBulkInserter 返回一个批量插入器 BulkInserter。
-func NewBulkInserter(conn Conn, stmt string) (*BulkInserter, error) {
+// 默认每 1000 行或每秒写入一次，可通过 WithBulkRows 及 WithBulkBytes 调整。
+func NewBulkInserter(conn Conn, stmt string, opts ...BulkOption) (*BulkInserter, error) {
 	bkStmt, err := parseInsertStmt(stmt)
 	if err != nil {
 		return nil, err
 	}
 
 	inserter := &dbInserter{
-		conn: conn,
-		stmt: bkStmt,
+		conn:    conn,
+		stmt:    bkStmt,
+		maxRows: maxBulkRows,
+	}
+	for _, opt := range opts {
+		opt(inserter)
 	}
 
 	return &BulkInserter{
@@ -56,14 +83,48 @@ func NewBulkInserter(conn Conn, stmt string) (*BulkInserter, error) {
 	}, nil
 }
 
+// WithBulkRows 设置每批次的最大行数，默认 1000 行。
+func WithBulkRows(rows int) BulkOption {
+	return func(in *dbInserter) {
+		if rows > 0 {
+			in.maxRows = rows
+		}
+	}
+}
+
+// WithBulkBytes 设置每条语句的最大字节数，如小于 MySQL 的 max_allowed_packet，默认不限。
+// 挂起的语句达到该大小即写入，写入时超出的部分拆分为多条语句，单行超出时独自成句。
+func WithBulkBytes(bytes int) BulkOption {
+	return func(in *dbInserter) {
+		in.maxBytes = bytes
+	}
+}
+
 // Flush 流转所有挂起的任务。
 func (bi *BulkInserter) Flush() {
 	bi.executor.Flush()
 }
 
+// FlushCtx 在 ctx 的控制下立即写入所有挂起的记录，返回首个批次错误。
+// 写入前等待执行中的批次完成，以保持批次顺序；结果处理器仍会收到每个批次的结果。
+func (bi *BulkInserter) FlushCtx(ctx context.Context) error {
+	var err error
+	bi.executor.FlushWith(func(tasks any) {
+		var stmt bulkStmt
+		var handler ResultHandler
+		bi.executor.Sync(func() {
+			stmt = bi.inserter.stmt
+			handler = bi.inserter.resultHandler
+		})
+		err = bi.inserter.execute(ctx, stmt, handler, tasks.([]string))
+	})
+
+	return err
+}
+
 // Insert 插入给定的参数。
 func (bi *BulkInserter) Insert(args ...any) error {
-	value, err := format(bi.stmt.valueFormat, args...)
+	value, err := bi.stmt.format(args...)
 	if err != nil {
 		return err
 	}
@@ -95,54 +156,91 @@ func (bi *BulkInserter) UpdateStmt(stmt string) error {
 
 	bi.executor.Flush()
 	bi.executor.Sync(func() {
+		bi.stmt = bkStmt
 		bi.inserter.stmt = bkStmt
 	})
 
 	return nil
 }
 
+// LastInsertId 返回批次的最后插入 ID，批次执行失败时返回错误。
+func (r BatchResult) LastInsertId() (int64, error) {
+	if r.Result == nil {
+		return 0, errNoResult
+	}
+
+	return r.Result.LastInsertId()
+}
+
+// RowsAffected 返回批次影响的行数，批次执行失败时返回错误。
+func (r BatchResult) RowsAffected() (int64, error) {
+	if r.Result == nil {
+		return 0, errNoResult
+	}
+
+	return r.Result.RowsAffected()
+}
+
 // db 插入器的任务容器
 type dbInserter struct {
 	conn          Conn
 	stmt          bulkStmt
 	values        []string
+	bytes         int
+	maxRows       int
+	maxBytes      int
 	resultHandler ResultHandler
 }
 
 func (in *dbInserter) AddTask(task any) bool {
-	in.values = append(in.values, task.(string))
-	return len(in.values) >= maxBulkRows
+	value := task.(string)
+	in.values = append(in.values, value)
+	in.bytes += len(value) + separatorBytes
+
+	return len(in.values) >= in.maxRows || in.maxBytes > 0 && in.stmt.size()+in.bytes >= in.maxBytes
 }
 
 func (in *dbInserter) Execute(tasks any) {
-	values := tasks.([]string)
-	if len(values) == 0 {
-		return
-	}
-
-	stmtWithoutValues := in.stmt.prefix
-	valueStr := strings.Join(values, ", ")
-	stmt := strings.Join([]string{stmtWithoutValues, valueStr}, " ")
-	if len(in.stmt.suffix) > 0 {
-		stmt = strings.Join([]string{stmt, in.stmt.suffix}, " ")
-	}
-	result, err := in.conn.Exec(stmt)
-	if in.resultHandler != nil {
-		in.resultHandler(result, err)
-	} else if err != nil {
-		logx.Errorf("SQL：%s，执行错误：%s", stmt, err)
-	}
+	_ = in.execute(context.Background(), in.stmt, in.resultHandler, tasks.([]string))
 }
 
 func (in *dbInserter) RemoveAll() any {
 	values := in.values
 	in.values = nil
+	in.bytes = 0
 	return values
 }
 
+// execute 按字节数拆分批次并依次写入，返回首个错误。
+func (in *dbInserter) execute(ctx context.Context, stmt bulkStmt, handler ResultHandler, values []string) error {
+	var firstErr error
+	for len(values) > 0 {
+		n := stmt.batchRows(values, in.maxBytes)
+		query := stmt.build(values[:n])
+		result, err := in.conn.ExecCtx(ctx, query)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		if handler != nil {
+			if err != nil {
+				handler(nil, err)
+			} else {
+				handler(BatchResult{Result: result, Rows: n}, nil)
+			}
+		} else if err != nil {
+			logx.WithContext(ctx).Errorf("SQL：%s，执行错误：%s", query, err)
+		}
+
+		values = values[n:]
+	}
+
+	return firstErr
+}
+
 func parseInsertStmt(stmt string) (bulkStmt, error) {
 	lower := strings.ToLower(stmt)
-	pos := strings.Index(lower, valuesKeyword)
+	pos := indexKeyword(lower, valuesKeyword)
 	if pos <= 0 {
 		return emptyBulkStmt, fmt.Errorf("错误的 SQL 插入语句：%q", stmt)
 	}
@@ -164,33 +262,190 @@ func parseInsertStmt(stmt string) (bulkStmt, error) {
 	}
 
 	var variables int
+	var postgres bool
 	var valueFormat string
 	var suffix string
-	left := strings.IndexByte(lower[pos:], '(')
-	if left > 0 {
-		right = strings.IndexByte(lower[pos+left:], ')')
-		if right > 0 {
-			values := lower[pos+left : pos+left+right]
-			for _, x := range values {
-				if x == '?' {
-					variables++
-				}
+	rest := strings.TrimLeft(lower[pos+len(valuesKeyword):], " \t\r\n")
+	left := len(lower) - len(rest)
+	if strings.HasPrefix(rest, "(") {
+		// 按括号配对查找值模板的结尾，值模板中可包含 now() 等函数调用
+		if right = matchParen(lower, left); right > 0 {
+			values := lower[left:right]
+			variables = strings.Count(values, "?")
+			if variables == 0 {
+				variables = countPostgresVariables(values)
+				postgres = variables > 0
 			}
-			valueFormat = stmt[pos+left : pos+left+right+1]
-			suffix = strings.TrimSpace(stmt[pos+left+right+1:])
+			valueFormat = stmt[left : right+1]
+			suffix = strings.TrimRight(strings.TrimSpace(stmt[right+1:]), ";")
 		}
 	}
 
 	if variables == 0 {
 		return emptyBulkStmt, fmt.Errorf("SQL 插入语句没有变量：%q", stmt)
 	}
-	if columns > 0 && columns != variables {
+	if columns > 0 && columns != countValueExprs(valueFormat) {
 		return emptyBulkStmt, fmt.Errorf("字段和变量的个数不一致：%q", stmt)
 	}
+	if err := checkUpsertSuffix(suffix, postgres); err != nil {
+		return emptyBulkStmt, fmt.Errorf("%s：%q", err, stmt)
+	}
 
 	return bulkStmt{
 		prefix:      stmt[:pos+len(valuesKeyword)],
 		valueFormat: valueFormat,
-		suffix:      suffix,
+		suffix:      strings.TrimSpace(suffix),
+		postgres:    postgres,
 	}, nil
 }
+
+// checkUpsertSuffix 校验 upsert 后缀与占位符的方言是否一致。
+func checkUpsertSuffix(suffix string, postgres bool) error {
+	lower := strings.ToLower(strings.Join(strings.Fields(suffix), " "))
+	switch {
+	case strings.HasPrefix(lower, "on duplicate key update"):
+		if postgres {
+			return errors.New("PostgreSQL 不支持 ON DUPLICATE KEY UPDATE")
+		}
+	case strings.HasPrefix(lower, "on conflict"):
+		if !postgres {
+			return errors.New("MySQL 不支持 ON CONFLICT")
+		}
+	}
+
+	return nil
+}
+
+// indexKeyword 返回不在引号内且前后不与标识符相连的关键字位置。
+func indexKeyword(lower, keyword string) int {
+	var quote byte
+	for i := 0; i < len(lower); i++ {
+		ch := lower[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case strings.HasPrefix(lower[i:], keyword) &&
+			(i == 0 || !isNameChar(lower[i-1], false)) &&
+			(i+len(keyword) == len(lower) || !isNameChar(lower[i+len(keyword)], false)):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// countValueExprs 返回值模板中以逗号分隔的表达式个数，如 (?, ?, now()) 为 3 个。
+func countValueExprs(valueFormat string) int {
+	count := 1
+	var depth int
+	var quote byte
+	for i := 0; i < len(valueFormat); i++ {
+		ch := valueFormat[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 1:
+			count++
+		}
+	}
+
+	return count
+}
+
+// matchParen 返回与 left 处左括号配对的右括号位置，忽略引号内的括号，不存在则返回 -1。
+func matchParen(s string, left int) int {
+	var depth int
+	var quote byte
+	for i := left; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// 统计 pg 风格 `$n` 占位符的个数，同一序号仅计一次。
+func countPostgresVariables(values string) int {
+	indexes := make(map[string]struct{})
+	for i := 0; i < len(values); i++ {
+		if values[i] != '$' {
+			continue
+		}
+
+		j := i + 1
+		for j < len(values) && '0' <= values[j] && values[j] <= '9' {
+			j++
+		}
+		if j > i+1 {
+			indexes[values[i+1:j]] = struct{}{}
+			i = j - 1
+		}
+	}
+
+	return len(indexes)
+}
+
+func (s bulkStmt) format(args ...any) (string, error) {
+	if s.postgres {
+		return interpolatePostgres(s.valueFormat, args...)
+	}
+
+	return interpolate(s.valueFormat, args...)
+}
+
+// batchRows 返回从 values 开头取出的、不超过 maxBytes 的行数，至少为 1 行。
+func (s bulkStmt) batchRows(values []string, maxBytes int) int {
+	if maxBytes <= 0 {
+		return len(values)
+	}
+
+	size := s.size()
+	for i, value := range values {
+		size += len(value) + separatorBytes
+		if size > maxBytes && i > 0 {
+			return i
+		}
+	}
+
+	return len(values)
+}
+
+func (s bulkStmt) build(values []string) string {
+	stmt := strings.Join([]string{s.prefix, strings.Join(values, ", ")}, " ")
+	if len(s.suffix) > 0 {
+		stmt = strings.Join([]string{stmt, s.suffix}, " ")
+	}
+
+	return stmt
+}
+
+// size 返回语句中除值以外部分的字节数。
+func (s bulkStmt) size() int {
+	return len(s.prefix) + len(s.suffix) + separatorBytes
+}
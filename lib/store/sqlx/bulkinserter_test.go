@@ -3,6 +3,7 @@ package sqlx
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
@@ -50,6 +51,23 @@ func TestBulkInserterSuffix(t *testing.T) {
 	})
 }
 
+func TestBulkInserterPostgres(t *testing.T) {
+	runSqlTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		var conn mockedConn
+		inserter, err := NewBulkInserter(&conn, `INSERT INTO classroom_dau(classroom, "user", count) VALUES`+
+			`($1, $2, $3) ON CONFLICT (classroom) DO NOTHING`)
+		assert.Nil(t, err)
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, inserter.Insert("class_'"+strconv.Itoa(i), i%2 == 0, i))
+		}
+		inserter.Flush()
+		assert.Equal(t, `INSERT INTO classroom_dau(classroom, "user", count) VALUES `+
+			`('class_''0', true, 0), ('class_''1', false, 1), ('class_''2', true, 2) `+
+			`ON CONFLICT (classroom) DO NOTHING`, conn.query)
+		assert.Nil(t, conn.args)
+	})
+}
+
 func TestBulkInserterBadStatement(t *testing.T) {
 	runSqlTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
 		var conn mockedConn
@@ -80,6 +98,82 @@ func TestBulkInserter_Update(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestBulkInserterUpsert(t *testing.T) {
+	var conn mockedConn
+	inserter, err := NewBulkInserter(&conn, "INSERT INTO user(name, age, update_time) VALUES "+
+		"(?, ?, now()) ON DUPLICATE KEY UPDATE age = VALUES(age), update_time = VALUES(update_time);")
+	assert.Nil(t, err)
+	assert.Nil(t, inserter.Insert("kevin", 18))
+	inserter.Flush()
+	assert.Equal(t, "INSERT INTO user(name, age, update_time) VALUES ('kevin', 18, now()) "+
+		"ON DUPLICATE KEY UPDATE age = VALUES(age), update_time = VALUES(update_time)", conn.query)
+
+	inserter, err = NewBulkInserter(&conn, `INSERT INTO "user"(name, age) VALUES ($1, $2) `+
+		`ON CONFLICT (name) DO UPDATE SET age = EXCLUDED.age`)
+	assert.Nil(t, err)
+	assert.Nil(t, inserter.Insert("kevin", 18))
+	inserter.Flush()
+	assert.Equal(t, `INSERT INTO "user"(name, age) VALUES ('kevin', 18) `+
+		`ON CONFLICT (name) DO UPDATE SET age = EXCLUDED.age`, conn.query)
+
+	_, err = NewBulkInserter(&conn, "INSERT INTO user(name) VALUES (?) ON CONFLICT (name) DO NOTHING")
+	assert.NotNil(t, err)
+	_, err = NewBulkInserter(&conn, "INSERT INTO user(name) VALUES ($1) ON DUPLICATE KEY UPDATE name = name")
+	assert.NotNil(t, err)
+	_, err = NewBulkInserter(&conn, "INSERT INTO user(name) VALUES (?")
+	assert.NotNil(t, err)
+}
+
+func TestBulkInserterBatchBytes(t *testing.T) {
+	var conn mockedConn
+	inserter, err := NewBulkInserter(&conn, "INSERT INTO user(name) VALUES (?)",
+		WithBulkRows(100), WithBulkBytes(60))
+	assert.Nil(t, err)
+
+	var rows []int
+	inserter.SetResultHandler(func(result sql.Result, err error) {
+		assert.Nil(t, err)
+		affected, err := result.RowsAffected()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, affected)
+		rows = append(rows, result.(BatchResult).Rows)
+	})
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, inserter.Insert("user_"+strconv.Itoa(i)))
+	}
+	assert.Nil(t, inserter.Insert("a_name_longer_than_the_limit_of_the_statement"))
+	assert.Nil(t, inserter.FlushCtx(context.Background()))
+	assert.Equal(t, []string{
+		"INSERT INTO user(name) VALUES ('user_0'), ('user_1')",
+		"INSERT INTO user(name) VALUES ('user_2')",
+		"INSERT INTO user(name) VALUES ('a_name_longer_than_the_limit_of_the_statement')",
+	}, conn.queries)
+	assert.Equal(t, []int{2, 1, 1}, rows)
+}
+
+func TestBulkInserterFlushCtx(t *testing.T) {
+	errExec := errors.New("exec")
+	conn := mockedConn{execErr: errExec}
+	inserter, err := NewBulkInserter(&conn, "INSERT INTO user(name) VALUES (?)")
+	assert.Nil(t, err)
+	assert.Nil(t, inserter.FlushCtx(context.Background()))
+
+	var handled bool
+	inserter.SetResultHandler(func(result sql.Result, err error) {
+		handled = true
+		assert.Equal(t, errExec, err)
+		assert.Nil(t, result)
+	})
+	assert.Nil(t, inserter.Insert("kevin"))
+	assert.Equal(t, errExec, inserter.FlushCtx(context.Background()))
+	assert.True(t, handled)
+
+	_, err = BatchResult{}.RowsAffected()
+	assert.Equal(t, errNoResult, err)
+	_, err = BatchResult{}.LastInsertId()
+	assert.Equal(t, errNoResult, err)
+}
+
 func runSqlTest(t *testing.T, fn func(db *sql.DB, mock sqlmock.Sqlmock)) {
 	logx.Disable()
 
@@ -100,14 +194,20 @@ func runSqlTest(t *testing.T, fn func(db *sql.DB, mock sqlmock.Sqlmock)) {
 
 type mockedConn struct {
 	query   string
+	queries []string
 	args    []any
 	execErr error
 }
 
 func (c *mockedConn) ExecCtx(_ context.Context, query string, args ...any) (sql.Result, error) {
 	c.query = query
+	c.queries = append(c.queries, query)
 	c.args = args
-	return nil, c.execErr
+	if c.execErr != nil {
+		return nil, c.execErr
+	}
+
+	return driver.RowsAffected(1), nil
 }
 
 func (c *mockedConn) PrepareCtx(ctx context.Context, query string) (StmtSession, error) {
@@ -158,6 +258,30 @@ func (c *mockedConn) QueryRowsPartial(v any, query string, args ...any) error {
 	panic("should not called")
 }
 
+func (c *mockedConn) ExecNamed(query string, arg any) (sql.Result, error) {
+	panic("should not called")
+}
+
+func (c *mockedConn) ExecNamedCtx(ctx context.Context, query string, arg any) (sql.Result, error) {
+	panic("should not called")
+}
+
+func (c *mockedConn) QueryRowNamed(v any, query string, arg any) error {
+	panic("should not called")
+}
+
+func (c *mockedConn) QueryRowNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	panic("should not called")
+}
+
+func (c *mockedConn) QueryRowsNamed(v any, query string, arg any) error {
+	panic("should not called")
+}
+
+func (c *mockedConn) QueryRowsNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	panic("should not called")
+}
+
 func (c *mockedConn) RawDB() (*sql.DB, error) {
 	panic("should not called")
 }
@@ -15,14 +15,20 @@ type (
 	Session interface {
 		Exec(query string, args ...any) (sql.Result, error)
 		ExecCtx(ctx context.Context, query string, args ...any) (sql.Result, error)
+		ExecNamed(query string, arg any) (sql.Result, error)
+		ExecNamedCtx(ctx context.Context, query string, arg any) (sql.Result, error)
 		Prepare(query string) (StmtSession, error)
 		PrepareCtx(ctx context.Context, query string) (StmtSession, error)
 		QueryRow(v any, query string, args ...any) error
 		QueryRowCtx(ctx context.Context, v any, query string, args ...any) error
+		QueryRowNamed(v any, query string, arg any) error
+		QueryRowNamedCtx(ctx context.Context, v any, query string, arg any) error
 		QueryRowPartial(v any, query string, args ...any) error
 		QueryRowPartialCtx(ctx context.Context, v any, query string, args ...any) error
 		QueryRows(v any, query string, args ...any) error
 		QueryRowsCtx(ctx context.Context, v any, query string, args ...any) error
+		QueryRowsNamed(v any, query string, arg any) error
+		QueryRowsNamedCtx(ctx context.Context, v any, query string, arg any) error
 		QueryRowsPartial(v any, query string, args ...any) error
 		QueryRowsPartialCtx(ctx context.Context, v any, query string, args ...any) error
 	}
@@ -42,6 +48,27 @@ type (
 		QueryRowsPartialCtx(ctx context.Context, v any, args ...any) error
 	}
 
+	// StreamSession 接口表示支持流式查询的会话，内置的连接及事务会话均已实现。
+	// 独立于 Session 定义，以免外部的 Session 实现须随之新增方法。
+	StreamSession interface {
+		QueryRowsStream(v any, handle func() error, query string, args ...any) error
+		QueryRowsStreamCtx(ctx context.Context, v any, handle func() error, query string, args ...any) error
+	}
+
+	// StmtStreamSession 接口表示支持流式查询的语句会话，内置的预编译语句均已实现。
+	StmtStreamSession interface {
+		QueryRowsStream(v any, handle func() error, args ...any) error
+		QueryRowsStreamCtx(ctx context.Context, v any, handle func() error, args ...any) error
+	}
+
+	// Transactor 接口表示可运行事务的会话，Conn 开启新事务，事务会话则基于 SAVEPOINT 嵌套。
+	// 事务函数 panic 时，两者均先回滚（至 SAVEPOINT）再继续 panic。
+	// 独立于 Session 定义，以免外部的 Session 实现须随之新增方法。
+	Transactor interface {
+		Transact(fn func(Session) error) error
+		TransactCtx(ctx context.Context, fn func(context.Context, Session) error) error
+	}
+
 	// Conn 接口代表原始连接，封装事务方法 Transact。
 	Conn interface {
 		Session
@@ -56,11 +83,15 @@ type (
 	// 线程安全的通用数据库连接。
 	// 因 CORBA 不支持 PREPARE，故合并 query 参数为一个字符串并进行底层无参 query。
 	commonConn struct {
-		brk      breaker.Breaker
-		provider connProvider
-		onError  func(err error)
-		accept   func(error) bool
-		beginTx  beginnable
+		brk       breaker.Breaker
+		provider  connProvider
+		onError   func(err error)
+		accept    func(error) bool
+		beginTx   beginnable
+		postgres  bool
+		retryable func(error) bool
+		txRetry   *TransactRetry
+		hooks     hookChain
 	}
 
 	sessionConn interface {
@@ -82,6 +113,7 @@ type (
 	statement struct {
 		query string
 		stmt  *sql.Stmt
+		hooks hookChain
 	}
 )
 
@@ -95,8 +127,8 @@ func NewConn(driverName, dataSourceName string, opts ...Option) Conn {
 		onError: func(err error) {
 			logInstanceError(dataSourceName, err)
 		},
-		beginTx: begin,
 	}
+	conn.beginTx = conn.begin
 	for _, opt := range opts {
 		opt(conn)
 	}
@@ -115,8 +147,8 @@ func NewConnFromDB(db *sql.DB, opts ...Option) Conn {
 		onError: func(err error) {
 			logx.Errorf("获取 SQL 实例错误：%v", err)
 		},
-		beginTx: begin,
 	}
+	conn.beginTx = conn.begin
 
 	for _, opt := range opts {
 		opt(conn)
@@ -135,24 +167,41 @@ func (db *commonConn) ExecCtx(ctx context.Context, query string, args ...any) (r
 		endSpan(span, err)
 	}()
 
-	err = db.brk.DoWithAcceptable(func() error {
-		var conn *sql.DB
-		conn, err = db.provider()
-		if err != nil {
-			db.onError(err)
-			return err
+	err = db.hooks.run(ctx, newHookInfo(HookExec, query, args), func(ctx context.Context, info *HookInfo) error {
+		e := db.brk.DoWithAcceptable(func() error {
+			conn, e := db.provider()
+			if e != nil {
+				db.onError(e)
+				return e
+			}
+
+			result, e = exec(ctx, conn, info.Query, info.Args...)
+			info.setResult(result)
+			return e
+		}, db.acceptable)
+		if e == breaker.ErrServiceUnavailable {
+			metricReqErr.Inc("Exec", "breaker")
 		}
 
-		result, err = exec(ctx, conn, query, args...)
-		return err
-	}, db.acceptable)
-	if err == breaker.ErrServiceUnavailable {
-		metricReqErr.Inc("Exec", "breaker")
-	}
+		return e
+	})
 
 	return
 }
 
+func (db *commonConn) ExecNamed(query string, arg any) (sql.Result, error) {
+	return db.ExecNamedCtx(context.Background(), query, arg)
+}
+
+func (db *commonConn) ExecNamedCtx(ctx context.Context, query string, arg any) (sql.Result, error) {
+	q, args, err := bindNamed(query, arg, db.postgres)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.ExecCtx(ctx, q, args...)
+}
+
 func (db *commonConn) Prepare(query string) (StmtSession, error) {
 	return db.PrepareCtx(context.Background(), query)
 }
@@ -179,6 +228,7 @@ func (db *commonConn) PrepareCtx(ctx context.Context, query string) (stmt StmtSe
 		stmt = statement{
 			query: query,
 			stmt:  st,
+			hooks: db.hooks,
 		}
 
 		return nil
@@ -205,6 +255,19 @@ func (db *commonConn) QueryRowCtx(ctx context.Context, v any, query string, args
 	}, query, args...)
 }
 
+func (db *commonConn) QueryRowNamed(v any, query string, arg any) error {
+	return db.QueryRowNamedCtx(context.Background(), v, query, arg)
+}
+
+func (db *commonConn) QueryRowNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	q, args, err := bindNamed(query, arg, db.postgres)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowCtx(ctx, v, q, args...)
+}
+
 func (db *commonConn) QueryRowPartial(v any, query string, args ...any) error {
 	return db.QueryRowPartialCtx(context.Background(), v, query, args...)
 }
@@ -235,6 +298,19 @@ func (db *commonConn) QueryRowsCtx(ctx context.Context, v any, query string, arg
 	}, query, args...)
 }
 
+func (db *commonConn) QueryRowsNamed(v any, query string, arg any) error {
+	return db.QueryRowsNamedCtx(context.Background(), v, query, arg)
+}
+
+func (db *commonConn) QueryRowsNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	q, args, err := bindNamed(query, arg, db.postgres)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowsCtx(ctx, v, q, args...)
+}
+
 func (db *commonConn) QueryRowsPartial(v any, query string, args ...any) error {
 	return db.QueryRowsPartialCtx(context.Background(), v, query, args...)
 }
@@ -250,6 +326,22 @@ func (db *commonConn) QueryRowsPartialCtx(ctx context.Context, v any, query stri
 	}, query, args...)
 }
 
+func (db *commonConn) QueryRowsStream(v any, handle func() error, query string, args ...any) error {
+	return db.QueryRowsStreamCtx(context.Background(), v, handle, query, args...)
+}
+
+func (db *commonConn) QueryRowsStreamCtx(ctx context.Context, v any, handle func() error,
+	query string, args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRowsStream")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	return db.queryRows(ctx, func(rows *sql.Rows) error {
+		return unmarshalRowsStream(ctx, v, rows, true, handle)
+	}, query, args...)
+}
+
 func (db *commonConn) RawDB() (*sql.DB, error) {
 	return db.provider()
 }
@@ -266,14 +358,27 @@ func (db *commonConn) TransactCtx(ctx context.Context, fn func(context.Context,
 		endSpan(span, err)
 	}()
 
-	err = db.brk.DoWithAcceptable(func() error {
-		return transact(ctx, db, db.beginTx, fn)
-	}, db.acceptable)
-	if err == breaker.ErrServiceUnavailable {
-		metricReqErr.Inc("Transact", "breaker")
-	}
+	for attempt := 1; ; attempt++ {
+		err = db.hooks.run(ctx, newHookInfo(HookTransact, "", nil), func(ctx context.Context, _ *HookInfo) error {
+			return db.brk.DoWithAcceptable(func() error {
+				return transact(ctx, db, db.beginTx, fn)
+			}, db.acceptable)
+		})
+		if err == breaker.ErrServiceUnavailable {
+			metricReqErr.Inc("Transact", "breaker")
+			return
+		}
 
-	return
+		if db.txRetry == nil || !db.txRetry.shouldRetry(attempt, err, db.retryable) {
+			return
+		}
+
+		metricReqRetry.Inc("Transact")
+		addRetryEvent(span, attempt, err)
+		if e := db.txRetry.wait(ctx, attempt); e != nil {
+			return
+		}
+	}
 }
 
 func (db *commonConn) acceptable(err error) bool {
@@ -285,27 +390,38 @@ func (db *commonConn) acceptable(err error) bool {
 	return ok || db.accept(err)
 }
 
+func (db *commonConn) begin(conn *sql.DB) (trans, error) {
+	tx, err := conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	return newTxSession(tx, db.postgres, db.hooks), nil
+}
+
 func (db *commonConn) queryRows(ctx context.Context, scanner func(*sql.Rows) error, q string, args ...any) (err error) {
-	var scanErr error
-	err = db.brk.DoWithAcceptable(func() error {
-		conn, e := db.provider()
-		if e != nil {
-			db.onError(e)
-			return e
+	return db.hooks.run(ctx, newHookInfo(HookQuery, q, args), func(ctx context.Context, info *HookInfo) error {
+		var scanErr error
+		e := db.brk.DoWithAcceptable(func() error {
+			conn, e := db.provider()
+			if e != nil {
+				db.onError(e)
+				return e
+			}
+
+			return query(ctx, conn, func(rows *sql.Rows) error {
+				scanErr = scanner(rows)
+				return scanErr
+			}, info.Query, info.Args...)
+		}, func(err error) bool {
+			return scanErr == err || db.acceptable(err)
+		})
+		if e == breaker.ErrServiceUnavailable {
+			metricReqErr.Inc("queryRows", "breaker")
 		}
 
-		return query(ctx, conn, func(rows *sql.Rows) error {
-			scanErr = scanner(rows)
-			return scanErr
-		}, q, args...)
-	}, func(err error) bool {
-		return scanErr == err || db.acceptable(err)
+		return e
 	})
-	if err == breaker.ErrServiceUnavailable {
-		metricReqErr.Inc("queryRows", "breaker")
-	}
-
-	return
 }
 
 func (s statement) Close() error {
@@ -322,7 +438,14 @@ func (s statement) ExecCtx(ctx context.Context, args ...any) (result sql.Result,
 		endSpan(span, err)
 	}()
 
-	return execStmt(ctx, s.stmt, s.query, args...)
+	err = s.hooks.run(ctx, newHookInfo(HookExecStmt, s.query, args), func(ctx context.Context, info *HookInfo) error {
+		var e error
+		result, e = execStmt(ctx, s.stmt, info.Query, info.Args...)
+		info.setResult(result)
+		return e
+	})
+
+	return
 }
 
 func (s statement) QueryRow(v any, args ...any) error {
@@ -335,9 +458,9 @@ func (s statement) QueryRowCtx(ctx context.Context, v any, args ...any) (err err
 		endSpan(span, err)
 	}()
 
-	return queryStmt(ctx, s.stmt, func(rows *sql.Rows) error {
+	return s.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRow(v, rows, true)
-	}, s.query, args...)
+	}, args...)
 }
 
 func (s statement) QueryRowPartial(v any, args ...any) error {
@@ -350,9 +473,9 @@ func (s statement) QueryRowPartialCtx(ctx context.Context, v any, args ...any) (
 		endSpan(span, err)
 	}()
 
-	return queryStmt(ctx, s.stmt, func(rows *sql.Rows) error {
+	return s.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRow(v, rows, false)
-	}, s.query, args...)
+	}, args...)
 }
 
 func (s statement) QueryRows(v any, args ...any) error {
@@ -365,9 +488,9 @@ func (s statement) QueryRowsCtx(ctx context.Context, v any, args ...any) (err er
 		endSpan(span, err)
 	}()
 
-	return queryStmt(ctx, s.stmt, func(rows *sql.Rows) error {
+	return s.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRows(v, rows, true)
-	}, s.query, args...)
+	}, args...)
 }
 
 func (s statement) QueryRowsPartial(v any, args ...any) error {
@@ -380,7 +503,28 @@ func (s statement) QueryRowsPartialCtx(ctx context.Context, v any, args ...any)
 		endSpan(span, err)
 	}()
 
-	return queryStmt(ctx, s.stmt, func(rows *sql.Rows) error {
+	return s.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRows(v, rows, false)
-	}, s.query, args...)
+	}, args...)
+}
+
+func (s statement) QueryRowsStream(v any, handle func() error, args ...any) error {
+	return s.QueryRowsStreamCtx(context.Background(), v, handle, args...)
+}
+
+func (s statement) QueryRowsStreamCtx(ctx context.Context, v any, handle func() error, args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRowsStream")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	return s.queryRows(ctx, func(rows *sql.Rows) error {
+		return unmarshalRowsStream(ctx, v, rows, true, handle)
+	}, args...)
+}
+
+func (s statement) queryRows(ctx context.Context, scanner func(*sql.Rows) error, args ...any) error {
+	return s.hooks.run(ctx, newHookInfo(HookQueryStmt, s.query, args), func(ctx context.Context, info *HookInfo) error {
+		return queryStmt(ctx, s.stmt, scanner, info.Query, info.Args...)
+	})
 }
@@ -0,0 +1,106 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gotid/god/lib/timex"
+	"time"
+)
+
+const (
+	// HookExec 代表直接执行的语句。
+	HookExec = "exec"
+	// HookExecStmt 代表预编译语句的执行。
+	HookExecStmt = "execStmt"
+	// HookQuery 代表直接执行的查询。
+	HookQuery = "query"
+	// HookQueryStmt 代表预编译语句的查询。
+	HookQueryStmt = "queryStmt"
+	// HookTransact 代表一个完整的事务，Before 在开启事务前调用，After 在提交或回滚后调用。
+	HookTransact = "transact"
+)
+
+type (
+	// Hook 是 SQL 执行的钩子，可用于审计、SQL 防火墙、租户过滤及自定义指标等。
+	Hook interface {
+		// Before 在执行前调用，可修改 info 中的 Query 与 Args，返回错误将拒绝执行。
+		Before(ctx context.Context, info *HookInfo) (context.Context, error)
+		// After 在执行后调用，info 中已填入耗时、影响行数及错误。
+		After(ctx context.Context, info *HookInfo)
+	}
+
+	// HookInfo 是钩子可见的执行信息。
+	HookInfo struct {
+		// Command 为 HookExec、HookQuery、HookTransact 等。
+		Command string
+		Query   string
+		Args    []any
+		// Duration 为执行耗时，查询类包含解编组结果的耗时。
+		Duration time.Duration
+		// RowsAffected 为影响的行数，仅执行类语句有效，未知时为 -1。
+		RowsAffected int64
+		Err          error
+	}
+
+	hookChain []Hook
+)
+
+// WithHooks 为连接添加钩子，Before 按添加顺序调用，After 按相反顺序调用。
+func WithHooks(hooks ...Hook) Option {
+	return func(conn *commonConn) {
+		conn.hooks = append(conn.hooks, hooks...)
+	}
+}
+
+func newHookInfo(command, query string, args []any) *HookInfo {
+	return &HookInfo{
+		Command:      command,
+		Query:        query,
+		Args:         args,
+		RowsAffected: -1,
+	}
+}
+
+func (info *HookInfo) setResult(result sql.Result) {
+	if result == nil {
+		return
+	}
+
+	if rows, err := result.RowsAffected(); err == nil {
+		info.RowsAffected = rows
+	}
+}
+
+// run 依次调用钩子的 Before，执行 fn 后再逆序调用已执行过 Before 的钩子的 After，
+// 每个钩子的 After 收到其 Before 返回的 ctx，耗时不含钩子自身的耗时。
+func (hc hookChain) run(ctx context.Context, info *HookInfo,
+	fn func(ctx context.Context, info *HookInfo) error) error {
+	if len(hc) == 0 {
+		return fn(ctx, info)
+	}
+
+	ctxs := make([]context.Context, 0, len(hc))
+	defer func() {
+		for i := len(ctxs) - 1; i >= 0; i-- {
+			hc[i].After(ctxs[i], info)
+		}
+	}()
+
+	for _, hook := range hc {
+		c, err := hook.Before(ctx, info)
+		if err != nil {
+			info.Err = err
+			return err
+		}
+
+		ctx = c
+		ctxs = append(ctxs, c)
+	}
+
+	start := timex.Now()
+	defer func() {
+		info.Duration = timex.Since(start)
+	}()
+	info.Err = fn(ctx, info)
+	return info.Err
+}
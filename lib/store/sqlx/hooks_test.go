@@ -0,0 +1,183 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errNoWhere = errors.New("update without where")
+
+type recordHook struct {
+	name  string
+	calls *[]string
+	infos *[]HookInfo
+}
+
+func (h recordHook) Before(ctx context.Context, info *HookInfo) (context.Context, error) {
+	*h.calls = append(*h.calls, h.name+".before."+info.Command)
+	return ctx, nil
+}
+
+func (h recordHook) After(_ context.Context, info *HookInfo) {
+	*h.calls = append(*h.calls, h.name+".after."+info.Command)
+	if h.infos != nil {
+		*h.infos = append(*h.infos, *info)
+	}
+}
+
+type firewallHook struct{}
+
+func (h firewallHook) Before(ctx context.Context, info *HookInfo) (context.Context, error) {
+	lower := strings.ToLower(info.Query)
+	if strings.HasPrefix(lower, "update") && !strings.Contains(lower, "where") {
+		return ctx, errNoWhere
+	}
+
+	return ctx, nil
+}
+
+func (h firewallHook) After(_ context.Context, _ *HookInfo) {
+}
+
+type tenantHook struct{}
+
+func (h tenantHook) Before(ctx context.Context, info *HookInfo) (context.Context, error) {
+	if info.Command == HookQuery {
+		info.Query += " and tenant_id = ?"
+		info.Args = append(info.Args, 7)
+	}
+
+	return ctx, nil
+}
+
+func (h tenantHook) After(_ context.Context, _ *HookInfo) {
+}
+
+type ctxHookKey struct{}
+
+// ctxHook 在 ctx 中写入自身名称，After 记录收到的名称。
+type ctxHook struct {
+	name  string
+	names *[]string
+}
+
+func (h ctxHook) Before(ctx context.Context, _ *HookInfo) (context.Context, error) {
+	time.Sleep(20 * time.Millisecond)
+	return context.WithValue(ctx, ctxHookKey{}, h.name), nil
+}
+
+func (h ctxHook) After(ctx context.Context, _ *HookInfo) {
+	*h.names = append(*h.names, ctx.Value(ctxHookKey{}).(string))
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestHookChainRun(t *testing.T) {
+	var names []string
+	hc := hookChain{ctxHook{name: "a", names: &names}, ctxHook{name: "b", names: &names}}
+	info := newHookInfo(HookExec, "any", nil)
+	assert.Nil(t, hc.run(context.Background(), info, func(ctx context.Context, _ *HookInfo) error {
+		assert.Equal(t, "b", ctx.Value(ctxHookKey{}))
+		return nil
+	}))
+	// 每个 After 收到其 Before 返回的 ctx，耗时不含钩子的耗时。
+	assert.Equal(t, []string{"b", "a"}, names)
+	assert.True(t, info.Duration < 20*time.Millisecond)
+}
+
+func TestHooks(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectExec("update users set name = \\? where id = \\?").WithArgs("kevin", 1).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("select name from users where id = \\?").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).FromCSVString("kevin"))
+
+		var calls []string
+		var infos []HookInfo
+		conn := NewConnFromDB(db, WithHooks(
+			recordHook{name: "a", calls: &calls, infos: &infos},
+			recordHook{name: "b", calls: &calls},
+		))
+		_, err := conn.Exec("update users set name = ? where id = ?", "kevin", 1)
+		assert.Nil(t, err)
+		var name string
+		assert.Nil(t, conn.QueryRow(&name, "select name from users where id = ?", 1))
+		assert.Equal(t, "kevin", name)
+
+		assert.Equal(t, []string{
+			"a.before.exec", "b.before.exec", "b.after.exec", "a.after.exec",
+			"a.before.query", "b.before.query", "b.after.query", "a.after.query",
+		}, calls)
+		assert.EqualValues(t, 2, infos[0].RowsAffected)
+		assert.Equal(t, []any{"kevin", 1}, infos[0].Args)
+		assert.EqualValues(t, -1, infos[1].RowsAffected)
+		assert.Nil(t, infos[1].Err)
+	})
+}
+
+func TestHooksReject(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		var calls []string
+		var infos []HookInfo
+		conn := NewConnFromDB(db, WithHooks(recordHook{name: "a", calls: &calls, infos: &infos}, firewallHook{}))
+		_, err := conn.Exec("update users set name = ?", "kevin")
+		assert.Equal(t, errNoWhere, err)
+		assert.Equal(t, []string{"a.before.exec", "a.after.exec"}, calls)
+		assert.Equal(t, errNoWhere, infos[0].Err)
+	})
+}
+
+func TestHooksRewrite(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("select name from users where id = \\? and tenant_id = \\?").WithArgs(1, 7).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).FromCSVString("kevin"))
+
+		conn := NewConnFromDB(db, WithHooks(tenantHook{}))
+		var names []string
+		assert.Nil(t, conn.QueryRows(&names, "select name from users where id = ?", 1))
+		assert.Equal(t, []string{"kevin"}, names)
+	})
+}
+
+func TestHooksTransact(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into users").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectPrepare("select name from users").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).FromCSVString("kevin"))
+		mock.ExpectRollback()
+
+		var calls []string
+		errRollback := errors.New("rollback")
+		conn := NewConnFromDB(db, WithHooks(recordHook{name: "a", calls: &calls}))
+		assert.Equal(t, errRollback, conn.TransactCtx(context.Background(),
+			func(ctx context.Context, session Session) error {
+				if _, err := session.ExecCtx(ctx, "insert into users"); err != nil {
+					return err
+				}
+
+				stmt, err := session.PrepareCtx(ctx, "select name from users")
+				if err != nil {
+					return err
+				}
+
+				var name string
+				if err := stmt.QueryRowCtx(ctx, &name); err != nil {
+					return err
+				}
+
+				return errRollback
+			}))
+		assert.Equal(t, []string{
+			"a.before.transact",
+			"a.before.exec", "a.after.exec",
+			"a.before.queryStmt", "a.after.queryStmt",
+			"a.after.transact",
+		}, calls)
+	})
+}
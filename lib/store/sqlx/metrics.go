@@ -20,4 +20,18 @@ var (
 		Help:      "MySQL客户端请求错误次数。",
 		Labels:    []string{"command", "error"},
 	})
+	metricRoleReqs = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "requests",
+		Name:      "role_total",
+		Help:      "读写分离时按主从角色统计的请求次数。",
+		Labels:    []string{"command", "role"},
+	})
+	metricReqRetry = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "requests",
+		Name:      "retry_total",
+		Help:      "SQL客户端请求重试次数。",
+		Labels:    []string{"command"},
+	})
 )
@@ -10,7 +10,7 @@ const (
 // NewMySQL 返回一个 MySQL 连接。
 // dataSourceName 为 mysql/sqlite/sqlmock/clickhouse 等。
 func NewMySQL(dataSourceName string, opts ...Option) Conn {
-	opts = append(opts, withMySQLAcceptable())
+	opts = append(opts, withMySQLAcceptable(), withMySQLRetryable())
 	return NewConn(mysqlDriverName, dataSourceName, opts...)
 }
 
@@ -0,0 +1,227 @@
+package sqlx
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// db 标签中标记敏感字段的选项，绑定时以 Sensitive 包装。
+const sensitiveOption = "sensitive"
+
+var (
+	// ErrUnsupportedNamedArg 代表命名参数既不是结构体也不是 map[string]any。
+	ErrUnsupportedNamedArg = errors.New("命名参数只支持结构体或 map[string]any")
+	// ErrEmptySliceArg 代表用于 IN 展开的切片参数为空。
+	ErrEmptySliceArg = errors.New("命名参数中用于展开的切片不能为空")
+)
+
+// bindNamed 将查询语句中的 :name 命名占位符替换为位置占位符，并按顺序返回对应参数。
+// arg 为结构体（按 db 标签绑定）或 map[string]any，切片值将展开为多个占位符，用于 IN (:ids)。
+// postgres 为真时生成 $n 占位符，否则生成 ? 占位符。
+func bindNamed(query string, arg any, postgres bool) (string, []any, error) {
+	values, err := namedValues(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	var args []any
+	writePlaceholder := func() {
+		if postgres {
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(len(args)))
+		} else {
+			b.WriteByte('?')
+		}
+	}
+
+	n := len(query)
+	for i := 0; i < n; i++ {
+		ch := query[i]
+		switch ch {
+		case '\'', '"', '`':
+			j := i + 1
+			for ; j < n; j++ {
+				if query[j] == '\\' {
+					j++
+				} else if query[j] == ch {
+					break
+				}
+			}
+			if j >= n {
+				return "", nil, errUnbalancedEscape
+			}
+			b.WriteString(query[i : j+1])
+			i = j
+		case '-', '/':
+			// 跳过注释，其中的 : 不是占位符
+			end := commentEnd(query, i)
+			if end < 0 {
+				b.WriteByte(ch)
+				continue
+			}
+
+			b.WriteString(query[i:end])
+			i = end - 1
+		case ':':
+			// pg 的类型转换，如 ::int
+			if i+1 < n && query[i+1] == ':' {
+				b.WriteString("::")
+				i++
+				continue
+			}
+
+			j := i + 1
+			for j < n && isNameChar(query[j], j == i+1) {
+				j++
+			}
+			if j == i+1 {
+				b.WriteByte(ch)
+				continue
+			}
+
+			name := query[i+1 : j]
+			val, ok := values[name]
+			if !ok {
+				return "", nil, fmt.Errorf("命名参数 %q 未提供值", name)
+			}
+
+			elems, expand := expandSlice(val)
+			if !expand {
+				args = append(args, val)
+				writePlaceholder()
+			} else if len(elems) == 0 {
+				return "", nil, ErrEmptySliceArg
+			} else {
+				for k, elem := range elems {
+					if k > 0 {
+						b.WriteString(", ")
+					}
+					args = append(args, elem)
+					writePlaceholder()
+				}
+			}
+			i = j - 1
+		default:
+			b.WriteByte(ch)
+		}
+	}
+
+	return b.String(), args, nil
+}
+
+// commentEnd 返回从 i 开始的 -- 行注释或 /* */ 块注释的结束位置，i 处不是注释时返回 -1。
+func commentEnd(query string, i int) int {
+	switch {
+	case strings.HasPrefix(query[i:], "--"):
+		if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	case strings.HasPrefix(query[i:], "/*"):
+		if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(query)
+	default:
+		return -1
+	}
+}
+
+func isNameChar(ch byte, first bool) bool {
+	switch {
+	case 'a' <= ch && ch <= 'z', 'A' <= ch && ch <= 'Z', ch == '_':
+		return true
+	case '0' <= ch && ch <= '9', ch == '.':
+		return !first
+	default:
+		return false
+	}
+}
+
+// 将切片及数组展开为元素列表，[]byte 及实现了 driver.Valuer 的值不展开。
+func expandSlice(val any) ([]any, bool) {
+	if val == nil {
+		return nil, false
+	}
+	if _, ok := val.(driver.Valuer); ok {
+		return nil, false
+	}
+	if _, ok := val.([]byte); ok {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	elems := make([]any, rv.Len())
+	for i := range elems {
+		elems[i] = rv.Index(i).Interface()
+	}
+
+	return elems, true
+}
+
+func namedValues(arg any) (map[string]any, error) {
+	if m, ok := arg.(map[string]any); ok {
+		return m, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(arg))
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrUnsupportedNamedArg
+	}
+
+	values := make(map[string]any)
+	collectNamedValues(rv, values)
+
+	return values, nil
+}
+
+func collectNamedValues(rv reflect.Value, values map[string]any) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+		if field.Anonymous && len(parseTagName(field)) == 0 {
+			inner := reflect.Indirect(fv)
+			if inner.Kind() == reflect.Struct {
+				collectNamedValues(inner, values)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		key := parseTagName(field)
+		switch key {
+		case "-":
+			continue
+		case "":
+			key = field.Name
+		}
+		if hasTagOption(field, sensitiveOption) {
+			values[key] = Sensitive(fv.Interface())
+		} else {
+			values[key] = fv.Interface()
+		}
+	}
+}
+
+func hasTagOption(field reflect.StructField, option string) bool {
+	options := strings.Split(field.Tag.Get(tagName), ",")
+	for _, opt := range options[1:] {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+
+	return false
+}
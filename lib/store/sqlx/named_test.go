@@ -0,0 +1,199 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type namedUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name,type=varchar"`
+	Age  int
+	Skip string `db:"-"`
+}
+
+type sensitiveUser struct {
+	ID       int64  `db:"id"`
+	Password string `db:"password,sensitive"`
+}
+
+func TestBindNamed(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		arg      any
+		postgres bool
+		expect   string
+		args     []any
+		hasErr   bool
+	}{
+		{
+			name:   "struct",
+			query:  "insert into users (id, name, age) values (:id, :name, :Age)",
+			arg:    namedUser{ID: 1, Name: "kevin", Age: 18},
+			expect: "insert into users (id, name, age) values (?, ?, ?)",
+			args:   []any{int64(1), "kevin", 18},
+		},
+		{
+			name:     "struct postgres",
+			query:    "update users set name=:name where id=:id",
+			arg:      &namedUser{ID: 1, Name: "kevin"},
+			postgres: true,
+			expect:   "update users set name=$1 where id=$2",
+			args:     []any{"kevin", int64(1)},
+		},
+		{
+			name:   "map with slice",
+			query:  "select * from users where id in (:ids) and name=:name",
+			arg:    map[string]any{"ids": []int{1, 2, 3}, "name": "kevin"},
+			expect: "select * from users where id in (?, ?, ?) and name=?",
+			args:   []any{1, 2, 3, "kevin"},
+		},
+		{
+			name:     "map with slice postgres",
+			query:    "select * from users where id in (:ids) and name=:name",
+			arg:      map[string]any{"ids": []int{1, 2}, "name": "kevin"},
+			postgres: true,
+			expect:   "select * from users where id in ($1, $2) and name=$3",
+			args:     []any{1, 2, "kevin"},
+		},
+		{
+			name:   "bytes not expanded",
+			query:  "select * from users where token=:token",
+			arg:    map[string]any{"token": []byte("abc")},
+			expect: "select * from users where token=?",
+			args:   []any{[]byte("abc")},
+		},
+		{
+			name:   "quoted and cast",
+			query:  "select ':name', id::text, '\\':x' from users where id=:id",
+			arg:    map[string]any{"id": 1},
+			expect: "select ':name', id::text, '\\':x' from users where id=?",
+			args:   []any{1},
+		},
+		{
+			name: "comments",
+			query: "select * from users -- a:b\nwhere id=:id /* :x */ and name='a' " +
+				"/* unclosed :y",
+			arg:    map[string]any{"id": 1},
+			expect: "select * from users -- a:b\nwhere id=? /* :x */ and name='a' /* unclosed :y",
+			args:   []any{1},
+		},
+		{
+			name:   "minus and division",
+			query:  "select age-:delta, score/:ratio from users",
+			arg:    map[string]any{"delta": 1, "ratio": 2},
+			expect: "select age-?, score/? from users",
+			args:   []any{1, 2},
+		},
+		{
+			name:   "sensitive",
+			query:  "update users set password=:password where id=:id",
+			arg:    sensitiveUser{ID: 1, Password: "secret"},
+			expect: "update users set password=? where id=?",
+			args:   []any{Sensitive("secret"), int64(1)},
+		},
+		{
+			name:   "missing",
+			query:  "select * from users where id=:id",
+			arg:    map[string]any{},
+			hasErr: true,
+		},
+		{
+			name:   "empty slice",
+			query:  "select * from users where id in (:ids)",
+			arg:    map[string]any{"ids": []int{}},
+			hasErr: true,
+		},
+		{
+			name:   "ignored field",
+			query:  "select * from users where skip=:Skip",
+			arg:    namedUser{},
+			hasErr: true,
+		},
+		{
+			name:   "unsupported",
+			query:  "select * from users where id=:id",
+			arg:    1,
+			hasErr: true,
+		},
+		{
+			name:   "unbalanced quote",
+			query:  "select * from users where id=:id and name='",
+			arg:    map[string]any{"id": 1},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			query, args, err := bindNamed(test.query, test.arg, test.postgres)
+			if test.hasErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expect, query)
+			assert.Equal(t, test.args, args)
+		})
+	}
+}
+
+func TestNamedConn(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectExec("update users set name = \\? where id = \\?").WithArgs("kevin", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("select name from users where id in \\(\\?, \\?\\)").WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).FromCSVString("kevin\nlisa"))
+		mock.ExpectQuery("select name from users where id = \\?").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).FromCSVString("kevin"))
+		mock.ExpectExec("update users set password = \\? where id = \\?").WithArgs("secret", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		conn := NewConnFromDB(db)
+		result, err := conn.ExecNamed("update users set name = :name where id = :id",
+			map[string]any{"name": "kevin", "id": 1})
+		assert.Nil(t, err)
+		affected, err := result.RowsAffected()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, affected)
+
+		var names []string
+		assert.Nil(t, conn.QueryRowsNamed(&names, "select name from users where id in (:ids)",
+			map[string]any{"ids": []int{1, 2}}))
+		assert.Equal(t, []string{"kevin", "lisa"}, names)
+
+		var name string
+		assert.Nil(t, conn.QueryRowNamed(&name, "select name from users where id = :id",
+			map[string]any{"id": 1}))
+		assert.Equal(t, "kevin", name)
+
+		// 敏感参数执行时使用原值。
+		_, err = conn.ExecNamed("update users set password = :password where id = :id",
+			sensitiveUser{ID: 1, Password: "secret"})
+		assert.Nil(t, err)
+	})
+}
+
+func TestNamedTxPostgres(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("update users set name = \\$1 where id = \\$2").WithArgs("kevin", int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		conn := NewPostgresFromDB(db)
+		assert.Nil(t, conn.TransactCtx(context.Background(), func(ctx context.Context, session Session) error {
+			_, err := session.ExecNamedCtx(ctx, "update users set name = :name where id = :id",
+				namedUser{ID: 1, Name: "kevin"})
+			return err
+		}))
+	})
+}
@@ -1,6 +1,7 @@
 package sqlx
 
 import (
+	"context"
 	"errors"
 	"github.com/gotid/god/lib/mapping"
 	"reflect"
@@ -33,6 +34,39 @@ func unmarshalRow(v any, scanner rowsScanner, strict bool) error {
 		return ErrNotFound
 	}
 
+	return scanRow(v, scanner, strict)
+}
+
+// 逐行解编组查询结果至 v，每行解编组后调用 handle，用于流式处理大结果集。
+func unmarshalRowsStream(ctx context.Context, v any, scanner rowsScanner, strict bool, handle func() error) error {
+	rv := reflect.ValueOf(v)
+	if err := mapping.ValidatePtr(&rv); err != nil {
+		return err
+	}
+
+	rve := rv.Elem()
+	zero := reflect.Zero(rve.Type())
+	for scanner.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// 重置目标值，以免上一行的指针字段被复用
+		rve.Set(zero)
+		if err := scanRow(v, scanner, strict); err != nil {
+			return err
+		}
+
+		if err := handle(); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// 解编组当前行至 v。
+func scanRow(v any, scanner rowsScanner, strict bool) error {
 	rv := reflect.ValueOf(v)
 	if err := mapping.ValidatePtr(&rv); err != nil {
 		return err
@@ -0,0 +1,484 @@
+package sqlx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const cursorSeparator = "."
+
+var (
+	// ErrInvalidCursor 表示分页游标格式错误、已被篡改或不属于当前排序。
+	ErrInvalidCursor = errors.New("分页游标无效")
+	// ErrNoPageSecret 表示未设置分页游标的签名密钥。
+	ErrNoPageSecret = errors.New("未设置分页游标的签名密钥")
+	// ErrNoSortFields 表示分页未指定排序列。
+	ErrNoSortFields = errors.New("分页须至少指定一个排序列")
+	// ErrInvalidPageLimit 表示每页行数不是正数。
+	ErrInvalidPageLimit = errors.New("每页行数须大于 0")
+
+	// WHERE 之后、ORDER BY 之前的子句，游标条件须插入其前。
+	pageTailClauses = []string{"group", "having", "window"}
+
+	pageSecret atomic.Value
+)
+
+type (
+	// SortField 是键集分页的排序列。
+	SortField struct {
+		Column string
+		Desc   bool
+	}
+
+	// PaginatorOption 自定义 Paginator。
+	PaginatorOption func(p *Paginator)
+
+	// Paginator 是键集（游标）分页器，以上一页最后一行的排序列值作为下一页的起点，
+	// 避免 OFFSET 在大表上的深分页扫描。排序列须构成唯一键（通常以主键结尾），且不可为 NULL。
+	Paginator struct {
+		sorts    []SortField
+		secret   []byte
+		postgres bool
+	}
+
+	cursorValue struct {
+		Type  string `json:"t"`
+		Value string `json:"v,omitempty"`
+	}
+)
+
+// Asc 返回升序排序列。
+func Asc(column string) SortField {
+	return SortField{Column: column}
+}
+
+// Desc 返回降序排序列。
+func Desc(column string) SortField {
+	return SortField{Column: column, Desc: true}
+}
+
+// SetPageSecret 设置默认的分页游标签名密钥，未通过 WithPageSecret 指定密钥的分页器将使用它。
+func SetPageSecret(secret []byte) {
+	pageSecret.Store(secret)
+}
+
+// WithPageSecret 设置分页游标的签名密钥。
+func WithPageSecret(secret []byte) PaginatorOption {
+	return func(p *Paginator) {
+		p.secret = secret
+	}
+}
+
+// WithPagePostgres 使分页条件使用 PostgreSQL 的 $n 占位符。
+func WithPagePostgres() PaginatorOption {
+	return func(p *Paginator) {
+		p.postgres = true
+	}
+}
+
+// NewPaginator 返回一个按给定排序列分页的 Paginator。
+func NewPaginator(sorts []SortField, opts ...PaginatorOption) *Paginator {
+	p := &Paginator{
+		sorts: sorts,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// QueryPage 查询一页数据至 v，返回下一页游标，无更多数据时游标为空。
+func (p *Paginator) QueryPage(session Session, v any, query, cursor string, limit int,
+	args ...any) (string, error) {
+	return p.QueryPageCtx(context.Background(), session, v, query, cursor, limit, args...)
+}
+
+// QueryPageCtx 查询一页数据至 v，返回下一页游标，无更多数据时游标为空。
+// query 为不含 ORDER BY 及 LIMIT 的查询语句，可含 GROUP BY 及 HAVING，v 须为切片指针。
+func (p *Paginator) QueryPageCtx(ctx context.Context, session Session, v any, query, cursor string,
+	limit int, args ...any) (string, error) {
+	query, args, err := p.Build(query, cursor, limit, args...)
+	if err != nil {
+		return "", err
+	}
+
+	if err = session.QueryRowsCtx(ctx, v, query, args...); err != nil {
+		return "", err
+	}
+
+	return p.Next(v, limit)
+}
+
+// Build 为 query 追加游标条件、排序及 LIMIT limit+1，多取的一行用于判断是否有下一页。
+// 结果可交由任意连接（如 sqlc.CachedConn 的 QueryRowsNoCacheCtx）查询，随后以 Next 获取下一页游标。
+// 未设置签名密钥时直接返回 ErrNoPageSecret，以免查询后才因无法生成游标而失败。
+func (p *Paginator) Build(query, cursor string, limit int, args ...any) (string, []any, error) {
+	if len(p.sorts) == 0 {
+		return "", nil, ErrNoSortFields
+	}
+	if limit <= 0 {
+		return "", nil, ErrInvalidPageLimit
+	}
+	if _, err := p.getSecret(); err != nil {
+		return "", nil, err
+	}
+
+	var values []any
+	if len(cursor) > 0 {
+		var err error
+		if values, err = p.Decode(cursor); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var b strings.Builder
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	if len(values) > 0 {
+		where := topLevelKeyword(query, "where", true)
+		end := len(query)
+		for _, clause := range pageTailClauses {
+			if index := topLevelKeyword(query, clause, false); index > where && index < end {
+				end = index
+			}
+		}
+
+		if where >= 0 {
+			// 原条件须加括号，避免其中的 OR 与游标条件混淆
+			where += len("where")
+			b.WriteString(query[:where])
+			b.WriteString(" (")
+			b.WriteString(strings.TrimSpace(query[where:end]))
+			b.WriteString(") and ")
+		} else {
+			b.WriteString(strings.TrimSpace(query[:end]))
+			b.WriteString(" where ")
+		}
+		args = p.writeCondition(&b, values, args, countPlaceholders(query[:end]))
+		if end < len(query) {
+			b.WriteString(" ")
+			b.WriteString(query[end:])
+		}
+	} else {
+		b.WriteString(query)
+	}
+
+	b.WriteString(" order by ")
+	for i, sort := range p.sorts {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(sort.Column)
+		if sort.Desc {
+			b.WriteString(" desc")
+		} else {
+			b.WriteString(" asc")
+		}
+	}
+	b.WriteString(" limit ")
+	b.WriteString(strconv.Itoa(limit + 1))
+
+	return b.String(), args, nil
+}
+
+// Next 将 v 截断至 limit 行，若有多取的行则以最后一行生成下一页游标。
+func (p *Paginator) Next(v any, limit int) (string, error) {
+	if limit <= 0 {
+		return "", ErrInvalidPageLimit
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return "", ErrUnsupportedValueType
+	}
+
+	rv = rv.Elem()
+	if rv.Len() <= limit {
+		return "", nil
+	}
+
+	rv.SetLen(limit)
+	return p.Encode(rv.Index(limit - 1).Interface())
+}
+
+// Encode 以一行数据的排序列值生成游标。
+func (p *Paginator) Encode(row any) (string, error) {
+	secret, err := p.getSecret()
+	if err != nil {
+		return "", err
+	}
+
+	fields, err := namedValues(row)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]cursorValue, len(p.sorts))
+	for i, sort := range p.sorts {
+		val, ok := fields[fieldName(sort.Column)]
+		if !ok {
+			return "", fmt.Errorf("排序列 %s 在 %T 中不存在", sort.Column, row)
+		}
+
+		if values[i], err = encodeCursorValue(val); err != nil {
+			return "", err
+		}
+	}
+
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + cursorSeparator + p.sign(secret, encoded), nil
+}
+
+// Decode 校验游标签名并解出排序列值，游标被篡改或不属于当前排序时返回 ErrInvalidCursor。
+func (p *Paginator) Decode(cursor string) ([]any, error) {
+	secret, err := p.getSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, signature, ok := strings.Cut(cursor, cursorSeparator)
+	if !ok || !hmac.Equal([]byte(signature), []byte(p.sign(secret, encoded))) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var values []cursorValue
+	if err = json.Unmarshal(payload, &values); err != nil || len(values) != len(p.sorts) {
+		return nil, ErrInvalidCursor
+	}
+
+	result := make([]any, len(values))
+	for i, val := range values {
+		if result[i], err = decodeCursorValue(val); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return result, nil
+}
+
+func (p *Paginator) getSecret() ([]byte, error) {
+	if len(p.secret) > 0 {
+		return p.secret, nil
+	}
+
+	if secret, ok := pageSecret.Load().([]byte); ok && len(secret) > 0 {
+		return secret, nil
+	}
+
+	return nil, ErrNoPageSecret
+}
+
+// sign 对游标及排序签名，使游标不能用于其他排序。
+func (p *Paginator) sign(secret []byte, encoded string) string {
+	mac := hmac.New(sha256.New, secret)
+	for _, sort := range p.sorts {
+		mac.Write([]byte(sort.Column))
+		if sort.Desc {
+			mac.Write([]byte(" desc,"))
+		} else {
+			mac.Write([]byte(" asc,"))
+		}
+	}
+	mac.Write([]byte(encoded))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// writeCondition 写入键集条件，如排序 (a asc, b desc) 对应 (a > ? or (a = ? and b < ?))。
+// 条件写在 GROUP BY 等子句之前，? 占位符的参数须插入 args 的第 at 个位置，$n 占位符按编号引用则追加至末尾。
+func (p *Paginator) writeCondition(b *strings.Builder, values, args []any, at int) []any {
+	if p.postgres || at > len(args) {
+		at = len(args)
+	}
+	head := append([]any(nil), args[:at]...)
+	tail := args[at:]
+
+	writeArg := func(val any) {
+		head = append(head, val)
+		if p.postgres {
+			b.WriteString("$")
+			b.WriteString(strconv.Itoa(len(args) + len(head) - at))
+		} else {
+			b.WriteString("?")
+		}
+	}
+
+	b.WriteString("(")
+	for i, sort := range p.sorts {
+		if i > 0 {
+			b.WriteString(" or ")
+		}
+		b.WriteString("(")
+		for j := 0; j < i; j++ {
+			b.WriteString(p.sorts[j].Column)
+			b.WriteString(" = ")
+			writeArg(values[j])
+			b.WriteString(" and ")
+		}
+		b.WriteString(sort.Column)
+		if sort.Desc {
+			b.WriteString(" < ")
+		} else {
+			b.WriteString(" > ")
+		}
+		writeArg(values[i])
+		b.WriteString(")")
+	}
+	b.WriteString(")")
+
+	return append(head, tail...)
+}
+
+func encodeCursorValue(val any) (cursorValue, error) {
+	if valuer, ok := val.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return cursorValue{}, err
+		}
+		val = v
+	}
+
+	rv := reflect.ValueOf(val)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return cursorValue{Type: "null"}, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return cursorValue{Type: "null"}, nil
+	}
+
+	if t, ok := rv.Interface().(time.Time); ok {
+		return cursorValue{Type: "time", Value: t.Format(time.RFC3339Nano)}, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return cursorValue{Type: "bool", Value: strconv.FormatBool(rv.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: "int", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: "uint", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: "float", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.String:
+		return cursorValue{Type: "string", Value: rv.String()}, nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return cursorValue{Type: "bytes", Value: base64.RawURLEncoding.EncodeToString(rv.Bytes())}, nil
+		}
+	}
+
+	return cursorValue{}, fmt.Errorf("不支持的游标值类型 %T", val)
+}
+
+func decodeCursorValue(val cursorValue) (any, error) {
+	switch val.Type {
+	case "null":
+		return nil, nil
+	case "bool":
+		return strconv.ParseBool(val.Value)
+	case "int":
+		return strconv.ParseInt(val.Value, 10, 64)
+	case "uint":
+		return strconv.ParseUint(val.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(val.Value, 64)
+	case "string":
+		return val.Value, nil
+	case "bytes":
+		return base64.RawURLEncoding.DecodeString(val.Value)
+	case "time":
+		return time.Parse(time.RFC3339Nano, val.Value)
+	default:
+		return nil, ErrInvalidCursor
+	}
+}
+
+// fieldName 返回列对应的结构体标签名，去除表名前缀及引号，如 `u`.`id` 对应 id。
+func fieldName(column string) string {
+	if index := strings.LastIndexByte(column, '.'); index >= 0 {
+		column = column[index+1:]
+	}
+
+	return strings.Trim(column, "`\"")
+}
+
+// countPlaceholders 返回 query 中不在引号内的 ? 占位符个数。
+func countPlaceholders(query string) int {
+	var count int
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '?':
+			count++
+		}
+	}
+
+	return count
+}
+
+// topLevelKeyword 返回不在括号及引号内的第一个（last 为 true 时为最后一个）keyword 关键字的位置，
+// 不存在则返回 -1。
+func topLevelKeyword(query, keyword string, last bool) int {
+	lower := strings.ToLower(query)
+	index := -1
+	var depth int
+	var quote byte
+	for i := 0; i < len(lower); i++ {
+		ch := lower[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(lower[i:], keyword) &&
+			(i == 0 || !isNameChar(lower[i-1], false)) &&
+			(i+len(keyword) == len(lower) || !isNameChar(lower[i+len(keyword)], false)):
+			if !last {
+				return i
+			}
+			index = i
+		}
+	}
+
+	return index
+}
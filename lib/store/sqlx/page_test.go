@@ -0,0 +1,187 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type pageUser struct {
+	Id         int64     `db:"id"`
+	Name       string    `db:"name"`
+	CreateTime time.Time `db:"create_time"`
+}
+
+type pageItem struct {
+	Id   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+var pageSorts = []SortField{Desc("create_time"), Asc("`u`.`id`")}
+
+func TestPaginatorBuild(t *testing.T) {
+	p := NewPaginator(pageSorts, WithPageSecret([]byte("secret")))
+	query, args, err := p.Build("select * from user u where status = ? or vip = 1", "", 10, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from user u where status = ? or vip = 1 order by create_time desc, `u`.`id` asc limit 11", query)
+	assert.Equal(t, []any{1}, args)
+
+	now := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor, err := p.Encode(&pageUser{Id: 7, CreateTime: now})
+	assert.Nil(t, err)
+
+	query, args, err = p.Build("select * from user u where status = ? or vip = 1;", cursor, 10, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from user u where (status = ? or vip = 1) and "+
+		"((create_time < ?) or (create_time = ? and `u`.`id` > ?)) order by create_time desc, `u`.`id` asc limit 11", query)
+	assert.Equal(t, []any{1, now, now, int64(7)}, args)
+
+	query, args, err = p.Build("select * from (select * from user where status = 1) u", cursor, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from (select * from user where status = 1) u where "+
+		"((create_time < ?) or (create_time = ? and `u`.`id` > ?)) order by create_time desc, `u`.`id` asc limit 11", query)
+	assert.Equal(t, []any{now, now, int64(7)}, args)
+}
+
+func TestPaginatorBuildGroupBy(t *testing.T) {
+	p := NewPaginator([]SortField{Asc("class")}, WithPageSecret([]byte("secret")))
+	cursor, err := p.Encode(struct {
+		Class int64 `db:"class"`
+	}{Class: 3})
+	assert.Nil(t, err)
+
+	query, args, err := p.Build("select class, count(*) from users where status = ? or vip = 1 "+
+		"group by class having count(*) > ?", cursor, 10, 1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "select class, count(*) from users where (status = ? or vip = 1) and ((class > ?)) "+
+		"group by class having count(*) > ? order by class asc limit 11", query)
+	// 游标参数须位于 HAVING 参数之前。
+	assert.Equal(t, []any{1, int64(3), 2}, args)
+
+	query, args, err = p.Build("select class, count(*) from users where name != '?' group by class "+
+		"having count(*) > ? and max(age) < ?", cursor, 10, 2, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, "select class, count(*) from users where (name != '?') and ((class > ?)) group by class "+
+		"having count(*) > ? and max(age) < ? order by class asc limit 11", query)
+	assert.Equal(t, []any{int64(3), 2, 60}, args)
+
+	query, args, err = p.Build("select class, count(*) from users group by class", cursor, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "select class, count(*) from users where ((class > ?)) group by class "+
+		"order by class asc limit 11", query)
+	assert.Equal(t, []any{int64(3)}, args)
+}
+
+func TestPaginatorLimit(t *testing.T) {
+	p := NewPaginator(pageSorts, WithPageSecret([]byte("secret")))
+	for _, limit := range []int{0, -1} {
+		_, _, err := p.Build("select * from users", "", limit)
+		assert.Equal(t, ErrInvalidPageLimit, err)
+		users := []pageUser{{Id: 1}}
+		_, err = p.Next(&users, limit)
+		assert.Equal(t, ErrInvalidPageLimit, err)
+		assert.Len(t, users, 1)
+	}
+
+	_, _, err := NewPaginator(pageSorts).Build("select * from users", "", 10)
+	assert.Equal(t, ErrNoPageSecret, err)
+}
+
+func TestPaginatorBuildPostgres(t *testing.T) {
+	p := NewPaginator([]SortField{Asc("name"), Asc("id")}, WithPageSecret([]byte("secret")), WithPagePostgres())
+	cursor, err := p.Encode(pageUser{Id: 7, Name: "kevin"})
+	assert.Nil(t, err)
+
+	query, args, err := p.Build("select * from users where status = $1", cursor, 5, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from users where (status = $1) and ((name > $2) or (name = $3 and id > $4)) "+
+		"order by name asc, id asc limit 6", query)
+	assert.Equal(t, []any{1, "kevin", "kevin", int64(7)}, args)
+}
+
+func TestPaginatorCursor(t *testing.T) {
+	p := NewPaginator(pageSorts, WithPageSecret([]byte("secret")))
+	cursor, err := p.Encode(pageUser{Id: 7})
+	assert.Nil(t, err)
+
+	_, err = p.Decode(cursor + "x")
+	assert.Equal(t, ErrInvalidCursor, err)
+	_, err = p.Decode("x" + cursor)
+	assert.Equal(t, ErrInvalidCursor, err)
+	_, err = p.Decode("invalid")
+	assert.Equal(t, ErrInvalidCursor, err)
+
+	other := NewPaginator([]SortField{Asc("create_time"), Asc("`u`.`id`")}, WithPageSecret([]byte("secret")))
+	_, err = other.Decode(cursor)
+	assert.Equal(t, ErrInvalidCursor, err)
+
+	other = NewPaginator(pageSorts, WithPageSecret([]byte("other")))
+	_, err = other.Decode(cursor)
+	assert.Equal(t, ErrInvalidCursor, err)
+
+	_, err = NewPaginator(pageSorts).Decode(cursor)
+	assert.Equal(t, ErrNoPageSecret, err)
+	SetPageSecret([]byte("secret"))
+	defer SetPageSecret(nil)
+	values, err := NewPaginator(pageSorts).Decode(cursor)
+	assert.Nil(t, err)
+	assert.Equal(t, []any{time.Time{}, int64(7)}, values)
+
+	_, err = p.Encode(struct{ Id int64 }{})
+	assert.NotNil(t, err)
+	_, _, err = NewPaginator(nil).Build("select * from users", "", 10)
+	assert.Equal(t, ErrNoSortFields, err)
+}
+
+func TestCursorValues(t *testing.T) {
+	name := "kevin"
+	var empty *string
+	values := []any{true, int8(-1), uint(2), 1.5, name, &name, empty, []byte("abc"),
+		sql.NullInt64{Int64: 3, Valid: true}, sql.NullString{}}
+	expected := []any{true, int64(-1), uint64(2), 1.5, name, name, nil, []byte("abc"), int64(3), nil}
+	for i, val := range values {
+		encoded, err := encodeCursorValue(val)
+		assert.Nil(t, err)
+		decoded, err := decodeCursorValue(encoded)
+		assert.Nil(t, err)
+		assert.Equal(t, expected[i], decoded)
+	}
+
+	_, err := encodeCursorValue(struct{}{})
+	assert.NotNil(t, err)
+	_, err = decodeCursorValue(cursorValue{Type: "unknown"})
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestQueryPage(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		p := NewPaginator([]SortField{Asc("id")}, WithPageSecret([]byte("secret")))
+		mock.ExpectQuery("select id, name from users where status = \\? order by id asc limit 3").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b").AddRow(3, "c"))
+		mock.ExpectQuery("select id, name from users where \\(status = \\?\\) and \\(\\(id > \\?\\)\\) "+
+			"order by id asc limit 3").WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "c"))
+
+		conn := NewConnFromDB(db)
+		var users []pageItem
+		cursor, err := p.QueryPageCtx(context.Background(), conn, &users,
+			"select id, name from users where status = ?", "", 2, 1)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, cursor)
+		assert.Equal(t, []pageItem{{Id: 1, Name: "a"}, {Id: 2, Name: "b"}}, users)
+
+		users = nil
+		cursor, err = p.QueryPage(conn, &users, "select id, name from users where status = ?", cursor, 2, 1)
+		assert.Nil(t, err)
+		assert.Empty(t, cursor)
+		assert.Equal(t, []pageItem{{Id: 3, Name: "c"}}, users)
+
+		_, err = p.QueryPage(conn, &users, "select id, name from users", "invalid", 2)
+		assert.Equal(t, ErrInvalidCursor, err)
+		_, err = p.Next(users, 2)
+		assert.Equal(t, ErrUnsupportedValueType, err)
+	})
+}
@@ -0,0 +1,78 @@
+package sqlx
+
+import (
+	"database/sql"
+	"errors"
+)
+
+const (
+	postgresDriverName  = "postgres"
+	uniqueViolationCode = "23505"
+)
+
+type (
+	// 兼容 pgx 等驱动的错误，如 *pgconn.PgError。
+	sqlStateError interface {
+		SQLState() string
+	}
+
+	// 兼容 lib/pq 驱动的错误，即 *pq.Error。
+	fieldError interface {
+		Get(k byte) string
+	}
+)
+
+// NewPostgres 返回一个 PostgreSQL 连接。
+// 使用前需引入注册名为 postgres 的驱动，如 _ "github.com/lib/pq"。
+func NewPostgres(dataSourceName string, opts ...Option) Conn {
+	opts = append(opts, withPostgresAcceptable(), withPostgresRetryable(), withPostgresBindVar())
+	return NewConn(postgresDriverName, dataSourceName, opts...)
+}
+
+// NewPostgresFromDB 返回给定 sql.DB 的 PostgreSQL 连接。
+func NewPostgresFromDB(db *sql.DB, opts ...Option) Conn {
+	opts = append(opts, withPostgresAcceptable(), withPostgresRetryable(), withPostgresBindVar())
+	return NewConnFromDB(db, opts...)
+}
+
+func withPostgresAcceptable() Option {
+	return func(conn *commonConn) {
+		conn.accept = postgresAcceptable
+	}
+}
+
+// 命名参数绑定为 $n 占位符。
+func withPostgresBindVar() Option {
+	return func(conn *commonConn) {
+		conn.postgres = true
+	}
+}
+
+func postgresAcceptable(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	switch postgresErrorCode(err) {
+	case uniqueViolationCode:
+		return true
+	default:
+		return false
+	}
+}
+
+// postgresErrorCode 返回 PostgreSQL 错误的 SQLSTATE 代码，非 PostgreSQL 错误返回空串。
+func postgresErrorCode(err error) string {
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+
+	var fieldErr fieldError
+	if errors.As(err, &fieldErr) {
+		// 'C' 为 pq 中 SQLSTATE 代码的字段标识
+		return fieldErr.Get('C')
+	}
+
+	return ""
+}
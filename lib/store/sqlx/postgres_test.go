@@ -0,0 +1,49 @@
+package sqlx
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type mockedPgError struct {
+	code string
+}
+
+func (e *mockedPgError) Error() string {
+	return "pg error: " + e.code
+}
+
+func (e *mockedPgError) SQLState() string {
+	return e.code
+}
+
+type mockedPqError struct {
+	code string
+}
+
+func (e *mockedPqError) Error() string {
+	return "pq error: " + e.code
+}
+
+func (e *mockedPqError) Get(k byte) string {
+	if k == 'C' {
+		return e.code
+	}
+
+	return ""
+}
+
+func TestPostgresAcceptable(t *testing.T) {
+	conn := NewPostgres("no_postgres").(*commonConn)
+	assert.EqualValues(t, reflect.ValueOf(postgresAcceptable).Pointer(), reflect.ValueOf(conn.accept).Pointer())
+	assert.True(t, postgresAcceptable(nil))
+	assert.False(t, postgresAcceptable(errors.New("any")))
+	assert.True(t, postgresAcceptable(&mockedPgError{code: uniqueViolationCode}))
+	assert.True(t, postgresAcceptable(fmt.Errorf("wrapped: %w", &mockedPgError{code: uniqueViolationCode})))
+	assert.True(t, postgresAcceptable(&mockedPqError{code: uniqueViolationCode}))
+	assert.False(t, postgresAcceptable(&mockedPgError{code: "42P01"}))
+	assert.False(t, postgresAcceptable(&mockedPqError{code: "42P01"}))
+}
@@ -0,0 +1,115 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/gotid/god/lib/mathx"
+	"time"
+)
+
+const (
+	deadlockCode        uint16 = 1213
+	lockWaitTimeoutCode uint16 = 1205
+
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+
+	defaultTransactBackoff    = 20 * time.Millisecond
+	defaultMaxTransactBackoff = time.Second
+	transactBackoffDeviation  = 0.5
+)
+
+var transactJitter = mathx.NewUnstable(transactBackoffDeviation)
+
+// TransactRetry 是事务因死锁、锁等待超时或序列化失败而失败时的重试策略。
+// 重试将重新执行整个事务函数，故函数内不应有数据库之外的副作用。
+type TransactRetry struct {
+	// MaxAttempts 最大尝试次数（含首次），小于 2 则不重试。
+	MaxAttempts int
+	// Backoff 首次重试前的等待时长，此后按指数增长并附加随机抖动，默认 20ms。
+	Backoff time.Duration
+	// MaxBackoff 单次等待时长的上限，默认 1s。
+	MaxBackoff time.Duration
+	// Retryable 判断错误是否可重试，为空时使用驱动默认的分类器。
+	Retryable func(err error) bool
+}
+
+// WithTransactRetry 开启事务的自动重试。
+func WithTransactRetry(retry TransactRetry) Option {
+	return func(conn *commonConn) {
+		if retry.Backoff <= 0 {
+			retry.Backoff = defaultTransactBackoff
+		}
+		if retry.MaxBackoff <= 0 {
+			retry.MaxBackoff = defaultMaxTransactBackoff
+		}
+		conn.txRetry = &retry
+	}
+}
+
+func withMySQLRetryable() Option {
+	return func(conn *commonConn) {
+		conn.retryable = mysqlRetryable
+	}
+}
+
+func withPostgresRetryable() Option {
+	return func(conn *commonConn) {
+		conn.retryable = postgresRetryable
+	}
+}
+
+func mysqlRetryable(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+
+	switch myErr.Number {
+	case deadlockCode, lockWaitTimeoutCode:
+		return true
+	default:
+		return false
+	}
+}
+
+func postgresRetryable(err error) bool {
+	switch postgresErrorCode(err) {
+	case serializationFailureCode, deadlockDetectedCode:
+		return true
+	default:
+		return false
+	}
+}
+
+// 判断第 attempt 次尝试失败后是否应重试。
+func (r *TransactRetry) shouldRetry(attempt int, err error, retryable func(error) bool) bool {
+	if err == nil || attempt >= r.MaxAttempts {
+		return false
+	}
+
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+
+	return retryable != nil && retryable(err)
+}
+
+// 等待第 attempt 次重试前的退避时长，上下文结束时返回其错误。
+func (r *TransactRetry) wait(ctx context.Context, attempt int) error {
+	backoff := r.Backoff << (attempt - 1)
+	if backoff <= 0 || backoff > r.MaxBackoff {
+		backoff = r.MaxBackoff
+	}
+
+	timer := time.NewTimer(transactJitter.AroundDuration(backoff))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMySQLRetryable(t *testing.T) {
+	assert.True(t, mysqlRetryable(&mysql.MySQLError{Number: deadlockCode}))
+	assert.True(t, mysqlRetryable(&mysql.MySQLError{Number: lockWaitTimeoutCode}))
+	assert.False(t, mysqlRetryable(&mysql.MySQLError{Number: duplicateEntryCode}))
+	assert.False(t, mysqlRetryable(errors.New("any")))
+	assert.True(t, mysqlRetryable(fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: deadlockCode})))
+}
+
+func TestPostgresRetryable(t *testing.T) {
+	assert.True(t, postgresRetryable(&mockedPgError{code: serializationFailureCode}))
+	assert.True(t, postgresRetryable(&mockedPqError{code: deadlockDetectedCode}))
+	assert.False(t, postgresRetryable(&mockedPgError{code: uniqueViolationCode}))
+	assert.False(t, postgresRetryable(errors.New("any")))
+}
+
+func TestTransactRetry(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("update users").WillReturnError(&mysql.MySQLError{Number: deadlockCode})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec("update users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		var attempts int
+		conn := NewConnFromDB(db, withMySQLRetryable(), WithTransactRetry(TransactRetry{
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+		}))
+		assert.Nil(t, conn.TransactCtx(context.Background(), func(ctx context.Context, session Session) error {
+			attempts++
+			_, err := session.ExecCtx(ctx, "update users")
+			return err
+		}))
+		assert.Equal(t, 2, attempts)
+	})
+}
+
+func TestTransactRetryExhausted(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		for i := 0; i < 2; i++ {
+			mock.ExpectBegin()
+			mock.ExpectExec("update users").WillReturnError(&mysql.MySQLError{Number: lockWaitTimeoutCode})
+			mock.ExpectRollback()
+		}
+
+		var attempts int
+		conn := NewConnFromDB(db, withMySQLRetryable(), WithTransactRetry(TransactRetry{
+			MaxAttempts: 2,
+			Backoff:     time.Millisecond,
+		}))
+		err := conn.Transact(func(session Session) error {
+			attempts++
+			_, err := session.Exec("update users")
+			return err
+		})
+		assert.Equal(t, lockWaitTimeoutCode, err.(*mysql.MySQLError).Number)
+		assert.Equal(t, 2, attempts)
+	})
+}
+
+func TestTransactNotRetryable(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		errAny := errors.New("any")
+		var attempts int
+		conn := NewConnFromDB(db, withMySQLRetryable(), WithTransactRetry(TransactRetry{
+			MaxAttempts: 3,
+		}))
+		assert.Equal(t, errAny, conn.Transact(func(session Session) error {
+			attempts++
+			return errAny
+		}))
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestTransactRetryCustomRetryable(t *testing.T) {
+	errRetry := errors.New("retry")
+	retry := TransactRetry{
+		MaxAttempts: 3,
+		Retryable: func(err error) bool {
+			return err == errRetry
+		},
+	}
+	assert.True(t, retry.shouldRetry(1, errRetry, nil))
+	assert.False(t, retry.shouldRetry(3, errRetry, nil))
+	assert.False(t, retry.shouldRetry(1, errors.New("any"), mysqlRetryable))
+	assert.False(t, retry.shouldRetry(1, nil, nil))
+}
+
+func TestTransactRetryWait(t *testing.T) {
+	retry := TransactRetry{
+		Backoff:    time.Millisecond,
+		MaxBackoff: 2 * time.Millisecond,
+	}
+	assert.Nil(t, retry.wait(context.Background(), 1))
+	assert.Nil(t, retry.wait(context.Background(), 100))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	retry.Backoff = time.Hour
+	retry.MaxBackoff = time.Hour
+	assert.Equal(t, context.Canceled, retry.wait(ctx, 1))
+}
+
+func TestTransactRetryRollbackFailed(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		errRollback := errors.New("rollback")
+		mock.ExpectBegin()
+		mock.ExpectExec("update users").WillReturnError(&mysql.MySQLError{Number: deadlockCode})
+		mock.ExpectRollback().WillReturnError(errRollback)
+		mock.ExpectBegin()
+		mock.ExpectExec("update users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		var attempts int
+		conn := NewConnFromDB(db, withMySQLRetryable(), WithTransactRetry(TransactRetry{
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+		}))
+		// 回滚失败时仍保留原错误，重试策略据此判断。
+		assert.Nil(t, conn.TransactCtx(context.Background(), func(ctx context.Context, session Session) error {
+			attempts++
+			_, err := session.ExecCtx(ctx, "update users")
+			return err
+		}))
+		assert.Equal(t, 2, attempts)
+	})
+}
@@ -0,0 +1,349 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/breaker"
+	"github.com/gotid/god/lib/timex"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// RoundRobinPolicy 轮询选择从库。
+	RoundRobinPolicy ReplicaPolicy = "round_robin"
+	// RandomPolicy 随机选择从库。
+	RandomPolicy ReplicaPolicy = "random"
+	// P2cPolicy 随机选择两个从库，取其中负载（延迟 * 在途请求）较低者。
+	P2cPolicy ReplicaPolicy = "p2c"
+
+	rolePrimary = "primary"
+	roleReplica = "replica"
+
+	// 响应耗时的指数加权平均系数，越大越平滑。
+	latencyDecay = 0.9
+)
+
+var (
+	forcePrimaryKey contextKey
+
+	// 不计入从库断路器的错误。
+	replicaAcceptableErrors = []error{
+		ErrNotFound, sql.ErrTxDone, context.Canceled, ErrNotSettable, ErrUnsupportedValueType,
+		ErrNotMatchDestination, ErrNotReadableValue, ErrStreamUnsupported,
+	}
+)
+
+type (
+	// ReplicaPolicy 是从库的选择策略。
+	ReplicaPolicy string
+
+	// RWOption 自定义读写分离连接的方法。
+	RWOption func(*rwConn)
+
+	contextKey struct{}
+
+	// 读写分离连接：查询路由至从库，写入、预编译及事务路由至主库。
+	rwConn struct {
+		primary  Conn
+		replicas []*replica
+		policy   ReplicaPolicy
+		next     uint64
+		lock     sync.Mutex
+		r        *rand.Rand
+	}
+
+	// 记录流式查询中逐行回调的耗时及错误，二者均不计入从库的延迟及断路器。
+	streamHandler struct {
+		handle  func() error
+		err     error
+		elapsed time.Duration
+	}
+
+	replica struct {
+		conn     Conn
+		brk      breaker.Breaker
+		latency  uint64
+		inflight int64
+	}
+)
+
+// NewRWConn 返回一个读写分离的数据库连接 Conn。
+// 查询类方法路由至从库，Exec、Prepare、RawDB 及事务内的全部操作路由至主库。
+// 从库断路器打开时，查询自动回落至主库。
+func NewRWConn(primary Conn, replicas []Conn, opts ...RWOption) Conn {
+	conn := &rwConn{
+		primary: primary,
+		policy:  RoundRobinPolicy,
+		r:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for i, r := range replicas {
+		conn.replicas = append(conn.replicas, &replica{
+			conn: r,
+			brk:  breaker.New(breaker.WithName(fmt.Sprintf("sql-replica-%d", i))),
+		})
+	}
+	for _, opt := range opts {
+		opt(conn)
+	}
+
+	return conn
+}
+
+// WithReplicaPolicy 自定义从库选择策略，默认为 RoundRobinPolicy。
+func WithReplicaPolicy(policy ReplicaPolicy) RWOption {
+	return func(conn *rwConn) {
+		conn.policy = policy
+	}
+}
+
+// ForcePrimary 返回强制读主库的上下文，用于写后立即读的场景。
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey, true)
+}
+
+func isForcePrimary(ctx context.Context) bool {
+	force, ok := ctx.Value(forcePrimaryKey).(bool)
+	return ok && force
+}
+
+func (c *rwConn) Exec(query string, args ...any) (sql.Result, error) {
+	return c.ExecCtx(context.Background(), query, args...)
+}
+
+func (c *rwConn) ExecCtx(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	metricRoleReqs.Inc("Exec", rolePrimary)
+	return c.primary.ExecCtx(ctx, query, args...)
+}
+
+func (c *rwConn) ExecNamed(query string, arg any) (sql.Result, error) {
+	return c.ExecNamedCtx(context.Background(), query, arg)
+}
+
+func (c *rwConn) ExecNamedCtx(ctx context.Context, query string, arg any) (sql.Result, error) {
+	metricRoleReqs.Inc("ExecNamed", rolePrimary)
+	return c.primary.ExecNamedCtx(ctx, query, arg)
+}
+
+func (c *rwConn) Prepare(query string) (StmtSession, error) {
+	return c.PrepareCtx(context.Background(), query)
+}
+
+func (c *rwConn) PrepareCtx(ctx context.Context, query string) (StmtSession, error) {
+	metricRoleReqs.Inc("Prepare", rolePrimary)
+	return c.primary.PrepareCtx(ctx, query)
+}
+
+func (c *rwConn) QueryRow(v any, query string, args ...any) error {
+	return c.QueryRowCtx(context.Background(), v, query, args...)
+}
+
+func (c *rwConn) QueryRowCtx(ctx context.Context, v any, query string, args ...any) error {
+	return c.read(ctx, "QueryRow", func(conn Conn) error {
+		return conn.QueryRowCtx(ctx, v, query, args...)
+	})
+}
+
+func (c *rwConn) QueryRowNamed(v any, query string, arg any) error {
+	return c.QueryRowNamedCtx(context.Background(), v, query, arg)
+}
+
+func (c *rwConn) QueryRowNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	return c.read(ctx, "QueryRowNamed", func(conn Conn) error {
+		return conn.QueryRowNamedCtx(ctx, v, query, arg)
+	})
+}
+
+func (c *rwConn) QueryRowPartial(v any, query string, args ...any) error {
+	return c.QueryRowPartialCtx(context.Background(), v, query, args...)
+}
+
+func (c *rwConn) QueryRowPartialCtx(ctx context.Context, v any, query string, args ...any) error {
+	return c.read(ctx, "QueryRowPartial", func(conn Conn) error {
+		return conn.QueryRowPartialCtx(ctx, v, query, args...)
+	})
+}
+
+func (c *rwConn) QueryRows(v any, query string, args ...any) error {
+	return c.QueryRowsCtx(context.Background(), v, query, args...)
+}
+
+func (c *rwConn) QueryRowsCtx(ctx context.Context, v any, query string, args ...any) error {
+	return c.read(ctx, "QueryRows", func(conn Conn) error {
+		return conn.QueryRowsCtx(ctx, v, query, args...)
+	})
+}
+
+func (c *rwConn) QueryRowsNamed(v any, query string, arg any) error {
+	return c.QueryRowsNamedCtx(context.Background(), v, query, arg)
+}
+
+func (c *rwConn) QueryRowsNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	return c.read(ctx, "QueryRowsNamed", func(conn Conn) error {
+		return conn.QueryRowsNamedCtx(ctx, v, query, arg)
+	})
+}
+
+func (c *rwConn) QueryRowsPartial(v any, query string, args ...any) error {
+	return c.QueryRowsPartialCtx(context.Background(), v, query, args...)
+}
+
+func (c *rwConn) QueryRowsPartialCtx(ctx context.Context, v any, query string, args ...any) error {
+	return c.read(ctx, "QueryRowsPartial", func(conn Conn) error {
+		return conn.QueryRowsPartialCtx(ctx, v, query, args...)
+	})
+}
+
+func (c *rwConn) QueryRowsStream(v any, handle func() error, query string, args ...any) error {
+	return c.QueryRowsStreamCtx(context.Background(), v, handle, query, args...)
+}
+
+func (c *rwConn) QueryRowsStreamCtx(ctx context.Context, v any, handle func() error,
+	query string, args ...any) error {
+	h := &streamHandler{handle: handle}
+	return c.readStream(ctx, "QueryRowsStream", h, func(conn Conn) error {
+		ss, ok := conn.(StreamSession)
+		if !ok {
+			return ErrStreamUnsupported
+		}
+
+		return ss.QueryRowsStreamCtx(ctx, v, h.do, query, args...)
+	})
+}
+
+func (c *rwConn) RawDB() (*sql.DB, error) {
+	return c.primary.RawDB()
+}
+
+func (c *rwConn) Transact(fn func(Session) error) error {
+	return c.TransactCtx(context.Background(), func(_ context.Context, session Session) error {
+		return fn(session)
+	})
+}
+
+func (c *rwConn) TransactCtx(ctx context.Context, fn func(context.Context, Session) error) error {
+	metricRoleReqs.Inc("Transact", rolePrimary)
+	return c.primary.TransactCtx(ctx, fn)
+}
+
+func (c *rwConn) read(ctx context.Context, command string, fn func(conn Conn) error) error {
+	return c.readStream(ctx, command, nil, fn)
+}
+
+func (c *rwConn) readStream(ctx context.Context, command string, h *streamHandler,
+	fn func(conn Conn) error) error {
+	if len(c.replicas) == 0 || isForcePrimary(ctx) {
+		metricRoleReqs.Inc(command, rolePrimary)
+		return fn(c.primary)
+	}
+
+	r := c.pick()
+	err := r.do(fn, h)
+	if err == breaker.ErrServiceUnavailable {
+		metricReqErr.Inc(command, "replica_breaker")
+		metricRoleReqs.Inc(command, rolePrimary)
+		return fn(c.primary)
+	}
+
+	metricRoleReqs.Inc(command, roleReplica)
+	return err
+}
+
+func (c *rwConn) pick() *replica {
+	n := len(c.replicas)
+	if n == 1 {
+		return c.replicas[0]
+	}
+
+	switch c.policy {
+	case RandomPolicy:
+		return c.replicas[c.randIntn(n)]
+	case P2cPolicy:
+		a := c.randIntn(n)
+		b := c.randIntn(n - 1)
+		if b >= a {
+			b++
+		}
+		r1, r2 := c.replicas[a], c.replicas[b]
+		if r1.load() > r2.load() {
+			return r2
+		}
+		return r1
+	default:
+		return c.replicas[atomic.AddUint64(&c.next, 1)%uint64(n)]
+	}
+}
+
+func (c *rwConn) randIntn(n int) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.r.Intn(n)
+}
+
+func (r *replica) do(fn func(conn Conn) error, h *streamHandler) error {
+	atomic.AddInt64(&r.inflight, 1)
+	start := timex.Now()
+	defer func() {
+		atomic.AddInt64(&r.inflight, -1)
+		r.observe(timex.Since(start) - h.duration())
+	}()
+
+	return r.brk.DoWithAcceptable(func() error {
+		return fn(r.conn)
+	}, func(err error) bool {
+		return replicaAcceptable(err) || h.isHandleErr(err)
+	})
+}
+
+func (r *replica) load() int64 {
+	latency := int64(atomic.LoadUint64(&r.latency))
+	inflight := atomic.LoadInt64(&r.inflight)
+	return (latency + 1) * (inflight + 1)
+}
+
+func (r *replica) observe(duration time.Duration) {
+	latency := uint64(duration)
+	old := atomic.LoadUint64(&r.latency)
+	if old > 0 {
+		latency = uint64(float64(old)*latencyDecay + float64(latency)*(1-latencyDecay))
+	}
+	atomic.StoreUint64(&r.latency, latency)
+}
+
+func (h *streamHandler) do() error {
+	start := timex.Now()
+	h.err = h.handle()
+	h.elapsed += timex.Since(start)
+	return h.err
+}
+
+func (h *streamHandler) duration() time.Duration {
+	if h == nil {
+		return 0
+	}
+
+	return h.elapsed
+}
+
+func (h *streamHandler) isHandleErr(err error) bool {
+	return h != nil && h.err != nil && h.err == err
+}
+
+// 仅连接及服务端错误计入从库断路器，查询无结果或解编组错误不计入。
+func replicaAcceptable(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	for _, target := range replicaAcceptableErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/breaker"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type roleConn struct {
+	Conn
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (c roleConn) ExecCtx(_ context.Context, _ string, _ ...any) (sql.Result, error) {
+	*c.calls = append(*c.calls, c.name)
+	return nil, c.err
+}
+
+func (c roleConn) PrepareCtx(_ context.Context, _ string) (StmtSession, error) {
+	*c.calls = append(*c.calls, c.name)
+	return nil, c.err
+}
+
+func (c roleConn) QueryRowCtx(_ context.Context, _ any, _ string, _ ...any) error {
+	*c.calls = append(*c.calls, c.name)
+	return c.err
+}
+
+func (c roleConn) QueryRowPartialCtx(_ context.Context, _ any, _ string, _ ...any) error {
+	*c.calls = append(*c.calls, c.name)
+	return c.err
+}
+
+func (c roleConn) QueryRowsCtx(_ context.Context, _ any, _ string, _ ...any) error {
+	*c.calls = append(*c.calls, c.name)
+	return c.err
+}
+
+func (c roleConn) QueryRowsPartialCtx(_ context.Context, _ any, _ string, _ ...any) error {
+	*c.calls = append(*c.calls, c.name)
+	return c.err
+}
+
+func (c roleConn) QueryRowsStream(v any, handle func() error, query string, args ...any) error {
+	return c.QueryRowsStreamCtx(context.Background(), v, handle, query, args...)
+}
+
+func (c roleConn) QueryRowsStreamCtx(_ context.Context, _ any, handle func() error, _ string, _ ...any) error {
+	*c.calls = append(*c.calls, c.name)
+	if c.err != nil {
+		return c.err
+	}
+
+	return handle()
+}
+
+func (c roleConn) TransactCtx(ctx context.Context, fn func(context.Context, Session) error) error {
+	*c.calls = append(*c.calls, c.name)
+	return fn(ctx, nil)
+}
+
+func TestRWConn(t *testing.T) {
+	var calls []string
+	primary := roleConn{name: "primary", calls: &calls}
+	conn := NewRWConn(primary, []Conn{
+		roleConn{name: "r0", calls: &calls},
+		roleConn{name: "r1", calls: &calls},
+	})
+
+	var val string
+	_, err := conn.Exec("any")
+	assert.Nil(t, err)
+	_, err = conn.Prepare("any")
+	assert.Nil(t, err)
+	assert.Nil(t, conn.QueryRow(&val, "any"))
+	assert.Nil(t, conn.QueryRowPartial(&val, "any"))
+	assert.Nil(t, conn.QueryRows(&val, "any"))
+	assert.Nil(t, conn.QueryRowsPartial(&val, "any"))
+	assert.Nil(t, conn.QueryRowCtx(ForcePrimary(context.Background()), &val, "any"))
+	assert.Nil(t, conn.Transact(func(session Session) error {
+		return nil
+	}))
+	assert.Equal(t, []string{"primary", "primary", "r1", "r0", "r1", "r0", "primary", "primary"}, calls)
+}
+
+func TestRWConnWithoutReplicas(t *testing.T) {
+	var calls []string
+	conn := NewRWConn(roleConn{name: "primary", calls: &calls}, nil)
+	var val string
+	assert.Nil(t, conn.QueryRows(&val, "any"))
+	assert.Equal(t, []string{"primary"}, calls)
+}
+
+func TestRWConnPolicies(t *testing.T) {
+	for _, policy := range []ReplicaPolicy{RandomPolicy, P2cPolicy} {
+		policy := policy
+		t.Run(string(policy), func(t *testing.T) {
+			var calls []string
+			conn := NewRWConn(roleConn{name: "primary", calls: &calls}, []Conn{
+				roleConn{name: "r0", calls: &calls},
+				roleConn{name: "r1", calls: &calls},
+				roleConn{name: "r2", calls: &calls},
+			}, WithReplicaPolicy(policy))
+			var val string
+			for i := 0; i < 100; i++ {
+				assert.Nil(t, conn.QueryRow(&val, "any"))
+			}
+			assert.Len(t, calls, 100)
+			assert.NotContains(t, calls, "primary")
+		})
+	}
+}
+
+func TestRWConnReplicaBreaker(t *testing.T) {
+	var calls []string
+	conn := NewRWConn(roleConn{name: "primary", calls: &calls}, []Conn{
+		roleConn{name: "r0", calls: &calls, err: errors.New("bad conn")},
+	})
+	var val string
+	var fallback bool
+	for i := 0; i < 1000; i++ {
+		if err := conn.QueryRow(&val, "any"); err == nil {
+			fallback = true
+		}
+	}
+	assert.True(t, fallback)
+	assert.Contains(t, calls, "primary")
+}
+
+func TestRWConnStreamHandlerError(t *testing.T) {
+	var calls []string
+	conn := NewRWConn(roleConn{name: "primary", calls: &calls}, []Conn{
+		roleConn{name: "r0", calls: &calls},
+	})
+	errHandle := errors.New("handle")
+	var val string
+	for i := 0; i < 1000; i++ {
+		err := conn.(StreamSession).QueryRowsStreamCtx(context.Background(), &val, func() error {
+			return errHandle
+		}, "any")
+		assert.Equal(t, errHandle, err)
+	}
+	// 回调返回的错误不计入从库断路器。
+	assert.NotContains(t, calls, "primary")
+
+	r := conn.(*rwConn).replicas[0]
+	r.latency = 0
+	assert.Nil(t, conn.(StreamSession).QueryRowsStreamCtx(context.Background(), &val, func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, "any"))
+	// 回调耗时不计入从库延迟。
+	assert.True(t, time.Duration(r.latency) < 10*time.Millisecond)
+}
+
+func TestReplicaAcceptable(t *testing.T) {
+	assert.True(t, replicaAcceptable(nil))
+	assert.True(t, replicaAcceptable(ErrNotFound))
+	assert.True(t, replicaAcceptable(ErrNotMatchDestination))
+	assert.True(t, replicaAcceptable(fmt.Errorf("wrapped: %w", ErrNotFound)))
+	assert.False(t, replicaAcceptable(breaker.ErrServiceUnavailable))
+	assert.False(t, replicaAcceptable(errors.New("any")))
+}
@@ -0,0 +1,377 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/hash"
+	"github.com/gotid/god/lib/mr"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// ShardTablePlaceholder 是 QueryRowsAll 查询语句中表示分表名称的占位符。
+	ShardTablePlaceholder = "{table}"
+
+	defaultShardTableFormat = "%s_%02d"
+)
+
+var (
+	// ErrNoShards 表示未提供分片连接。
+	ErrNoShards = errors.New("未提供分片连接")
+	// ErrInvalidShardTables 表示分表数量小于分库数量。
+	ErrInvalidShardTables = errors.New("分表数量不能小于分库数量")
+	// ErrInvalidShardKey 表示分片键的类型不受分片函数支持。
+	ErrInvalidShardKey = errors.New("不支持的分片键类型")
+	// ErrShardNotFound 表示分片键不属于任何分片。
+	ErrShardNotFound = errors.New("分片键不属于任何分片")
+	// ErrCrossShardTransaction 表示事务涉及多个分库，不支持跨库事务。
+	ErrCrossShardTransaction = errors.New("不支持跨分片事务")
+)
+
+type (
+	// ShardFunc 返回分片键 key 所在的分片序号，n 为分片总数，启用分表时为分表总数。
+	ShardFunc func(key any, n int) (int, error)
+
+	// ShardOption 自定义分片连接的方法。
+	ShardOption func(*ShardedConn)
+
+	// ShardedConn 是按分片键路由的数据库连接，支持分库及分库分表。
+	// 启用分表时，分表按序号均匀连续地分布至各分库，如 4 库 16 表时 order_00 ~ order_03 位于第 0 库。
+	ShardedConn struct {
+		conns       []Conn
+		shard       ShardFunc
+		tables      int
+		tableFormat string
+	}
+)
+
+// NewShardedConn 返回一个分片连接，默认以 ModShard 按分库数量取模。
+func NewShardedConn(conns []Conn, opts ...ShardOption) (*ShardedConn, error) {
+	if len(conns) == 0 {
+		return nil, ErrNoShards
+	}
+
+	c := &ShardedConn{
+		conns:       conns,
+		shard:       ModShard,
+		tableFormat: defaultShardTableFormat,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.tables > 0 && c.tables < len(conns) {
+		return nil, ErrInvalidShardTables
+	}
+
+	return c, nil
+}
+
+// WithShardFunc 自定义分片函数，如 ModShard、RangeShard 或 ConsistentHashShard。
+func WithShardFunc(fn ShardFunc) ShardOption {
+	return func(c *ShardedConn) {
+		c.shard = fn
+	}
+}
+
+// WithShardTables 启用分表，tables 为分表总数，分片函数将以分表总数计算分片。
+func WithShardTables(tables int) ShardOption {
+	return func(c *ShardedConn) {
+		c.tables = tables
+	}
+}
+
+// WithShardTableFormat 自定义分表名称的格式，参数依次为表名及分表序号，默认为 %s_%02d。
+func WithShardTableFormat(format string) ShardOption {
+	return func(c *ShardedConn) {
+		c.tableFormat = format
+	}
+}
+
+// ModShard 按分片键取模，整数键直接取模，其余键先取哈希。
+func ModShard(key any, n int) (int, error) {
+	if v, ok := shardUint(key); ok {
+		return int(v % uint64(n)), nil
+	}
+
+	if v, ok := shardInt(key); ok {
+		index := v % int64(n)
+		if index < 0 {
+			index += int64(n)
+		}
+		return int(index), nil
+	}
+
+	return int(hash.Hash(shardBytes(key)) % uint64(n)), nil
+}
+
+// RangeShard 按整数分片键的范围分片，bounds 依次为各分片的上界（不含），数量须与分片总数一致。
+// 如 RangeShard(1000000, 2000000) 将 [0, 1000000) 路由至 0 号分片，[1000000, 2000000) 路由至 1 号分片。
+func RangeShard(bounds ...int64) ShardFunc {
+	return func(key any, n int) (int, error) {
+		if len(bounds) != n {
+			return 0, fmt.Errorf("范围分片的上界数量 %d 与分片总数 %d 不一致", len(bounds), n)
+		}
+
+		if v, ok := shardUint(key); ok && v > math.MaxInt64 {
+			return 0, fmt.Errorf("%w：%d", ErrShardNotFound, v)
+		}
+
+		v, ok := shardInt(key)
+		if !ok {
+			return 0, ErrInvalidShardKey
+		}
+
+		for i, bound := range bounds {
+			if v < bound {
+				return i, nil
+			}
+		}
+
+		return 0, fmt.Errorf("%w：%d", ErrShardNotFound, v)
+	}
+}
+
+// ConsistentHashShard 以 hash.ConsistentHash 分片，增减分片时仅少量分片键迁移。
+// 返回的分片函数应仅用于一个分片连接。
+func ConsistentHashShard() ShardFunc {
+	var once sync.Once
+	ring := hash.NewConsistentHash()
+
+	return func(key any, n int) (int, error) {
+		once.Do(func() {
+			for i := 0; i < n; i++ {
+				ring.Add(i)
+			}
+		})
+
+		node, ok := ring.Get(string(shardBytes(key)))
+		if !ok {
+			return 0, ErrShardNotFound
+		}
+
+		return node.(int), nil
+	}
+}
+
+// Shard 返回分片键所在的分库连接。
+func (c *ShardedConn) Shard(key any) (Conn, error) {
+	conn, _, err := c.Route(key, "")
+	return conn, err
+}
+
+// Table 返回分片键所在的分表名称，未启用分表时返回 table 本身。
+func (c *ShardedConn) Table(key any, table string) (string, error) {
+	_, name, err := c.Route(key, table)
+	return name, err
+}
+
+// Route 返回分片键所在的分库连接及分表名称，未启用分表时分表名称即为 table。
+func (c *ShardedConn) Route(key any, table string) (Conn, string, error) {
+	slot, err := c.slot(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return c.conns[c.connIndex(slot)], c.tableName(table, slot), nil
+}
+
+// Transact 在分片键所在的分库上执行事务，参见 TransactCtx。
+func (c *ShardedConn) Transact(keys []any, fn func(Session) error) error {
+	return c.TransactCtx(context.Background(), keys, func(_ context.Context, session Session) error {
+		return fn(session)
+	})
+}
+
+// TransactCtx 在分片键所在的分库上执行事务，keys 分布于多个分库时返回 ErrCrossShardTransaction。
+// 同一分库内的多张分表可在同一事务中操作。
+func (c *ShardedConn) TransactCtx(ctx context.Context, keys []any,
+	fn func(context.Context, Session) error) error {
+	if len(keys) == 0 {
+		return ErrShardNotFound
+	}
+
+	index := -1
+	for _, key := range keys {
+		slot, err := c.slot(key)
+		if err != nil {
+			return err
+		}
+
+		i := c.connIndex(slot)
+		if index >= 0 && i != index {
+			return ErrCrossShardTransaction
+		}
+		index = i
+	}
+
+	return c.conns[index].TransactCtx(ctx, fn)
+}
+
+// Scatter 在全部分片上并发执行 fn，参见 ScatterCtx。
+func (c *ShardedConn) Scatter(table string, fn func(ctx context.Context, conn Conn, table string) error) error {
+	return c.ScatterCtx(context.Background(), table, fn)
+}
+
+// ScatterCtx 在全部分片上并发执行 fn，启用分表时对每张分表执行一次，任一分片出错即取消其余分片。
+func (c *ShardedConn) ScatterCtx(ctx context.Context, table string,
+	fn func(ctx context.Context, conn Conn, table string) error) error {
+	return c.scatter(ctx, table, func(ctx context.Context, _ int, conn Conn, table string) error {
+		return fn(ctx, conn, table)
+	})
+}
+
+func (c *ShardedConn) scatter(ctx context.Context, table string,
+	fn func(ctx context.Context, slot int, conn Conn, table string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fns := make([]func() error, c.slots())
+	for i := range fns {
+		slot := i
+		fns[i] = func() error {
+			if err := fn(ctx, slot, c.conns[c.connIndex(slot)], c.tableName(table, slot)); err != nil {
+				cancel()
+				return err
+			}
+			return nil
+		}
+	}
+
+	return mr.Finish(fns...)
+}
+
+// QueryRowsAll 在全部分片上查询并合并结果，参见 QueryRowsAllCtx。
+func (c *ShardedConn) QueryRowsAll(v any, table, query string, args ...any) error {
+	return c.QueryRowsAllCtx(context.Background(), v, table, query, args...)
+}
+
+// QueryRowsAllCtx 在全部分片上并发查询，并按分片顺序将结果合并至切片指针 v。
+// query 以 ShardTablePlaceholder 表示分表名称，如 select * from {table} where uid = ?，
+// 未启用分表时替换为 table 本身。
+func (c *ShardedConn) QueryRowsAllCtx(ctx context.Context, v any, table, query string, args ...any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return ErrNotSettable
+	}
+
+	results := make([]reflect.Value, c.slots())
+	err := c.scatter(ctx, table, func(ctx context.Context, slot int, conn Conn, name string) error {
+		result := reflect.New(rv.Elem().Type())
+		q := strings.ReplaceAll(query, ShardTablePlaceholder, name)
+		if err := conn.QueryRowsCtx(ctx, result.Interface(), q, args...); err != nil {
+			return err
+		}
+
+		results[slot] = result.Elem()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	merged := reflect.MakeSlice(rv.Elem().Type(), 0, 0)
+	for _, result := range results {
+		if result.IsValid() {
+			merged = reflect.AppendSlice(merged, result)
+		}
+	}
+	rv.Elem().Set(merged)
+
+	return nil
+}
+
+func (c *ShardedConn) slot(key any) (int, error) {
+	n := c.slots()
+	slot, err := c.shard(key, n)
+	if err != nil {
+		return 0, err
+	}
+	if slot < 0 || slot >= n {
+		return 0, fmt.Errorf("%w：%v", ErrShardNotFound, key)
+	}
+
+	return slot, nil
+}
+
+func (c *ShardedConn) slots() int {
+	if c.tables > 0 {
+		return c.tables
+	}
+
+	return len(c.conns)
+}
+
+func (c *ShardedConn) connIndex(slot int) int {
+	if c.tables > 0 {
+		return slot * len(c.conns) / c.tables
+	}
+
+	return slot
+}
+
+func (c *ShardedConn) tableName(table string, slot int) string {
+	if c.tables == 0 || len(table) == 0 {
+		return table
+	}
+
+	return fmt.Sprintf(c.tableFormat, table, slot)
+}
+
+func shardInt(key any) (int64, bool) {
+	switch v := key.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+// shardUint 返回无符号整数分片键，以免超出 int64 的键转换为负数。
+func shardUint(key any) (uint64, bool) {
+	switch v := key.(type) {
+	case uint:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func shardBytes(key any) []byte {
+	switch v := key.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	default:
+		if i, ok := shardInt(key); ok {
+			return []byte(strconv.FormatInt(i, 10))
+		}
+		return []byte(fmt.Sprint(key))
+	}
+}
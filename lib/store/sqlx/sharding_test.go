@@ -0,0 +1,213 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync"
+	"testing"
+)
+
+type shardConn struct {
+	Conn
+	index   int
+	lock    *sync.Mutex
+	queries *[]string
+	err     error
+}
+
+func (c shardConn) QueryRowsCtx(_ context.Context, v any, query string, _ ...any) error {
+	c.lock.Lock()
+	*c.queries = append(*c.queries, fmt.Sprintf("%d:%s", c.index, query))
+	c.lock.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+
+	*v.(*[]int) = []int{c.index}
+	return nil
+}
+
+func (c shardConn) TransactCtx(ctx context.Context, fn func(context.Context, Session) error) error {
+	c.lock.Lock()
+	*c.queries = append(*c.queries, fmt.Sprintf("%d:transact", c.index))
+	c.lock.Unlock()
+	return fn(ctx, nil)
+}
+
+func newShardConns(n int) ([]Conn, *[]string) {
+	var lock sync.Mutex
+	var queries []string
+	conns := make([]Conn, n)
+	for i := range conns {
+		conns[i] = shardConn{index: i, lock: &lock, queries: &queries}
+	}
+
+	return conns, &queries
+}
+
+func TestNewShardedConn(t *testing.T) {
+	_, err := NewShardedConn(nil)
+	assert.Equal(t, ErrNoShards, err)
+
+	conns, _ := newShardConns(4)
+	_, err = NewShardedConn(conns, WithShardTables(2))
+	assert.Equal(t, ErrInvalidShardTables, err)
+}
+
+func TestModShard(t *testing.T) {
+	tests := []struct {
+		key    any
+		expect int
+	}{
+		{key: 7, expect: 3},
+		{key: int64(-1), expect: 3},
+		{key: uint8(2), expect: 2},
+		{key: uint64(math.MaxUint64), expect: 3},
+	}
+	for _, test := range tests {
+		index, err := ModShard(test.key, 4)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expect, index)
+	}
+
+	a, err := ModShard("user-1", 4)
+	assert.Nil(t, err)
+	b, err := ModShard([]byte("user-1"), 4)
+	assert.Nil(t, err)
+	assert.Equal(t, a, b)
+}
+
+func TestRangeShard(t *testing.T) {
+	fn := RangeShard(100, 200)
+	index, err := fn(99, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, index)
+	index, err = fn(int64(100), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, index)
+	_, err = fn(200, 2)
+	assert.True(t, errors.Is(err, ErrShardNotFound))
+	_, err = fn(uint64(math.MaxInt64)+1, 2)
+	assert.True(t, errors.Is(err, ErrShardNotFound))
+	_, err = fn("100", 2)
+	assert.Equal(t, ErrInvalidShardKey, err)
+	_, err = fn(1, 3)
+	assert.NotNil(t, err)
+}
+
+func TestConsistentHashShard(t *testing.T) {
+	fn := ConsistentHashShard()
+	for i := 0; i < 100; i++ {
+		a, err := fn(i, 4)
+		assert.Nil(t, err)
+		assert.True(t, a >= 0 && a < 4)
+		b, err := fn(i, 4)
+		assert.Nil(t, err)
+		assert.Equal(t, a, b)
+	}
+}
+
+func TestShardedConnRoute(t *testing.T) {
+	conns, _ := newShardConns(4)
+	conn, err := NewShardedConn(conns, WithShardTables(16))
+	assert.Nil(t, err)
+
+	c, table, err := conn.Route(7, "order")
+	assert.Nil(t, err)
+	assert.Equal(t, "order_07", table)
+	assert.Equal(t, 1, c.(shardConn).index)
+
+	c, err = conn.Shard(15)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, c.(shardConn).index)
+
+	table, err = conn.Table(3, "order")
+	assert.Nil(t, err)
+	assert.Equal(t, "order_03", table)
+
+	conn, err = NewShardedConn(conns, WithShardTables(8), WithShardTableFormat("%s_%d"))
+	assert.Nil(t, err)
+	table, err = conn.Table(5, "order")
+	assert.Nil(t, err)
+	assert.Equal(t, "order_5", table)
+
+	conn, err = NewShardedConn(conns, WithShardFunc(func(key any, n int) (int, error) {
+		return n, nil
+	}))
+	assert.Nil(t, err)
+	_, err = conn.Shard(1)
+	assert.True(t, errors.Is(err, ErrShardNotFound))
+}
+
+func TestShardedConnTransact(t *testing.T) {
+	conns, queries := newShardConns(2)
+	conn, err := NewShardedConn(conns, WithShardTables(4))
+	assert.Nil(t, err)
+
+	var called bool
+	assert.Nil(t, conn.Transact([]any{0, 1}, func(session Session) error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+	assert.Equal(t, []string{"0:transact"}, *queries)
+
+	assert.Equal(t, ErrCrossShardTransaction, conn.Transact([]any{1, 2}, func(session Session) error {
+		return nil
+	}))
+	assert.Equal(t, ErrShardNotFound, conn.Transact(nil, func(session Session) error {
+		return nil
+	}))
+}
+
+func TestShardedConnQueryRowsAll(t *testing.T) {
+	conns, queries := newShardConns(2)
+	conn, err := NewShardedConn(conns, WithShardTables(4))
+	assert.Nil(t, err)
+
+	var result []int
+	assert.Nil(t, conn.QueryRowsAll(&result, "order", "select id from {table} where name like '%a'"))
+	assert.Equal(t, []int{0, 0, 1, 1}, result)
+	assert.ElementsMatch(t, []string{
+		"0:select id from order_00 where name like '%a'",
+		"0:select id from order_01 where name like '%a'",
+		"1:select id from order_02 where name like '%a'",
+		"1:select id from order_03 where name like '%a'",
+	}, *queries)
+
+	conn, err = NewShardedConn(conns)
+	assert.Nil(t, err)
+	result = nil
+	assert.Nil(t, conn.QueryRowsAll(&result, "", "select id from order"))
+	assert.Equal(t, []int{0, 1}, result)
+
+	// 未启用分表时占位符替换为表名本身。
+	*queries = nil
+	result = nil
+	assert.Nil(t, conn.QueryRowsAll(&result, "order", "select id from {table}"))
+	assert.Equal(t, []int{0, 1}, result)
+	assert.ElementsMatch(t, []string{
+		"0:select id from order",
+		"1:select id from order",
+	}, *queries)
+
+	assert.Equal(t, ErrNotSettable, conn.QueryRowsAll(result, "", "select id from order"))
+}
+
+func TestShardedConnScatterError(t *testing.T) {
+	errDummy := errors.New("dummy")
+	conns, queries := newShardConns(2)
+	broken := conns[1].(shardConn)
+	broken.err = errDummy
+	conns[1] = broken
+	conn, err := NewShardedConn(conns)
+	assert.Nil(t, err)
+
+	var result []int
+	assert.Equal(t, errDummy, conn.QueryRowsAll(&result, "", "select id from order"))
+	assert.Nil(t, result)
+	assert.Len(t, *queries, 2)
+}
@@ -0,0 +1,324 @@
+package sqlxtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"github.com/gotid/god/lib/store/sqlx"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	execKind = iota
+	queryKind
+)
+
+type (
+	// Statement 是一条已执行的语句，Args 为驱动层的值，如 int 会被转为 int64。
+	Statement struct {
+		Query string
+		Args  []any
+		InTx  bool
+	}
+
+	// Conn 是一个用于单元测试的假 sqlx.Conn，无需任何数据库。
+	// 它记录所有执行的语句，并按脚本返回结果，查询结果经由真实的 sqlx 解编组逻辑写入目标变量。
+	// 未匹配任何脚本的执行语句影响 0 行，未匹配任何脚本的查询返回空结果集。
+	Conn struct {
+		sqlx.Conn
+		db         *sql.DB
+		lock       sync.Mutex
+		scripts    []*Script
+		statements []Statement
+		begins     int
+		commits    int
+		rollbacks  int
+		beginErr   error
+		commitErr  error
+	}
+
+	// Script 是一条语句的脚本，按子串匹配语句（忽略大小写及多余空白）。
+	Script struct {
+		kind    int
+		pattern string
+		rows    *Rows
+		result  fakeResult
+		err     error
+		times   int
+		used    int
+	}
+)
+
+// NewConn 返回一个假的 sqlx.Conn，可传入钩子等 sqlx.Option，使用完毕须调用 Close。
+func NewConn(opts ...sqlx.Option) *Conn {
+	c := new(Conn)
+	c.db = sql.OpenDB(fakeConnector{conn: c})
+	c.Conn = sqlx.NewConnFromDB(c.db, opts...)
+	return c
+}
+
+// Close 关闭底层的假 sql.DB。
+func (c *Conn) Close() error {
+	return c.db.Close()
+}
+
+// OnExec 为匹配 pattern 的执行语句添加脚本。
+func (c *Conn) OnExec(pattern string) *Script {
+	return c.addScript(execKind, pattern)
+}
+
+// OnQuery 为匹配 pattern 的查询语句添加脚本。
+func (c *Conn) OnQuery(pattern string) *Script {
+	return c.addScript(queryKind, pattern)
+}
+
+// FailBegin 使后续开启事务返回给定错误，传入 nil 则恢复。
+func (c *Conn) FailBegin(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.beginErr = err
+}
+
+// FailCommit 使后续提交事务返回给定错误，传入 nil 则恢复。
+func (c *Conn) FailCommit(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.commitErr = err
+}
+
+// Statements 返回已执行的全部语句。
+func (c *Conn) Statements() []Statement {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]Statement(nil), c.statements...)
+}
+
+// Begins 返回开启事务的次数。
+func (c *Conn) Begins() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.begins
+}
+
+// Commits 返回提交事务的次数。
+func (c *Conn) Commits() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.commits
+}
+
+// Rollbacks 返回回滚事务的次数。
+func (c *Conn) Rollbacks() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.rollbacks
+}
+
+// Reset 清空脚本、语句记录、事务计数及模拟的错误。
+func (c *Conn) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.scripts = nil
+	c.statements = nil
+	c.begins = 0
+	c.commits = 0
+	c.rollbacks = 0
+	c.beginErr = nil
+	c.commitErr = nil
+}
+
+// AssertExecuted 断言执行过匹配 pattern 的语句，若给定 args 则参数也须一致。
+func (c *Conn) AssertExecuted(t testing.TB, pattern string, args ...any) bool {
+	t.Helper()
+
+	expect := make([]any, len(args))
+	for i, arg := range args {
+		expect[i] = mustConvert(arg)
+	}
+
+	for _, stmt := range c.Statements() {
+		if !match(stmt.Query, pattern) {
+			continue
+		}
+		if len(args) == 0 || reflect.DeepEqual(expect, stmt.Args) {
+			return true
+		}
+	}
+
+	t.Errorf("sqlxtest: 未执行过匹配 %q 且参数为 %v 的语句，已执行：%v", pattern, args, c.Statements())
+	return false
+}
+
+// AssertNotExecuted 断言未执行过匹配 pattern 的语句。
+func (c *Conn) AssertNotExecuted(t testing.TB, pattern string) bool {
+	t.Helper()
+
+	for _, stmt := range c.Statements() {
+		if match(stmt.Query, pattern) {
+			t.Errorf("sqlxtest: 不应执行匹配 %q 的语句，却执行了：%s", pattern, stmt.Query)
+			return false
+		}
+	}
+
+	return true
+}
+
+// AssertCommitted 断言提交过事务。
+func (c *Conn) AssertCommitted(t testing.TB) bool {
+	t.Helper()
+
+	if c.Commits() == 0 {
+		t.Errorf("sqlxtest: 未提交过事务")
+		return false
+	}
+
+	return true
+}
+
+// AssertRolledBack 断言回滚过事务。
+func (c *Conn) AssertRolledBack(t testing.TB) bool {
+	t.Helper()
+
+	if c.Rollbacks() == 0 {
+		t.Errorf("sqlxtest: 未回滚过事务")
+		return false
+	}
+
+	return true
+}
+
+// WillReturnRows 设置查询返回的结果集。
+func (s *Script) WillReturnRows(rows *Rows) *Script {
+	s.rows = rows
+	return s
+}
+
+// WillReturnResult 设置执行语句返回的自增 ID 及影响行数。
+func (s *Script) WillReturnResult(lastInsertId, rowsAffected int64) *Script {
+	s.result = fakeResult{
+		lastInsertId: lastInsertId,
+		rowsAffected: rowsAffected,
+	}
+	return s
+}
+
+// WillReturnError 设置语句返回的错误。
+func (s *Script) WillReturnError(err error) *Script {
+	s.err = err
+	return s
+}
+
+// Times 设置脚本可被匹配的次数，默认不限次数。
+func (s *Script) Times(n int) *Script {
+	s.times = n
+	return s
+}
+
+func (c *Conn) addScript(kind int, pattern string) *Script {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s := &Script{
+		kind:    kind,
+		pattern: pattern,
+	}
+	c.scripts = append(c.scripts, s)
+
+	return s
+}
+
+func (c *Conn) begin() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.beginErr != nil {
+		return c.beginErr
+	}
+
+	c.begins++
+	return nil
+}
+
+func (c *Conn) commit() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.commitErr != nil {
+		c.rollbacks++
+		return c.commitErr
+	}
+
+	c.commits++
+	return nil
+}
+
+func (c *Conn) rollback() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.rollbacks++
+	return nil
+}
+
+func (c *Conn) exec(query string, args []driver.Value, inTx bool) (driver.Result, error) {
+	s := c.record(execKind, query, args, inTx)
+	if s == nil {
+		return fakeResult{}, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.result, nil
+}
+
+func (c *Conn) query(query string, args []driver.Value, inTx bool) (driver.Rows, error) {
+	s := c.record(queryKind, query, args, inTx)
+	if s == nil || s.rows == nil && s.err == nil {
+		return &rowsIterator{rows: NewRows()}, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &rowsIterator{rows: s.rows}, nil
+}
+
+// 记录语句并返回第一个匹配且未用尽的脚本。
+func (c *Conn) record(kind int, query string, args []driver.Value, inTx bool) *Script {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stmtArgs := make([]any, len(args))
+	for i, arg := range args {
+		stmtArgs[i] = arg
+	}
+	c.statements = append(c.statements, Statement{
+		Query: query,
+		Args:  stmtArgs,
+		InTx:  inTx,
+	})
+
+	for _, s := range c.scripts {
+		if s.kind != kind || !match(query, s.pattern) {
+			continue
+		}
+		if s.times > 0 && s.used >= s.times {
+			continue
+		}
+
+		s.used++
+		return s
+	}
+
+	return nil
+}
+
+func match(query, pattern string) bool {
+	return strings.Contains(normalize(query), normalize(pattern))
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,203 @@
+package sqlxtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gotid/god/lib/store/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type user struct {
+	Id   int64          `db:"id"`
+	Name string         `db:"name"`
+	Age  sql.NullInt64  `db:"age"`
+	Memo sql.NullString `db:"memo"`
+}
+
+func TestConnQueryRows(t *testing.T) {
+	conn := NewConn()
+	defer conn.Close()
+	conn.OnQuery("SELECT * FROM users").WillReturnRows(RowsFromTable(`
+		id | name  | age  | memo
+		1  | kevin | 18   | NULL
+		2  | lisa  | NULL | vip
+	`))
+
+	var users []user
+	assert.Nil(t, conn.QueryRows(&users, "select *  from users where age > ?", 10))
+	assert.Equal(t, []user{
+		{Id: 1, Name: "kevin", Age: sql.NullInt64{Int64: 18, Valid: true}},
+		{Id: 2, Name: "lisa", Memo: sql.NullString{String: "vip", Valid: true}},
+	}, users)
+	conn.AssertExecuted(t, "from users where age", 10)
+	conn.AssertNotExecuted(t, "delete")
+}
+
+func TestConnQueryRow(t *testing.T) {
+	conn := NewConn()
+	defer conn.Close()
+	conn.OnQuery("from users where id").WillReturnRows(RowsFromStructs([]user{
+		{Id: 1, Name: "kevin"},
+	}))
+
+	var u user
+	assert.Nil(t, conn.QueryRow(&u, "select * from users where id = ?", 1))
+	assert.Equal(t, user{Id: 1, Name: "kevin"}, u)
+
+	var name string
+	assert.Equal(t, sqlx.ErrNotFound, conn.QueryRow(&name, "select name from roles where id = ?", 1))
+}
+
+func TestConnScriptTimes(t *testing.T) {
+	conn := NewConn()
+	defer conn.Close()
+	conn.OnQuery("select count").Times(1).WillReturnRows(NewRows("count").AddRow(1))
+	conn.OnQuery("select count").WillReturnRows(NewRows("count").AddRow(2))
+
+	var count int
+	assert.Nil(t, conn.QueryRow(&count, "select count(*) from users"))
+	assert.Equal(t, 1, count)
+	assert.Nil(t, conn.QueryRow(&count, "select count(*) from users"))
+	assert.Equal(t, 2, count)
+	assert.Nil(t, conn.QueryRow(&count, "select count(*) from users"))
+	assert.Equal(t, 2, count)
+}
+
+func TestConnExec(t *testing.T) {
+	errDuplicate := errors.New("duplicate")
+	conn := NewConn()
+	defer conn.Close()
+	conn.OnExec("insert into users").WillReturnResult(3, 1)
+	conn.OnExec("insert into roles").WillReturnError(errDuplicate)
+
+	result, err := conn.Exec("insert into users (name) values (?)", "kevin")
+	assert.Nil(t, err)
+	id, err := result.LastInsertId()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, id)
+	affected, err := result.RowsAffected()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, affected)
+
+	_, err = conn.Exec("insert into roles (name) values (?)", "admin")
+	assert.Equal(t, errDuplicate, err)
+
+	result, err = conn.Exec("update users set name = ?", "lisa")
+	assert.Nil(t, err)
+	affected, err = result.RowsAffected()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, affected)
+
+	conn.AssertExecuted(t, "insert into users", "kevin")
+	assert.Len(t, conn.Statements(), 3)
+
+	conn.Reset()
+	assert.Empty(t, conn.Statements())
+}
+
+func TestConnPrepare(t *testing.T) {
+	conn := NewConn()
+	defer conn.Close()
+	conn.OnQuery("select name").WillReturnRows(NewRows("name").AddRow("kevin"))
+
+	stmt, err := conn.Prepare("select name from users where id = ?")
+	assert.Nil(t, err)
+	defer stmt.Close()
+
+	var name string
+	assert.Nil(t, stmt.QueryRow(&name, 1))
+	assert.Equal(t, "kevin", name)
+	conn.AssertExecuted(t, "select name from users", 1)
+}
+
+func TestConnTransact(t *testing.T) {
+	conn := NewConn()
+	defer conn.Close()
+	assert.Nil(t, conn.TransactCtx(context.Background(), func(ctx context.Context, session sqlx.Session) error {
+		_, err := session.ExecCtx(ctx, "insert into users (name) values (?)", "kevin")
+		return err
+	}))
+	conn.AssertCommitted(t)
+	assert.True(t, conn.Statements()[0].InTx)
+
+	errRollback := errors.New("rollback")
+	assert.Equal(t, errRollback, conn.Transact(func(session sqlx.Session) error {
+		return errRollback
+	}))
+	conn.AssertRolledBack(t)
+	assert.Equal(t, 2, conn.Begins())
+
+	_, err := conn.Exec("delete from users")
+	assert.Nil(t, err)
+	assert.False(t, conn.Statements()[1].InTx)
+}
+
+func TestConnTransactFailure(t *testing.T) {
+	errBegin := errors.New("begin")
+	errCommit := errors.New("commit")
+	conn := NewConn()
+	defer conn.Close()
+
+	conn.FailBegin(errBegin)
+	assert.Equal(t, errBegin, conn.Transact(func(session sqlx.Session) error {
+		return nil
+	}))
+	conn.FailBegin(nil)
+
+	conn.FailCommit(errCommit)
+	assert.Equal(t, errCommit, conn.Transact(func(session sqlx.Session) error {
+		return nil
+	}))
+	assert.Equal(t, 0, conn.Commits())
+	assert.Equal(t, 1, conn.Rollbacks())
+}
+
+func TestConnAssertFailure(t *testing.T) {
+	conn := NewConn()
+	defer conn.Close()
+	_, err := conn.Exec("delete from users where id = ?", 1)
+	assert.Nil(t, err)
+
+	var mt mockedT
+	assert.False(t, conn.AssertExecuted(&mt, "delete from users", 2))
+	assert.False(t, conn.AssertNotExecuted(&mt, "delete"))
+	assert.False(t, conn.AssertCommitted(&mt))
+	assert.False(t, conn.AssertRolledBack(&mt))
+	assert.Equal(t, 4, mt.errors)
+}
+
+func TestRowsPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRows("id").AddRow(1, 2)
+	})
+	assert.Panics(t, func() {
+		RowsFromStructs(user{})
+	})
+	assert.Panics(t, func() {
+		RowsFromStructs([]int{1})
+	})
+	assert.Panics(t, func() {
+		RowsFromTable("")
+	})
+}
+
+func TestConnClose(t *testing.T) {
+	conn := NewConn()
+	assert.Nil(t, conn.Close())
+	_, err := conn.Exec("delete from users")
+	assert.NotNil(t, err)
+}
+
+type mockedT struct {
+	testing.TB
+	errors int
+}
+
+func (t *mockedT) Helper() {
+}
+
+func (t *mockedT) Errorf(_ string, _ ...any) {
+	t.errors++
+}
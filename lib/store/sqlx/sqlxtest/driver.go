@@ -0,0 +1,131 @@
+package sqlxtest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+var errNestedTx = errors.New("sqlxtest: 不支持在事务中再次开启事务")
+
+type (
+	// 实现 driver.Connector，使 sql.OpenDB 无需注册驱动。
+	fakeConnector struct {
+		conn *Conn
+	}
+
+	fakeDriver struct {
+		conn *Conn
+	}
+
+	// 驱动层连接，事务状态随连接保存。
+	fakeDriverConn struct {
+		conn *Conn
+		inTx bool
+	}
+
+	fakeTx struct {
+		dc *fakeDriverConn
+	}
+
+	fakeStmt struct {
+		dc    *fakeDriverConn
+		query string
+	}
+
+	fakeResult struct {
+		lastInsertId int64
+		rowsAffected int64
+	}
+)
+
+func (c fakeConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return &fakeDriverConn{conn: c.conn}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{conn: c.conn}
+}
+
+func (d fakeDriver) Open(_ string) (driver.Conn, error) {
+	return &fakeDriverConn{conn: d.conn}, nil
+}
+
+func (dc *fakeDriverConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{
+		dc:    dc,
+		query: query,
+	}, nil
+}
+
+func (dc *fakeDriverConn) Close() error {
+	return nil
+}
+
+func (dc *fakeDriverConn) Begin() (driver.Tx, error) {
+	if dc.inTx {
+		return nil, errNestedTx
+	}
+
+	if err := dc.conn.begin(); err != nil {
+		return nil, err
+	}
+
+	dc.inTx = true
+	return fakeTx{dc: dc}, nil
+}
+
+func (dc *fakeDriverConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return dc.conn.exec(query, namedValues(args), dc.inTx)
+}
+
+func (dc *fakeDriverConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return dc.conn.query(query, namedValues(args), dc.inTx)
+}
+
+func (tx fakeTx) Commit() error {
+	tx.dc.inTx = false
+	return tx.dc.conn.commit()
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.dc.inTx = false
+	return tx.dc.conn.rollback()
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.dc.conn.exec(s.query, args, s.dc.inTx)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.dc.conn.query(s.query, args, s.dc.inTx)
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.lastInsertId, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	if len(args) == 0 {
+		return nil
+	}
+
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	return values
+}
@@ -0,0 +1,165 @@
+package sqlxtest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	dbTag     = "db"
+	nullValue = "NULL"
+)
+
+// Rows 是脚本化的查询结果。
+type Rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// NewRows 返回具有给定列的空结果集。
+func NewRows(columns ...string) *Rows {
+	return &Rows{
+		columns: columns,
+	}
+}
+
+// AddRow 添加一行，值的个数须与列数一致。
+func (r *Rows) AddRow(values ...any) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Errorf("sqlxtest: 结果集有 %d 列，却添加了 %d 个值", len(r.columns), len(values)))
+	}
+
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		row[i] = mustConvert(v)
+	}
+	r.values = append(r.values, row)
+
+	return r
+}
+
+// RowsFromStructs 以结构体（或其指针）的切片构建结果集，列名取自 db 标签，无标签则取字段名。
+func RowsFromStructs(slice any) *Rows {
+	rv := reflect.Indirect(reflect.ValueOf(slice))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		panic(fmt.Errorf("sqlxtest: RowsFromStructs 只接收切片，却收到 %T", slice))
+	}
+
+	et := rv.Type().Elem()
+	for et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		panic(fmt.Errorf("sqlxtest: RowsFromStructs 只接收结构体切片，却收到 %T", slice))
+	}
+
+	var columns []string
+	var indexes []int
+	for i := 0; i < et.NumField(); i++ {
+		field := et.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.TrimSpace(strings.Split(field.Tag.Get(dbTag), ",")[0])
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		columns = append(columns, name)
+		indexes = append(indexes, i)
+	}
+
+	rows := NewRows(columns...)
+	for i := 0; i < rv.Len(); i++ {
+		item := reflect.Indirect(rv.Index(i))
+		values := make([]any, len(indexes))
+		for j, index := range indexes {
+			values[j] = item.Field(index).Interface()
+		}
+		rows.AddRow(values...)
+	}
+
+	return rows
+}
+
+// RowsFromTable 以类 CSV 的文本表格构建结果集，首行为列名，列之间以 | 分隔，
+// 每个值两侧的空白将被去除，NULL 代表空值，如：
+//
+//	id | name  | age
+//	1  | kevin | 18
+//	2  | lisa  | NULL
+func RowsFromTable(table string) *Rows {
+	var rows *Rows
+	for _, line := range strings.Split(table, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		cells := strings.Split(line, "|")
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+
+		if rows == nil {
+			rows = NewRows(cells...)
+			continue
+		}
+
+		values := make([]any, len(cells))
+		for i, cell := range cells {
+			if cell == nullValue {
+				values[i] = nil
+			} else {
+				values[i] = cell
+			}
+		}
+		rows.AddRow(values...)
+	}
+
+	if rows == nil {
+		panic("sqlxtest: RowsFromTable 需要至少一行列名")
+	}
+
+	return rows
+}
+
+func mustConvert(v any) driver.Value {
+	val, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		panic(fmt.Errorf("sqlxtest: 无法转换值 %v：%w", v, err))
+	}
+
+	return val
+}
+
+// 驱动层的结果集迭代器。
+type rowsIterator struct {
+	rows *Rows
+	pos  int
+}
+
+func (it *rowsIterator) Columns() []string {
+	return it.rows.columns
+}
+
+func (it *rowsIterator) Close() error {
+	return nil
+}
+
+func (it *rowsIterator) Next(dest []driver.Value) error {
+	if it.pos >= len(it.rows.values) {
+		return io.EOF
+	}
+
+	copy(dest, it.rows.values[it.pos])
+	it.pos++
+
+	return nil
+}
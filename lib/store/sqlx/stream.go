@@ -0,0 +1,39 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStreamUnsupported 表示会话未实现流式查询。
+var ErrStreamUnsupported = errors.New("会话不支持流式查询")
+
+// QueryRowsStream 流式查询给定语句，逐行解编组为 T 并交由 fn 处理。
+// 不会将整个结果集载入内存，适用于导出及批处理等大结果集场景。
+// fn 返回错误时中止查询并返回该错误，session 未实现 StreamSession 时返回 ErrStreamUnsupported。
+func QueryRowsStream[T any](ctx context.Context, session Session, fn func(row T) error,
+	query string, args ...any) error {
+	ss, ok := session.(StreamSession)
+	if !ok {
+		return ErrStreamUnsupported
+	}
+
+	var row T
+	return ss.QueryRowsStreamCtx(ctx, &row, func() error {
+		return fn(row)
+	}, query, args...)
+}
+
+// QueryStmtRowsStream 流式执行给定预编译语句，逐行解编组为 T 并交由 fn 处理。
+// stmt 未实现 StmtStreamSession 时返回 ErrStreamUnsupported。
+func QueryStmtRowsStream[T any](ctx context.Context, stmt StmtSession, fn func(row T) error, args ...any) error {
+	ss, ok := stmt.(StmtStreamSession)
+	if !ok {
+		return ErrStreamUnsupported
+	}
+
+	var row T
+	return ss.QueryRowsStreamCtx(ctx, &row, func() error {
+		return fn(row)
+	}, args...)
+}
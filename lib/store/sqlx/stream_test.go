@@ -0,0 +1,119 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQueryRowsStream(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		rs := sqlmock.NewRows([]string{"name", "age"}).FromCSVString("liao,5\nanyone,6")
+		mock.ExpectQuery("select (.+) from users where user=?").WithArgs("anyone").WillReturnRows(rs)
+
+		type user struct {
+			Name string `db:"name"`
+			Age  int    `db:"age"`
+		}
+		var users []user
+		conn := NewConnFromDB(db)
+		assert.Nil(t, QueryRowsStream(context.Background(), conn, func(u user) error {
+			users = append(users, u)
+			return nil
+		}, "select name, age from users where user=?", "anyone"))
+		assert.Equal(t, []user{{Name: "liao", Age: 5}, {Name: "anyone", Age: 6}}, users)
+	})
+}
+
+func TestQueryRowsStreamPointerFields(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		rs := sqlmock.NewRows([]string{"name"}).FromCSVString("liao\nanyone")
+		mock.ExpectQuery("select (.+) from users").WillReturnRows(rs)
+
+		type user struct {
+			Name *string `db:"name"`
+		}
+		var names []*string
+		conn := NewConnFromDB(db)
+		assert.Nil(t, QueryRowsStream(context.Background(), conn, func(u user) error {
+			names = append(names, u.Name)
+			return nil
+		}, "select name from users"))
+		assert.Equal(t, "liao", *names[0])
+		assert.Equal(t, "anyone", *names[1])
+	})
+}
+
+func TestQueryRowsStreamHandlerError(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		rs := sqlmock.NewRows([]string{"value"}).FromCSVString("1\n2\n3")
+		mock.ExpectQuery("select (.+) from users").WillReturnRows(rs)
+
+		errStop := errors.New("stop")
+		var values []int
+		conn := NewConnFromDB(db)
+		err := QueryRowsStream(context.Background(), conn, func(v int) error {
+			values = append(values, v)
+			if v == 2 {
+				return errStop
+			}
+			return nil
+		}, "select value from users")
+		assert.Equal(t, errStop, err)
+		assert.Equal(t, []int{1, 2}, values)
+	})
+}
+
+func TestQueryRowsStreamCanceled(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		rs := sqlmock.NewRows([]string{"value"}).FromCSVString("1\n2\n3")
+		mock.ExpectQuery("select (.+) from users").WillReturnRows(rs)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var value int
+		err := query(ctx, db, func(rows *sql.Rows) error {
+			return unmarshalRowsStream(ctx, &value, rows, true, func() error {
+				cancel()
+				return nil
+			})
+		}, "select value from users")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, value)
+	})
+}
+
+func TestQueryRowsStreamTxAndStmt(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("select (.+) from users").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).FromCSVString("1\n2"))
+		mock.ExpectPrepare("select (.+) from users").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).FromCSVString("3"))
+		mock.ExpectCommit()
+
+		var values []int
+		conn := NewConnFromDB(db)
+		assert.Nil(t, conn.TransactCtx(context.Background(), func(ctx context.Context, session Session) error {
+			if err := QueryRowsStream(ctx, session, func(v int) error {
+				values = append(values, v)
+				return nil
+			}, "select value from users"); err != nil {
+				return err
+			}
+
+			stmt, err := session.PrepareCtx(ctx, "select value from users")
+			if err != nil {
+				return err
+			}
+
+			return QueryStmtRowsStream(ctx, stmt, func(v int) error {
+				values = append(values, v)
+				return nil
+			})
+		}))
+		assert.Equal(t, []int{1, 2, 3}, values)
+	})
+}
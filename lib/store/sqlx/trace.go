@@ -11,10 +11,18 @@ import (
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-// 用于标识 SQL 执行的跨度名称。
-const spanName = "sql"
+const (
+	// 用于标识 SQL 执行的跨度名称。
+	spanName = "sql"
+	// 用于标识事务重试的跨度事件名称。
+	retryEventName = "retry"
+)
 
-var sqlAttributeKey = attribute.Key("sql.method")
+var (
+	sqlAttributeKey    = attribute.Key("sql.method")
+	sqlRetryAttemptKey = attribute.Key("sql.retry.attempt")
+	sqlRetryErrorKey   = attribute.Key("sql.retry.error")
+)
 
 func startSpan(ctx context.Context, method string) (context.Context, oteltrace.Span) {
 	tracer := otel.GetTracerProvider().Tracer(trace.Name)
@@ -35,3 +43,11 @@ func endSpan(span oteltrace.Span, err error) {
 	span.SetStatus(codes.Error, err.Error())
 	span.RecordError(err)
 }
+
+// addRetryEvent 记录第 attempt 次尝试失败后的重试事件。
+func addRetryEvent(span oteltrace.Span, attempt int, err error) {
+	span.AddEvent(retryEventName, oteltrace.WithAttributes(
+		sqlRetryAttemptKey.Int(attempt),
+		sqlRetryErrorKey.String(err.Error()),
+	))
+}
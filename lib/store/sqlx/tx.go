@@ -3,7 +3,10 @@ package sqlx
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/gotid/god/lib/logx"
+	"sync/atomic"
 )
 
 type (
@@ -17,12 +20,39 @@ type (
 
 	txSession struct {
 		*sql.Tx
+		postgres bool
+		// 同一事务内已创建的 SAVEPOINT 数，由嵌套会话共享，用于生成不重名的 SAVEPOINT
+		savepoints *uint64
+		hooks      hookChain
 	}
 )
 
+// ErrTransactUnsupported 表示会话未实现 Transactor，不能运行事务。
+var ErrTransactUnsupported = errors.New("会话不支持事务")
+
 // NewSessionFromTx 返回给定事务 tx 对应的会话 Session。
 func NewSessionFromTx(tx *sql.Tx) Session {
-	return txSession{Tx: tx}
+	return newTxSession(tx, false, nil)
+}
+
+// TransactCtx 以事务运行 fn：session 为 Conn 时开启新事务，为事务会话时基于 SAVEPOINT 嵌套，
+// 以便同一函数既可独立运行，也可复用于更大的事务中。session 未实现 Transactor 时返回 ErrTransactUnsupported。
+func TransactCtx(ctx context.Context, session Session, fn func(context.Context, Session) error) error {
+	t, ok := session.(Transactor)
+	if !ok {
+		return ErrTransactUnsupported
+	}
+
+	return t.TransactCtx(ctx, fn)
+}
+
+func newTxSession(tx *sql.Tx, postgres bool, hooks hookChain) txSession {
+	return txSession{
+		Tx:         tx,
+		postgres:   postgres,
+		savepoints: new(uint64),
+		hooks:      hooks,
+	}
 }
 
 func (t txSession) Exec(query string, args ...any) (sql.Result, error) {
@@ -35,11 +65,29 @@ func (t txSession) ExecCtx(ctx context.Context, query string, args ...any) (resu
 		endSpan(span, err)
 	}()
 
-	result, err = exec(ctx, t.Tx, query, args...)
+	err = t.hooks.run(ctx, newHookInfo(HookExec, query, args), func(ctx context.Context, info *HookInfo) error {
+		var e error
+		result, e = exec(ctx, t.Tx, info.Query, info.Args...)
+		info.setResult(result)
+		return e
+	})
 
 	return
 }
 
+func (t txSession) ExecNamed(query string, arg any) (sql.Result, error) {
+	return t.ExecNamedCtx(context.Background(), query, arg)
+}
+
+func (t txSession) ExecNamedCtx(ctx context.Context, query string, arg any) (sql.Result, error) {
+	q, args, err := bindNamed(query, arg, t.postgres)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.ExecCtx(ctx, q, args...)
+}
+
 func (t txSession) Prepare(query string) (StmtSession, error) {
 	return t.PrepareCtx(context.Background(), query)
 }
@@ -58,6 +106,7 @@ func (t txSession) PrepareCtx(ctx context.Context, query string) (stmtSession St
 	return statement{
 		query: query,
 		stmt:  stmt,
+		hooks: t.hooks,
 	}, nil
 }
 
@@ -71,11 +120,24 @@ func (t txSession) QueryRowCtx(ctx context.Context, v any, q string, args ...any
 		endSpan(span, err)
 	}()
 
-	return query(ctx, t.Tx, func(rows *sql.Rows) error {
+	return t.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRow(v, rows, true)
 	}, q, args...)
 }
 
+func (t txSession) QueryRowNamed(v any, query string, arg any) error {
+	return t.QueryRowNamedCtx(context.Background(), v, query, arg)
+}
+
+func (t txSession) QueryRowNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	q, args, err := bindNamed(query, arg, t.postgres)
+	if err != nil {
+		return err
+	}
+
+	return t.QueryRowCtx(ctx, v, q, args...)
+}
+
 func (t txSession) QueryRowPartial(v any, query string, args ...any) error {
 	return t.QueryRowPartialCtx(context.Background(), v, query, args...)
 }
@@ -86,7 +148,7 @@ func (t txSession) QueryRowPartialCtx(ctx context.Context, v any, q string, args
 		endSpan(span, err)
 	}()
 
-	return query(ctx, t.Tx, func(rows *sql.Rows) error {
+	return t.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRow(v, rows, false)
 	}, q, args...)
 }
@@ -101,11 +163,24 @@ func (t txSession) QueryRowsCtx(ctx context.Context, v any, q string, args ...an
 		endSpan(span, err)
 	}()
 
-	return query(ctx, t.Tx, func(rows *sql.Rows) error {
+	return t.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRows(v, rows, true)
 	}, q, args...)
 }
 
+func (t txSession) QueryRowsNamed(v any, query string, arg any) error {
+	return t.QueryRowsNamedCtx(context.Background(), v, query, arg)
+}
+
+func (t txSession) QueryRowsNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	q, args, err := bindNamed(query, arg, t.postgres)
+	if err != nil {
+		return err
+	}
+
+	return t.QueryRowsCtx(ctx, v, q, args...)
+}
+
 func (t txSession) QueryRowsPartial(v any, query string, args ...any) error {
 	return t.QueryRowsPartialCtx(context.Background(), v, query, args...)
 }
@@ -116,20 +191,71 @@ func (t txSession) QueryRowsPartialCtx(ctx context.Context, v any, q string, arg
 		endSpan(span, err)
 	}()
 
-	return query(ctx, t.Tx, func(rows *sql.Rows) error {
+	return t.queryRows(ctx, func(rows *sql.Rows) error {
 		return unmarshalRows(v, rows, false)
 	}, q, args...)
 }
 
-func begin(db *sql.DB) (trans, error) {
-	tx, err := db.Begin()
-	if err != nil {
-		return nil, err
+func (t txSession) QueryRowsStream(v any, handle func() error, query string, args ...any) error {
+	return t.QueryRowsStreamCtx(context.Background(), v, handle, query, args...)
+}
+
+func (t txSession) QueryRowsStreamCtx(ctx context.Context, v any, handle func() error,
+	q string, args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRowsStream")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	return t.queryRows(ctx, func(rows *sql.Rows) error {
+		return unmarshalRowsStream(ctx, v, rows, true, handle)
+	}, q, args...)
+}
+
+func (t txSession) Transact(fn func(Session) error) error {
+	return t.TransactCtx(context.Background(), func(_ context.Context, session Session) error {
+		return fn(session)
+	})
+}
+
+// TransactCtx 在当前事务中创建 SAVEPOINT 并运行给定函数，
+// 函数出错时回滚至该 SAVEPOINT，成功时释放之，外层事务不受影响。
+// 函数 panic 时回滚至该 SAVEPOINT 后继续 panic。
+func (t txSession) TransactCtx(ctx context.Context, fn func(context.Context, Session) error) (err error) {
+	ctx, span := startSpan(ctx, "Transact")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	savepoint := fmt.Sprintf("god_sp_%d", atomic.AddUint64(t.savepoints, 1))
+	if _, err = t.ExecCtx(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
 	}
 
-	return txSession{
-		Tx: tx,
-	}, nil
+	defer func() {
+		if p := recover(); p != nil {
+			if _, e := t.ExecCtx(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); e != nil {
+				logx.WithContext(ctx).Errorf("嵌套事务异常，回滚至 %s 也失败了：%v", savepoint, e)
+			}
+			panic(p)
+		}
+
+		if err != nil {
+			if _, e := t.ExecCtx(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); e != nil {
+				err = fmt.Errorf("嵌套事务失败了：%w，回滚也失败了：%v", err, e)
+			}
+		} else {
+			_, err = t.ExecCtx(ctx, "RELEASE SAVEPOINT "+savepoint)
+		}
+	}()
+
+	return fn(ctx, t)
+}
+
+func (t txSession) queryRows(ctx context.Context, scanner func(*sql.Rows) error, q string, args ...any) error {
+	return t.hooks.run(ctx, newHookInfo(HookQuery, q, args), func(ctx context.Context, info *HookInfo) error {
+		return query(ctx, t.Tx, scanner, info.Query, info.Args...)
+	})
 }
 
 func transact(ctx context.Context, db *commonConn, b beginnable, fn func(context.Context, Session) error) (err error) {
@@ -150,11 +276,16 @@ func transactOnConn(ctx context.Context, conn *sql.DB, b beginnable, fn func(con
 	}
 
 	defer func() {
+		// 与嵌套事务一致，回滚后继续 panic。
 		if p := recover(); p != nil {
-
+			if e := tx.Rollback(); e != nil {
+				logx.WithContext(ctx).Errorf("事务异常，回滚也失败了：%v", e)
+			}
+			panic(p)
 		} else if err != nil {
 			if e := tx.Rollback(); e != nil {
-				err = fmt.Errorf("事务失败了：%s，回滚也失败了：%w", err, e)
+				// 保留原错误以便调用方及重试策略判断，回滚错误仅作为说明。
+				err = fmt.Errorf("事务失败了：%w，回滚也失败了：%v", err, e)
 			}
 		} else {
 			err = tx.Commit()
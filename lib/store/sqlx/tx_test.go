@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -30,6 +31,14 @@ func (mt *mockTx) ExecCtx(ctx context.Context, query string, args ...any) (sql.R
 	return nil, nil
 }
 
+func (mt *mockTx) ExecNamed(query string, arg any) (sql.Result, error) {
+	return nil, nil
+}
+
+func (mt *mockTx) ExecNamedCtx(ctx context.Context, query string, arg any) (sql.Result, error) {
+	return nil, nil
+}
+
 func (mt *mockTx) Prepare(query string) (StmtSession, error) {
 	return nil, nil
 }
@@ -46,6 +55,14 @@ func (mt *mockTx) QueryRowCtx(ctx context.Context, v any, query string, args ...
 	return nil
 }
 
+func (mt *mockTx) QueryRowNamed(v any, query string, arg any) error {
+	return nil
+}
+
+func (mt *mockTx) QueryRowNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	return nil
+}
+
 func (mt *mockTx) QueryRowPartial(v any, q string, args ...any) error {
 	return nil
 }
@@ -62,6 +79,14 @@ func (mt *mockTx) QueryRowsCtx(ctx context.Context, v any, query string, args ..
 	return nil
 }
 
+func (mt *mockTx) QueryRowsNamed(v any, query string, arg any) error {
+	return nil
+}
+
+func (mt *mockTx) QueryRowsNamedCtx(ctx context.Context, v any, query string, arg any) error {
+	return nil
+}
+
 func (mt *mockTx) QueryRowsPartial(v any, q string, args ...any) error {
 	return nil
 }
@@ -100,3 +125,81 @@ func TestTransactRollback(t *testing.T) {
 	assert.Equal(t, mockRollback, mock.status)
 	assert.NotNil(t, err)
 }
+
+func TestTransactPanic(t *testing.T) {
+	mock := &mockTx{}
+	assert.PanicsWithValue(t, "foo", func() {
+		_ = transactOnConn(context.Background(), nil, beginMock(mock),
+			func(context.Context, Session) error {
+				panic("foo")
+			})
+	})
+	assert.Equal(t, mockRollback, mock.status)
+}
+
+func TestNestedTransact(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into users").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("SAVEPOINT god_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into orders").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("SAVEPOINT god_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into logs").WillReturnError(errors.New("foo"))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT god_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT god_sp_3").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into logs").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("RELEASE SAVEPOINT god_sp_3").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT god_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		conn := NewConnFromDB(db)
+		insertLog := func(ctx context.Context, session Session) error {
+			_, err := session.ExecCtx(ctx, "insert into logs")
+			return err
+		}
+		assert.Nil(t, conn.TransactCtx(context.Background(), func(ctx context.Context, session Session) error {
+			if _, err := session.ExecCtx(ctx, "insert into users"); err != nil {
+				return err
+			}
+
+			return TransactCtx(ctx, session, func(ctx context.Context, session Session) error {
+				if _, err := session.ExecCtx(ctx, "insert into orders"); err != nil {
+					return err
+				}
+
+				// 内层失败只回滚至其 SAVEPOINT，同层的后续嵌套事务使用新的 SAVEPOINT
+				assert.NotNil(t, TransactCtx(ctx, session, insertLog))
+				return TransactCtx(ctx, session, insertLog)
+			})
+		}))
+	})
+}
+
+func TestNestedTransactPanic(t *testing.T) {
+	runOrmTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT god_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT god_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		conn := NewConnFromDB(db)
+		assert.PanicsWithValue(t, "bar", func() {
+			_ = conn.Transact(func(session Session) error {
+				// 回滚至 SAVEPOINT 后继续 panic，由外层事务回滚后继续 panic。
+				assert.PanicsWithValue(t, "foo", func() {
+					_ = session.(Transactor).Transact(func(session Session) error {
+						panic("foo")
+					})
+				})
+				panic("bar")
+			})
+		})
+	})
+}
+
+func TestTransactUnsupported(t *testing.T) {
+	assert.Equal(t, ErrTransactUnsupported, TransactCtx(context.Background(), &mockTx{},
+		func(context.Context, Session) error {
+			return nil
+		}))
+}
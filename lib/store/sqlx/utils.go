@@ -2,6 +2,7 @@ package sqlx
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"github.com/gotid/god/lib/logx"
@@ -11,6 +12,12 @@ import (
 	"time"
 )
 
+const (
+	postgresTimeLayout = "2006-01-02 15:04:05.999999999Z07:00"
+	// 敏感参数在 SQL 日志中的掩码。
+	sensitiveMask = "'******'"
+)
+
 var errUnbalancedEscape = errors.New("逃逸字符后面没有字符")
 
 func logInstanceError(dsn string, err error) {
@@ -35,8 +42,38 @@ func desensitize(dsn string) string {
 	return dsn
 }
 
-// 格式化查询语句。
+// sensitiveValue 是以 Sensitive 标记的敏感参数值。
+type sensitiveValue struct {
+	value any
+}
+
+// Sensitive 标记敏感的参数值（如密码、证件号），SQL 日志中以掩码代替，执行时仍使用原值。
+// 命名参数的结构体字段也可通过 db 标签的 sensitive 选项标记，如 `db:"password,sensitive"`。
+func Sensitive(v any) driver.Valuer {
+	return sensitiveValue{value: v}
+}
+
+// Value 返回原值，供数据库驱动使用。
+func (v sensitiveValue) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(v.value)
+}
+
+// 格式化查询语句，用于日志，敏感参数以掩码代替。
 func format(query string, args ...any) (string, error) {
+	return formatWith(writeValue, true, query, args...)
+}
+
+// 将参数值内插至查询语句，用于生成实际执行的语句。
+func interpolate(query string, args ...any) (string, error) {
+	return formatWith(writeValue, false, query, args...)
+}
+
+// 按 PostgreSQL 字面量规则将参数值内插至查询语句，用于生成实际执行的语句。
+func interpolatePostgres(query string, args ...any) (string, error) {
+	return formatWith(writePostgresValue, false, query, args...)
+}
+
+func formatWith(write func(*strings.Builder, any), mask bool, query string, args ...any) (string, error) {
 	numArgs := len(args)
 	if numArgs == 0 {
 		return query, nil
@@ -45,6 +82,16 @@ func format(query string, args ...any) (string, error) {
 	var b strings.Builder
 	var argIndex int
 	queryLength := len(query)
+	writeArg := func(arg any) {
+		if v, ok := arg.(sensitiveValue); ok {
+			if mask {
+				b.WriteString(sensitiveMask)
+				return
+			}
+			arg = v.value
+		}
+		write(&b, arg)
+	}
 
 	for i := 0; i < queryLength; i++ {
 		ch := query[i]
@@ -54,7 +101,7 @@ func format(query string, args ...any) (string, error) {
 				return "", fmt.Errorf("错误：SQL 中有 %d 个 ?，但只提供了 %d 个参数值", argIndex+1, numArgs)
 			}
 
-			writeValue(&b, args[argIndex])
+			writeArg(args[argIndex])
 			argIndex++
 		case ':', '$':
 			var j int
@@ -81,8 +128,11 @@ func format(query string, args ...any) (string, error) {
 					return "", fmt.Errorf("错误：索引 %d 越界", index)
 				}
 
-				writeValue(&b, args[index])
+				writeArg(args[index])
 				i = j - 1
+			} else {
+				// 非占位符，如 pg 的类型转换 `::int` 或 `$$` 字符串
+				b.WriteByte(ch)
 			}
 		case '\'', '"', '`':
 			b.WriteByte(ch)
@@ -140,6 +190,36 @@ func writeValue(b *strings.Builder, arg any) {
 	}
 }
 
+func writePostgresValue(b *strings.Builder, arg any) {
+	switch v := arg.(type) {
+	case bool:
+		if v {
+			b.WriteString("true")
+		} else {
+			b.WriteString("false")
+		}
+	case string:
+		b.WriteByte('\'')
+		b.WriteString(strings.ReplaceAll(v, "'", "''"))
+		b.WriteByte('\'')
+	case time.Time:
+		b.WriteByte('\'')
+		b.WriteString(v.Format(postgresTimeLayout))
+		b.WriteByte('\'')
+	case *time.Time:
+		if v == nil {
+			b.WriteString("NULL")
+			return
+		}
+
+		b.WriteByte('\'')
+		b.WriteString(v.Format(postgresTimeLayout))
+		b.WriteByte('\'')
+	default:
+		b.WriteString(mapping.Repr(v))
+	}
+}
+
 func escape(s string) string {
 	var b strings.Builder
 	for _, r := range s {
@@ -3,6 +3,7 @@ package sqlx
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestFormat(t *testing.T) {
@@ -49,6 +50,12 @@ func TestFormat(t *testing.T) {
 			args:   []any{"133", false},
 			hasErr: true,
 		},
+		{
+			name:   "pg type cast",
+			query:  "select name, age from users where id=$1::int and phone=$2",
+			args:   []any{1, "133"},
+			expect: "select name, age from users where id=1::int and phone='133'",
+		},
 		{
 			name:   "oracle normal",
 			query:  "select name, age from users where bool=:1 and phone=:2",
@@ -114,3 +121,31 @@ func TestFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatSensitive(t *testing.T) {
+	query := "update users set password=?, phone=? where id=?"
+	actual, err := format(query, Sensitive("secret"), Sensitive(nil), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "update users set password='******', phone='******' where id=1", actual)
+
+	actual, err = interpolate(query, Sensitive("secret"), "123", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "update users set password='secret', phone='123' where id=1", actual)
+
+	val, err := Sensitive(int8(1)).Value()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), val)
+}
+
+func TestInterpolatePostgres(t *testing.T) {
+	tm := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	actual, err := interpolatePostgres("insert into users values ($1, $2, $3, $4)", true, "it's", tm, &tm)
+	assert.Nil(t, err)
+	assert.Equal(t, "insert into users values (true, 'it''s', '2022-01-02 03:04:05Z', '2022-01-02 03:04:05Z')",
+		actual)
+
+	var empty *time.Time
+	actual, err = interpolatePostgres("update users set deleted_at = $1", empty)
+	assert.Nil(t, err)
+	assert.Equal(t, "update users set deleted_at = NULL", actual)
+}
@@ -10,6 +10,7 @@ import (
 	"text/template"
 
 	"github.com/gotid/god/tools/god/internal/version"
+	"github.com/gotid/god/tools/god/migrate"
 	"github.com/gotid/god/tools/god/model"
 	"github.com/gotid/god/tools/god/rpc"
 	"github.com/logrusorgru/aurora"
@@ -111,5 +112,6 @@ func init() {
 	rootCmd.AddCommand(rpc.Cmd)
 	rootCmd.AddCommand(model.Cmd)
 	rootCmd.AddCommand(api.Cmd)
+	rootCmd.AddCommand(migrate.Cmd)
 	//rootCmd.AddCommand(cobracompletefig.CreateCompletionSpecCommand())
 }
@@ -9,6 +9,7 @@ require (
 	github.com/gotid/antlr v0.0.0-20221103053916-29bff99c97c7
 	github.com/gotid/ddl-parser v0.0.0-20221029075150-b53a75dd4846
 	github.com/gotid/god v1.4.3
+	github.com/lib/pq v1.10.7
 	github.com/logrusorgru/aurora v2.0.3+incompatible
 	github.com/spf13/cobra v1.6.1
 	github.com/stretchr/testify v1.8.0
@@ -0,0 +1,57 @@
+package migrate
+
+import (
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var (
+	// Cmd 描述了一个数据库迁移命令。
+	Cmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "数据库迁移",
+	}
+
+	createCmd = &cobra.Command{
+		Use:   "create <name>",
+		Short: "创建迁移文件",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Create,
+	}
+
+	upCmd = &cobra.Command{
+		Use:   "up",
+		Short: "执行待执行的迁移",
+		RunE:  Up,
+	}
+
+	downCmd = &cobra.Command{
+		Use:   "down",
+		Short: "回滚最近执行的迁移",
+		RunE:  Down,
+	}
+
+	statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "查看迁移状态",
+		RunE:  Status,
+	}
+)
+
+func init() {
+	upCmd.Flags().Int64Var(&VarInt64Version, "version", 0, "仅执行不大于该版本的迁移[可选]")
+	upCmd.Flags().BoolVar(&VarBoolDryRun, "dry-run", false, "仅打印将要执行的语句[可选]")
+	downCmd.Flags().IntVar(&VarIntSteps, "steps", 1, "回滚的迁移数量")
+	downCmd.Flags().BoolVar(&VarBoolDryRun, "dry-run", false, "仅打印将要执行的语句[可选]")
+
+	Cmd.PersistentFlags().StringVarP(&VarStringDir, "dir", "d", "migrations", "迁移文件目录")
+	Cmd.PersistentFlags().StringVar(&VarStringURL, "url", "", `数据库的数据源，例如 "root:password@tcp(127.0.0.1:3306)/database"`)
+	Cmd.PersistentFlags().StringVar(&VarStringTable, "table", "schema_migrations", "迁移历史表")
+	Cmd.PersistentFlags().BoolVar(&VarBoolPostgres, "postgres", false, "数据源为 PostgreSQL[可选]")
+	Cmd.PersistentFlags().DurationVar(&VarDurationLockTimeout, "lock-timeout", time.Minute, "获取迁移锁的超时时间")
+
+	Cmd.AddCommand(createCmd)
+	Cmd.AddCommand(upCmd)
+	Cmd.AddCommand(downCmd)
+	Cmd.AddCommand(statusCmd)
+}
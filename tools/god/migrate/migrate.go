@@ -0,0 +1,207 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gotid/god/lib/store/migrate"
+	"github.com/gotid/god/lib/store/sqlx"
+	"github.com/gotid/god/tools/god/util/console"
+	"github.com/gotid/god/tools/god/util/pathx"
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	versionLayout  = "20060102150405"
+	dateTimeLayout = "2006-01-02 15:04:05"
+)
+
+var (
+	// VarStringDir 迁移文件目录
+	VarStringDir string
+	// VarStringURL 数据源链接地址
+	VarStringURL string
+	// VarStringTable 迁移历史表
+	VarStringTable string
+	// VarBoolDryRun 是否仅打印将要执行的语句
+	VarBoolDryRun bool
+	// VarInt64Version 执行到的迁移版本
+	VarInt64Version int64
+	// VarIntSteps 回滚的迁移数量
+	VarIntSteps int
+	// VarBoolPostgres 数据源是否为 PostgreSQL
+	VarBoolPostgres bool
+	// VarDurationLockTimeout 获取迁移锁的超时时间
+	VarDurationLockTimeout time.Duration
+)
+
+var (
+	errNoURL       = errors.New("缺少数据源 --url")
+	nameRegexp     = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	errInvalidName = errors.New("迁移名称仅能包含字母、数字及下划线")
+)
+
+// Create 创建迁移文件。
+func Create(_ *cobra.Command, args []string) error {
+	name := args[0]
+	if !nameRegexp.MatchString(name) {
+		return errInvalidName
+	}
+
+	if err := pathx.MkdirIfNotExist(VarStringDir); err != nil {
+		return err
+	}
+
+	version, err := nextVersion()
+	if err != nil {
+		return err
+	}
+
+	up, down := migrate.Filenames(version, name)
+	// 写入注释占位，补写语句前加载迁移将返回 migrate.ErrEmptyMigration，以免被记录为已执行。
+	files := map[string]string{
+		up:   "-- 在此编写升级语句\n",
+		down: "-- 在此编写回滚语句\n",
+	}
+	for _, filename := range []string{up, down} {
+		file := filepath.Join(VarStringDir, filename)
+		if err = os.WriteFile(file, []byte(files[filename]), 0o644); err != nil {
+			return err
+		}
+		console.Info("已创建 %s", file)
+	}
+
+	return nil
+}
+
+// Up 执行待执行的迁移。
+func Up(_ *cobra.Command, _ []string) error {
+	m, err := newMigrator()
+	if err != nil {
+		return err
+	}
+
+	applied, err := m.UpTo(context.Background(), VarInt64Version)
+	for _, migration := range applied {
+		console.Success("已执行 %s", migration)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		console.Info("没有待执行的迁移")
+	}
+
+	return nil
+}
+
+// Down 回滚最近执行的迁移。
+func Down(_ *cobra.Command, _ []string) error {
+	m, err := newMigrator()
+	if err != nil {
+		return err
+	}
+
+	rolledBack, err := m.Down(context.Background(), VarIntSteps)
+	for _, migration := range rolledBack {
+		console.Success("已回滚 %s", migration)
+	}
+	if err != nil {
+		return err
+	}
+	if len(rolledBack) == 0 {
+		console.Info("没有可回滚的迁移")
+	}
+
+	return nil
+}
+
+// Status 打印迁移状态。
+func Status(_ *cobra.Command, _ []string) error {
+	m, err := newMigrator()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		state := "待执行"
+		appliedAt := "-"
+		if status.Applied {
+			state = "已执行"
+			appliedAt = status.AppliedAt.Format(dateTimeLayout)
+		}
+		switch {
+		case status.Missing:
+			state += "（文件缺失）"
+		case status.Modified:
+			state += "（已修改）"
+		}
+		fmt.Printf("%-16d %-40s %-20s %s\n", status.Version, status.Name, appliedAt, state)
+	}
+
+	return nil
+}
+
+func newMigrator() (*migrate.Migrator, error) {
+	if len(VarStringURL) == 0 {
+		return nil, errNoURL
+	}
+
+	migrations, err := migrate.LoadDir(VarStringDir)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []migrate.Option{
+		migrate.WithTable(VarStringTable),
+		migrate.WithLockTimeout(VarDurationLockTimeout),
+	}
+	if VarBoolDryRun {
+		opts = append(opts, migrate.WithDryRun())
+	}
+
+	if VarBoolPostgres {
+		opts = append(opts, migrate.WithPostgres())
+		return migrate.New(sqlx.NewPostgres(VarStringURL), migrations, opts...), nil
+	}
+
+	return migrate.New(sqlx.NewMySQL(VarStringURL), migrations, opts...), nil
+}
+
+// nextVersion 以当前时间生成版本，且大于目录中已有的版本。
+// 目录中可能有尚未编写的迁移文件，故仅解析文件名而不加载迁移。
+func nextVersion() (int64, error) {
+	entries, err := os.ReadDir(VarStringDir)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := strconv.ParseInt(time.Now().Format(versionLayout), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseInt(prefix, 10, 64); err == nil && v >= version {
+			version = v + 1
+		}
+	}
+
+	return version, nil
+}
@@ -0,0 +1,81 @@
+package gen
+
+import (
+	"fmt"
+	"github.com/gotid/god/tools/god/model/sql/template"
+	"github.com/gotid/god/tools/god/util"
+	"github.com/gotid/god/tools/god/util/pathx"
+	"github.com/gotid/god/tools/god/util/stringx"
+	"strings"
+)
+
+// 为主键及每个唯一索引生成游标分页方法，如 FindPageById、FindPageByName。
+func genFindPageByField(table Table, withCache, postgreSql bool) (string, string, error) {
+	text, err := pathx.LoadTemplate(category, findPageByFieldTemplateFile, template.FindPageByField)
+	if err != nil {
+		return "", "", err
+	}
+
+	methodText, err := pathx.LoadTemplate(category, findPageByFieldMethodTemplateFile, template.FindPageByFieldMethod)
+	if err != nil {
+		return "", "", err
+	}
+
+	keys := append([]Key{table.PrimaryCacheKey}, table.UniqueCacheKey...)
+	camel := table.Name.ToCamel()
+	t := util.With("findPageByField").Parse(text)
+	tm := util.With("findPageByFieldMethod").Parse(methodText)
+	var list, listMethod []string
+	for _, key := range keys {
+		upperField := key.FieldNameJoin.ToCamel().With("").Source()
+		output, err := t.Execute(map[string]any{
+			"upperStartCamelObject": camel,
+			"lowerStartCamelObject": stringx.From(camel).UnTitle(),
+			"upperField":            upperField,
+			"sorts":                 genPageSorts(table, key, postgreSql),
+			"withCache":             withCache,
+			"postgreSql":            postgreSql,
+			"data":                  table,
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		method, err := tm.Execute(map[string]any{
+			"upperStartCamelObject": camel,
+			"upperField":            upperField,
+			"data":                  table,
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		list = append(list, output.String())
+		listMethod = append(listMethod, method.String())
+	}
+
+	return strings.Join(list, pathx.NL), strings.Join(listMethod, pathx.NL), nil
+}
+
+// 唯一索引的列可能为 NULL，故以主键兜底保证排序唯一。
+func genPageSorts(table Table, key Key, postgreSql bool) string {
+	columns := append([]string(nil), key.FieldNameJoin...)
+	primary := table.PrimaryKey.Name.Source()
+	var containsPrimary bool
+	for _, column := range columns {
+		if column == primary {
+			containsPrimary = true
+			break
+		}
+	}
+	if !containsPrimary {
+		columns = append(columns, primary)
+	}
+
+	sorts := make([]string, len(columns))
+	for i, column := range columns {
+		sorts[i] = fmt.Sprintf("sqlx.Asc(%q)", wrapWithRawString(column, postgreSql))
+	}
+
+	return strings.Join(sorts, ", ")
+}
@@ -216,7 +216,12 @@ func (g *DefaultGenerator) genModel(t parser.Table, withCache bool) (string, err
 		return "", err
 	}
 
-	findCode = append(findCode, findOneCode, ret.findOneMethod)
+	findPageCode, findPageCodeMethod, err := genFindPageByField(table, withCache, g.isPostgreSQL)
+	if err != nil {
+		return "", err
+	}
+
+	findCode = append(findCode, findOneCode, ret.findOneMethod, findPageCode)
 
 	updateCode, updateCodeMethod, err := genUpdate(table, withCache, g.isPostgreSQL)
 	if err != nil {
@@ -230,7 +235,7 @@ func (g *DefaultGenerator) genModel(t parser.Table, withCache bool) (string, err
 
 	var list []string
 	list = append(list, insertCodeMethod, findOneCodeMethod, ret.findOneInterfaceMethod,
-		updateCodeMethod, deleteCodeMethod)
+		findPageCodeMethod, updateCodeMethod, deleteCodeMethod)
 	typesCode, err := genTypes(table, strings.Join(modelutil.TrimStringSlice(list), pathx.NL), withCache)
 	if err != nil {
 		return "", err
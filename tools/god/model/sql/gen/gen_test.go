@@ -195,3 +195,38 @@ func Test_genPublicModel(t *testing.T) {
 	assert.True(t, strings.Contains(code, "customTestUserModel struct {\n\t\t*defaultTestUserModel\n\t}\n"))
 	assert.True(t, strings.Contains(code, "func NewTestUserModel(conn sqlx.Conn) TestUserModel {"))
 }
+
+func Test_genFindPageByField(t *testing.T) {
+	dir := pathx.MustTempDir()
+	defer os.RemoveAll(dir)
+
+	sqlFile := filepath.Join(dir, "user.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte(source), 0o777))
+	tables, err := parser.Parse(sqlFile, "", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tables))
+
+	primaryKey, uniqueKey := genCacheKeys(*tables[0])
+	table := Table{
+		Table:                  *tables[0],
+		PrimaryCacheKey:        primaryKey,
+		UniqueCacheKey:         uniqueKey,
+		ContainsUniqueCacheKey: len(uniqueKey) > 0,
+	}
+
+	code, method, err := genFindPageByField(table, true, false)
+	assert.NoError(t, err)
+	assert.Contains(t, code, "FindPageById(ctx context.Context, cursor string, limit int) ([]*TestUser, string, error)")
+	assert.Contains(t, code, "sqlx.SetPageSecret")
+	assert.Contains(t, code, "sqlx.NewPaginator([]sqlx.SortField{ sqlx.Asc(\"`id`\") })")
+	assert.Contains(t, code, "sqlx.NewPaginator([]sqlx.SortField{ sqlx.Asc(\"`mobile`\"), sqlx.Asc(\"`id`\") })")
+	assert.Contains(t, code, "sqlx.Asc(\"`class`\"), sqlx.Asc(\"`name`\"), sqlx.Asc(\"`id`\")")
+	assert.Contains(t, code, "m.QueryRowsNoCacheCtx(ctx, &resp, query, args...)")
+	assert.Contains(t, method, "FindPageByMobile(ctx context.Context, cursor string, limit int) ([]*TestUser, string, error)")
+	assert.Contains(t, method, "FindPageByClassName(")
+
+	code, _, err = genFindPageByField(table, false, true)
+	assert.NoError(t, err)
+	assert.Contains(t, code, "sqlx.Asc(\"id\") }, sqlx.WithPagePostgres())")
+	assert.Contains(t, code, "m.conn.QueryRowsCtx(ctx, &resp, query, args...)")
+}
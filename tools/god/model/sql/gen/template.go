@@ -15,6 +15,8 @@ const (
 	findOneByFieldTemplateFile            = "find-one-by-field.tpl"
 	findOneByFieldMethodTemplateFile      = "interface-find-one-by-field.tpl"
 	findOneByFieldExtraMethodTemplateFile = "find-one-by-field-extra-method.tpl"
+	findPageByFieldTemplateFile           = "find-page-by-field.tpl"
+	findPageByFieldMethodTemplateFile     = "interface-find-page-by-field.tpl"
 	updateTemplateFile                    = "update.tpl"
 	updateMethodTemplateFile              = "interface-update.tpl"
 	deleteTemplateFile                    = "delete.tpl"
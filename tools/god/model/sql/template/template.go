@@ -107,6 +107,16 @@ var FindOneMethod string
 //go:embed tpl/interface-find-one-by-field.tpl
 var FindOneByFieldMethod string
 
+// FindPageByField 根据索引字段游标分页查询。
+//
+//go:embed tpl/find-page-by-field.tpl
+var FindPageByField string
+
+// FindPageByFieldMethod 定义根据索引字段游标分页查询的方法。
+//
+//go:embed tpl/interface-find-page-by-field.tpl
+var FindPageByFieldMethod string
+
 // Field 定义一个字段类型声明的模板。
 //
 //go:embed tpl/field.tpl
@@ -0,0 +1,22 @@
+// FindPageBy{{.upperField}} 按 {{.upperField}} 游标分页查询，cursor 为空时查询首页，返回的下一页游标为空表示没有更多数据。
+// 须先调用 sqlx.SetPageSecret 设置游标签名密钥，否则返回 sqlx.ErrNoPageSecret。
+func (m *default{{.upperStartCamelObject}}Model) FindPageBy{{.upperField}}(ctx context.Context, cursor string, limit int) ([]*{{.upperStartCamelObject}}, string, error) {
+	paginator := sqlx.NewPaginator([]sqlx.SortField{ {{.sorts}} }{{if .postgreSql}}, sqlx.WithPagePostgres(){{end}})
+	query, args, err := paginator.Build(fmt.Sprintf("select %s from %s", {{.lowerStartCamelObject}}Rows, m.table), cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var resp []*{{.upperStartCamelObject}}
+	{{if .withCache}}err = m.QueryRowsNoCacheCtx(ctx, &resp, query, args...){{else}}err = m.conn.QueryRowsCtx(ctx, &resp, query, args...){{end}}
+	if err != nil {
+		return nil, "", err
+	}
+
+	next, err := paginator.Next(&resp, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp, next, nil
+}
@@ -0,0 +1 @@
+FindPageBy{{.upperField}}(ctx context.Context, cursor string, limit int) ([]*{{.upperStartCamelObject}}, string, error)
\ No newline at end of file